@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/NamhaeSusan/my-go-kit/grpcclient/interceptor"
+	"github.com/NamhaeSusan/my-go-kit/propagation"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/backoff"
 	"google.golang.org/grpc/credentials"
@@ -47,6 +48,9 @@ type Config struct {
 	IdleTimeout               time.Duration
 	UnaryClientInterceptors   []grpc.UnaryClientInterceptor
 	StreamClientInterceptors  []grpc.StreamClientInterceptor
+	// Propagator writes the trace of the context to each call. nil uses
+	// propagation.Default at call time.
+	Propagator propagation.Propagator
 }
 
 type Client struct {
@@ -93,13 +97,14 @@ func checkClientConfig(cfg *Config) {
 func NewClient(addr string, cfg Config) (*Client, error) {
 	checkClientConfig(&cfg)
 
+	traceConfig := interceptor.TraceConfig{Propagator: cfg.Propagator}
 	unaryInterceptors := append([]grpc.UnaryClientInterceptor{
-		interceptor.UnaryClientTraceInterceptor(),
+		interceptor.UnaryClientTraceInterceptorWithConfig(traceConfig),
 		interceptor.UnaryClientLoggingInterceptor(),
 	}, cfg.UnaryClientInterceptors...)
 
 	streamInterceptors := append([]grpc.StreamClientInterceptor{
-		interceptor.StreamClientTraceInterceptor(),
+		interceptor.StreamClientTraceInterceptorWithConfig(traceConfig),
 		interceptor.StreamClientLoggingInterceptor(),
 	}, cfg.StreamClientInterceptors...)
 
@@ -12,6 +12,10 @@ import (
 )
 
 const (
+	// loggerName is the kitlog.Named logger used by the interceptors. Use
+	// kitlog.SetNamedLevel("grpc", ...) to quiet them.
+	loggerName = "grpc"
+
 	logTypeFieldName = "log_type"
 	logTypeGRPC      = "grpc"
 )
@@ -95,7 +99,7 @@ func logGRPCCall(ctx context.Context, fullMethod string, elapsed time.Duration,
 		zap.String(logTypeFieldName, logTypeGRPC),
 	)
 
-	zap.L().Info("grpc request", fields...)
+	kitlog.Named(loggerName).Info("grpc request", fields...)
 }
 
 func splitGRPCMethod(fullMethod string) (string, string) {
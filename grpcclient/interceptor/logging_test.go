@@ -43,6 +43,9 @@ func TestUnaryClientLoggingInterceptor_WritesExpectedFields(t *testing.T) {
 	}
 
 	entry := logs.All()[0]
+	if entry.LoggerName != loggerName {
+		t.Fatalf("unexpected logger name: %q", entry.LoggerName)
+	}
 	fields := entry.ContextMap()
 	if got := fields["method"]; got != "Ping" {
 		t.Fatalf("unexpected method: %#v", got)
@@ -195,3 +198,30 @@ type fakeServerStreamForLogging struct {
 func (f *fakeServerStreamForLogging) Context() context.Context {
 	return f.ctx
 }
+
+func TestUnaryServerLoggingInterceptor_IncludesContextFields(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	prev := zap.L()
+	zap.ReplaceGlobals(zap.New(core))
+	t.Cleanup(func() { zap.ReplaceGlobals(prev) })
+
+	interceptor := UnaryServerLoggingInterceptor()
+	ctx := kitlog.WithFields(context.Background(), zap.String("tenant", "acme"))
+
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{
+		FullMethod: "/sample.Server/Handle",
+	}, func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	})
+	if err != nil {
+		t.Fatalf("interceptor returned error: %v", err)
+	}
+
+	fields := logs.All()[0].ContextMap()
+	if got := fields["tenant"]; got != "acme" {
+		t.Fatalf("unexpected tenant: %#v", got)
+	}
+	if got := fields["grpc_code"]; got != "OK" {
+		t.Fatalf("unexpected grpc_code: %#v", got)
+	}
+}
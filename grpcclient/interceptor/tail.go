@@ -0,0 +1,45 @@
+package interceptor
+
+import (
+	"context"
+
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerTailBufferInterceptor holds the debug and info logs written with
+// the request context and writes them only if the handler returns a non-OK
+// status. Chain it after UnaryServerLoggingInterceptor so that the request
+// log itself is not held.
+func UnaryServerTailBufferInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		ctx, buf := kitlog.WithTailBuffer(ctx, 0)
+		defer finishTailBuffer(buf, &err)
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerTailBufferInterceptor is the stream counterpart of
+// UnaryServerTailBufferInterceptor.
+func StreamServerTailBufferInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		ctx, buf := kitlog.WithTailBuffer(ss.Context(), 0)
+		defer finishTailBuffer(buf, &err)
+		return handler(srv, &wrappedServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// finishTailBuffer flushes buf if the call failed or panicked.
+func finishTailBuffer(buf *kitlog.TailBuffer, err *error) {
+	if r := recover(); r != nil {
+		buf.Flush()
+		panic(r)
+	}
+	if status.Code(*err) != codes.OK {
+		buf.Flush()
+		return
+	}
+	buf.Discard()
+}
@@ -0,0 +1,60 @@
+package interceptor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestServerTailBufferInterceptors_FlushOnlyFailedCalls(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l, err := kitlog.New(kitlog.Config{Stdout: new(false), FilePath: path})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	restore := kitlog.ReplaceGlobals(l)
+	t.Cleanup(func() {
+		restore()
+		_ = l.Close()
+	})
+
+	unary := UnaryServerTailBufferInterceptor()
+	for _, code := range []codes.Code{codes.OK, codes.Internal} {
+		_, _ = unary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/svc/Unary"}, func(ctx context.Context, req any) (any, error) {
+			kitlog.Debugf(ctx, "unary %s", code)
+			return nil, status.Error(code, "result")
+		})
+	}
+
+	stream := StreamServerTailBufferInterceptor()
+	for _, code := range []codes.Code{codes.OK, codes.NotFound} {
+		_ = stream(nil, &fakeServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/svc/Stream"}, func(srv any, ss grpc.ServerStream) error {
+			kitlog.Infof(ss.Context(), "stream %s", code)
+			return status.Error(code, "result")
+		})
+	}
+	_ = l.Sync()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	logged := string(data)
+	for _, want := range []string{"unary Internal", "stream NotFound"} {
+		if !strings.Contains(logged, want) {
+			t.Fatalf("%q should be flushed: %s", want, logged)
+		}
+	}
+	for _, unwanted := range []string{"unary OK", "stream OK"} {
+		if strings.Contains(logged, unwanted) {
+			t.Fatalf("%q should be dropped: %s", unwanted, logged)
+		}
+	}
+}
@@ -2,45 +2,91 @@ package interceptor
 
 import (
 	"context"
+	"errors"
+	"io"
 	"strings"
+	"sync"
 
 	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+	"github.com/NamhaeSusan/my-go-kit/propagation"
+	"github.com/NamhaeSusan/my-go-kit/trace"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
-var (
-	traceMetadataKey = strings.ToLower(kitlog.TraceHeader)
-	spanMetadataKey  = strings.ToLower(kitlog.SpanHeader)
-	pSpanMetadataKey = strings.ToLower(kitlog.PSpanHeader)
-)
+var debugMetadataKey = strings.ToLower(kitlog.DebugHeader)
+
+// TraceConfig configures the trace interceptors.
+type TraceConfig struct {
+	// DebugTrust turns on debug logging for a single call when the
+	// kitlog.DebugHeader metadata comes from a caller it trusts. nil ignores
+	// the metadata. Only the server interceptors use it.
+	DebugTrust *kitlog.DebugTrust
+	// Propagator reads the trace of incoming calls and writes it to outgoing
+	// ones. nil uses propagation.Default at call time.
+	Propagator propagation.Propagator
+}
 
 func UnaryClientTraceInterceptor() grpc.UnaryClientInterceptor {
+	return UnaryClientTraceInterceptorWithConfig(TraceConfig{})
+}
+
+func UnaryClientTraceInterceptorWithConfig(cfg TraceConfig) grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		ctx = injectOutgoingTraceMetadata(ctx)
-		return invoker(ctx, method, req, reply, cc, opts...)
+		ctx, span := startRPCSpan(ctx, method, trace.SpanKindClient)
+		ctx = injectOutgoingTraceMetadata(ctx, cfg)
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		endRPCSpan(span, err)
+		return err
 	}
 }
 
 func StreamClientTraceInterceptor() grpc.StreamClientInterceptor {
+	return StreamClientTraceInterceptorWithConfig(TraceConfig{})
+}
+
+func StreamClientTraceInterceptorWithConfig(cfg TraceConfig) grpc.StreamClientInterceptor {
 	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
-		ctx = injectOutgoingTraceMetadata(ctx)
-		return streamer(ctx, desc, cc, method, opts...)
+		ctx, span := startRPCSpan(ctx, method, trace.SpanKindClient)
+		ctx = injectOutgoingTraceMetadata(ctx, cfg)
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			endRPCSpan(span, err)
+			return nil, err
+		}
+		return newTracedClientStream(ctx, stream, span, desc.ServerStreams), nil
 	}
 }
 
-func injectOutgoingTraceMetadata(ctx context.Context) context.Context {
-	if ctx == nil {
-		ctx = context.Background()
-	}
+// metadataCarrier adapts gRPC metadata to propagation.Carrier.
+type metadataCarrier metadata.MD
 
-	traceID := kitlog.GetTraceID(ctx)
-	if traceID == kitlog.Unknown {
-		traceID = kitlog.NewTraceID()
+func (m metadataCarrier) Get(key string) string {
+	return firstMetadataValue(metadata.MD(m).Get(key))
+}
+
+func (m metadataCarrier) Set(key, value string) {
+	metadata.MD(m).Set(key, value)
+}
+
+func (m metadataCarrier) Values(key string) []string {
+	return metadata.MD(m).Get(key)
+}
+
+func propagatorFor(cfg TraceConfig) propagation.Propagator {
+	if cfg.Propagator != nil {
+		return cfg.Propagator
 	}
+	return propagation.Default()
+}
 
-	pSpanID := kitlog.GetSpanID(ctx)
-	spanID := kitlog.NewSpanID()
+func injectOutgoingTraceMetadata(ctx context.Context, cfg TraceConfig) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
 
 	md, ok := metadata.FromOutgoingContext(ctx)
 	if ok {
@@ -49,24 +95,46 @@ func injectOutgoingTraceMetadata(ctx context.Context) context.Context {
 		md = metadata.New(nil)
 	}
 
-	md.Set(traceMetadataKey, traceID)
-	md.Set(spanMetadataKey, spanID)
-	md.Set(pSpanMetadataKey, pSpanID)
+	// 이전 호출에서 복사된 trace metadata가 섞이지 않도록 먼저 지운다.
+	p := propagatorFor(cfg)
+	for _, field := range p.Fields() {
+		md.Delete(field)
+	}
+	p.Inject(ctx, metadataCarrier(md))
+	if debug := kitlog.GetDebugLog(ctx); debug != "" {
+		md.Set(debugMetadataKey, debug)
+	}
 
 	return metadata.NewOutgoingContext(ctx, md)
 }
 
 func UnaryServerTraceInterceptor() grpc.UnaryServerInterceptor {
+	return UnaryServerTraceInterceptorWithConfig(TraceConfig{})
+}
+
+func UnaryServerTraceInterceptorWithConfig(cfg TraceConfig) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
-		ctx = injectIncomingTraceContext(ctx)
-		return handler(ctx, req)
+		ctx, release := injectIncomingTraceContext(ctx, cfg)
+		defer release()
+		ctx, span := continueRPCSpan(ctx, info.FullMethod)
+		resp, err := handler(ctx, req)
+		endRPCSpan(span, err)
+		return resp, err
 	}
 }
 
 func StreamServerTraceInterceptor() grpc.StreamServerInterceptor {
+	return StreamServerTraceInterceptorWithConfig(TraceConfig{})
+}
+
+func StreamServerTraceInterceptorWithConfig(cfg TraceConfig) grpc.StreamServerInterceptor {
 	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-		ctx := injectIncomingTraceContext(ss.Context())
-		return handler(srv, &wrappedServerStream{ServerStream: ss, ctx: ctx})
+		ctx, release := injectIncomingTraceContext(ss.Context(), cfg)
+		defer release()
+		ctx, span := continueRPCSpan(ctx, info.FullMethod)
+		err := handler(srv, &wrappedServerStream{ServerStream: ss, ctx: ctx})
+		endRPCSpan(span, err)
+		return err
 	}
 }
 
@@ -79,32 +147,115 @@ func (w *wrappedServerStream) Context() context.Context {
 	return w.ctx
 }
 
-func injectIncomingTraceContext(ctx context.Context) context.Context {
+// injectIncomingTraceContext stores the trace of the incoming metadata in
+// ctx. release ends the debug flag, if any, when the call returns.
+func injectIncomingTraceContext(ctx context.Context, cfg TraceConfig) (_ context.Context, release func()) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
 
 	md, _ := metadata.FromIncomingContext(ctx)
+	ctx, _ = propagation.ExtractContext(ctx, propagatorFor(cfg), metadataCarrier(md))
+	traceID := kitlog.GetTraceID(ctx)
+
+	if debug := firstMetadataValue(md.Get(debugMetadataKey)); cfg.DebugTrust.Allowed(debug, traceID, peerAddr(ctx)) {
+		return kitlog.WithDebugLog(ctx, debug)
+	}
+	return ctx, func() {}
+}
+
+func startRPCSpan(ctx context.Context, fullMethod string, kind trace.SpanKind) (context.Context, *trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return trace.Start(ctx, rpcSpanName(fullMethod), trace.WithKind(kind), trace.WithAttributes(rpcAttributes(fullMethod)...))
+}
+
+// continueRPCSpan starts the server span of a call whose IDs
+// injectIncomingTraceContext stored in ctx.
+func continueRPCSpan(ctx context.Context, fullMethod string) (context.Context, *trace.Span) {
+	return trace.Continue(ctx, rpcSpanName(fullMethod), trace.WithKind(trace.SpanKindServer), trace.WithAttributes(rpcAttributes(fullMethod)...))
+}
+
+func rpcSpanName(fullMethod string) string {
+	service, method := splitGRPCMethod(fullMethod)
+	return service + "/" + method
+}
 
-	traceID := firstMetadataValue(md.Get(traceMetadataKey))
-	if traceID == "" {
-		traceID = kitlog.NewTraceID()
+func rpcAttributes(fullMethod string) []zap.Field {
+	service, method := splitGRPCMethod(fullMethod)
+	return []zap.Field{
+		zap.String("rpc.system", "grpc"),
+		zap.String("rpc.service", service),
+		zap.String("rpc.method", method),
 	}
+}
 
-	spanID := firstMetadataValue(md.Get(spanMetadataKey))
-	if spanID == "" {
-		spanID = kitlog.NewSpanID()
+// endRPCSpan ends the span of a call with the gRPC status of err. Any
+// non-OK status fails the span.
+func endRPCSpan(span *trace.Span, err error) {
+	defer span.End()
+	st := status.Convert(err)
+	span.SetAttributes(zap.Int("rpc.grpc.status_code", int(st.Code())))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(trace.StatusError, st.Message())
 	}
+}
+
+// tracedClientStream ends the client span when the stream finishes: at the
+// first error or io.EOF of RecvMsg, after the single response of a stream
+// without server streaming, or when the context of the call ends before the
+// caller drained the stream.
+type tracedClientStream struct {
+	grpc.ClientStream
+	span          *trace.Span
+	serverStreams bool
 
-	pSpanID := firstMetadataValue(md.Get(pSpanMetadataKey))
-	if pSpanID == "" {
-		pSpanID = kitlog.Unknown
+	once sync.Once
+	done chan struct{}
+}
+
+func newTracedClientStream(ctx context.Context, stream grpc.ClientStream, span *trace.Span, serverStreams bool) *tracedClientStream {
+	s := &tracedClientStream{ClientStream: stream, span: span, serverStreams: serverStreams, done: make(chan struct{})}
+	// 호출자가 stream을 끝까지 읽지 않고 취소해도 span이 끝나도록 한다.
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.finish(status.FromContextError(ctx.Err()).Err())
+		case <-s.done:
+		}
+	}()
+	return s
+}
+
+func (s *tracedClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case errors.Is(err, io.EOF):
+		s.finish(nil)
+	case err != nil:
+		s.finish(err)
+	case !s.serverStreams:
+		s.finish(nil)
 	}
+	return err
+}
 
-	ctx = kitlog.WithTraceID(ctx, traceID)
-	ctx = kitlog.WithSpanID(ctx, spanID)
-	ctx = kitlog.WithPSpanID(ctx, pSpanID)
-	return ctx
+// finish ends the span once, with the status of err.
+func (s *tracedClientStream) finish(err error) {
+	s.once.Do(func() {
+		close(s.done)
+		endRPCSpan(s.span, err)
+	})
+}
+
+func peerAddr(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	return p.Addr.String()
 }
 
 func firstMetadataValue(values []string) string {
@@ -3,11 +3,26 @@ package interceptor
 import (
 	"context"
 	"encoding/hex"
+	"io"
+	"net"
+	"strings"
 	"testing"
+	"time"
 
 	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+	"github.com/NamhaeSusan/my-go-kit/propagation"
+	"github.com/NamhaeSusan/my-go-kit/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	traceMetadataKey = strings.ToLower(kitlog.TraceHeader)
+	spanMetadataKey  = strings.ToLower(kitlog.SpanHeader)
+	pSpanMetadataKey = strings.ToLower(kitlog.PSpanHeader)
 )
 
 func TestUnaryClientTraceInterceptor_InjectsOutgoingMetadata(t *testing.T) {
@@ -42,7 +57,12 @@ func TestUnaryClientTraceInterceptor_InjectsOutgoingMetadata(t *testing.T) {
 	if got := firstMetadataValue(md.Get(traceMetadataKey)); got != "trace-ctx-1" {
 		t.Fatalf("unexpected trace id: %q", got)
 	}
-	if got := firstMetadataValue(md.Get(pSpanMetadataKey)); got != "span-ctx-1" {
+	// 현재 span의 자식인 client span이 다음 서비스의 pSpanID로 전파된다.
+	span := trace.SpanFromContext(gotCtx)
+	if span.ParentSpanID() != "span-ctx-1" || span.Kind() != trace.SpanKindClient || !span.Ended() {
+		t.Fatalf("unexpected client span: parent=%q kind=%s ended=%v", span.ParentSpanID(), span.Kind(), span.Ended())
+	}
+	if got := firstMetadataValue(md.Get(pSpanMetadataKey)); got != span.SpanID() {
 		t.Fatalf("unexpected pspan id: %q", got)
 	}
 	if got := firstMetadataValue(md.Get(spanMetadataKey)); got == "" || got == "span-ctx-1" {
@@ -81,8 +101,10 @@ func TestUnaryClientTraceInterceptor_GeneratesWhenMissing(t *testing.T) {
 	if !isHexLen(spanID, 16) {
 		t.Fatalf("span id should be generated hex16, got: %q", spanID)
 	}
-	if pSpanID != kitlog.Unknown {
-		t.Fatalf("pspan should fallback to unknown, got: %q", pSpanID)
+	// 새 trace의 root는 client span이다.
+	span := trace.SpanFromContext(gotCtx)
+	if span.ParentSpanID() != "" || span.TraceID() != traceID || pSpanID != span.SpanID() {
+		t.Fatalf("the client span should be the root, got pspan: %q", pSpanID)
 	}
 }
 
@@ -114,7 +136,11 @@ func TestStreamClientTraceInterceptor_InjectsOutgoingMetadata(t *testing.T) {
 	if got := firstMetadataValue(md.Get(traceMetadataKey)); got != "trace-ctx-2" {
 		t.Fatalf("unexpected trace id: %q", got)
 	}
-	if got := firstMetadataValue(md.Get(pSpanMetadataKey)); got != "span-ctx-2" {
+	span := trace.SpanFromContext(gotCtx)
+	if span.ParentSpanID() != "span-ctx-2" {
+		t.Fatalf("unexpected parent of the client span: %q", span.ParentSpanID())
+	}
+	if got := firstMetadataValue(md.Get(pSpanMetadataKey)); got != span.SpanID() {
 		t.Fatalf("unexpected pspan id: %q", got)
 	}
 }
@@ -177,6 +203,251 @@ func TestStreamServerTraceInterceptor_GeneratesFallbackValues(t *testing.T) {
 	}
 }
 
+func TestTraceInterceptors_PropagateTraceParent(t *testing.T) {
+	const traceID, spanID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	incoming := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		traceMetadataKey, "legacy-trace",
+		"traceparent", "00-"+traceID+"-"+spanID+"-00",
+		"tracestate", "vendor=abc",
+	))
+
+	var serverCtx context.Context
+	server := UnaryServerTraceInterceptorWithConfig(TraceConfig{
+		Propagator: propagation.Composite{propagation.W3C{}, propagation.Legacy{}},
+	})
+	_, err := server(incoming, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+		serverCtx = ctx
+		return "ok", nil
+	})
+	if err != nil {
+		t.Fatalf("interceptor returned error: %v", err)
+	}
+	if kitlog.GetTraceID(serverCtx) != traceID || kitlog.GetPSpanID(serverCtx) != spanID {
+		t.Fatalf("traceparent should win: %s/%s", kitlog.GetTraceID(serverCtx), kitlog.GetPSpanID(serverCtx))
+	}
+
+	var clientCtx context.Context
+	client := UnaryClientTraceInterceptor()
+	err = client(serverCtx, "/svc/method", nil, nil, nil, func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		clientCtx = ctx
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("interceptor returned error: %v", err)
+	}
+	md, _ := metadata.FromOutgoingContext(clientCtx)
+	clientSpan := trace.SpanFromContext(clientCtx)
+	if clientSpan.ParentSpanID() != kitlog.GetSpanID(serverCtx) {
+		t.Fatalf("the client span should be a child of the server span: %q", clientSpan.ParentSpanID())
+	}
+	want := "00-" + traceID + "-" + clientSpan.SpanID() + "-00"
+	if got := firstMetadataValue(md.Get("traceparent")); got != want {
+		t.Fatalf("unexpected traceparent: %q, want %q", got, want)
+	}
+	if got := firstMetadataValue(md.Get("tracestate")); got != "vendor=abc" {
+		t.Fatalf("unexpected tracestate: %q", got)
+	}
+}
+
+func TestTraceInterceptors_PropagateSamplingDecision(t *testing.T) {
+	trace.SetSampler(trace.ParentBased(trace.AlwaysSample()))
+	t.Cleanup(func() { trace.SetSampler(nil) })
+
+	sampledKey := strings.ToLower(kitlog.SampledHeader)
+	incoming := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		traceMetadataKey, "incoming-trace",
+		spanMetadataKey, "incoming-span",
+		sampledKey, "0",
+	))
+
+	var serverCtx context.Context
+	_, err := UnaryServerTraceInterceptor()(incoming, nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Orders/Get"}, func(ctx context.Context, req any) (any, error) {
+		serverCtx = ctx
+		return "ok", nil
+	})
+	if err != nil {
+		t.Fatalf("interceptor returned error: %v", err)
+	}
+	if trace.SpanFromContext(serverCtx).IsRecording() {
+		t.Fatal("the server span should follow the decision of the caller")
+	}
+
+	var clientCtx context.Context
+	err = UnaryClientTraceInterceptor()(serverCtx, "/svc/method", nil, nil, nil, func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		clientCtx = ctx
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("interceptor returned error: %v", err)
+	}
+	if trace.SpanFromContext(clientCtx).IsRecording() {
+		t.Fatal("the client span should follow its parent")
+	}
+	md, _ := metadata.FromOutgoingContext(clientCtx)
+	if got := firstMetadataValue(md.Get(sampledKey)); got != "0" {
+		t.Fatalf("the decision should be sent downstream: %q", got)
+	}
+}
+
+func TestClientTraceInterceptorWithConfig_ReplacesStaleMetadata(t *testing.T) {
+	const traceID, spanID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	ctx := kitlog.WithTraceID(context.Background(), traceID)
+	ctx = kitlog.WithSpanID(ctx, spanID)
+	ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs(
+		"x-b3-parentspanid", "1111111111111111",
+		traceMetadataKey, "stale-trace",
+	))
+
+	var gotCtx context.Context
+	client := StreamClientTraceInterceptorWithConfig(TraceConfig{Propagator: propagation.B3{}})
+	_, err := client(ctx, &grpc.StreamDesc{}, nil, "/svc/method", func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		gotCtx = ctx
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("interceptor returned error: %v", err)
+	}
+
+	md, _ := metadata.FromOutgoingContext(gotCtx)
+	if got := firstMetadataValue(md.Get("x-b3-traceid")); got != traceID {
+		t.Fatalf("unexpected b3 trace: %q", got)
+	}
+	if got := md.Get("x-b3-parentspanid"); len(got) != 1 || got[0] != trace.SpanFromContext(gotCtx).SpanID() {
+		t.Fatalf("stale parent should be replaced: %v", got)
+	}
+	// 설정된 propagator가 쓰지 않는 metadata는 그대로 둔다.
+	if got := firstMetadataValue(md.Get(traceMetadataKey)); got != "stale-trace" {
+		t.Fatalf("unrelated metadata should be kept: %q", got)
+	}
+}
+
+func TestUnaryServerTraceInterceptor_RecordsServerSpan(t *testing.T) {
+	interceptor := UnaryServerTraceInterceptor()
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		traceMetadataKey, "incoming-trace",
+		spanMetadataKey, "incoming-span",
+		pSpanMetadataKey, "incoming-pspan",
+	))
+
+	var span *trace.Span
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Orders/Get"}, func(ctx context.Context, req any) (any, error) {
+		span = trace.SpanFromContext(ctx)
+		return nil, status.Error(codes.NotFound, "no order")
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if span.SpanID() != "incoming-span" || span.ParentSpanID() != "incoming-pspan" || span.Kind() != trace.SpanKindServer {
+		t.Fatalf("the server span should adopt the incoming IDs: %s/%s/%s", span.SpanID(), span.ParentSpanID(), span.Kind())
+	}
+	if span.Name() != "pkg.Orders/Get" || !span.Ended() {
+		t.Fatalf("unexpected span: %s ended=%v", span.Name(), span.Ended())
+	}
+	if got := span.Status(); got.Code != trace.StatusError || got.Description != "no order" {
+		t.Fatalf("unexpected status: %+v", got)
+	}
+	var code int64 = -1
+	for _, f := range span.Attributes() {
+		if f.Key == "rpc.grpc.status_code" {
+			code = f.Integer
+		}
+	}
+	if code != int64(codes.NotFound) {
+		t.Fatalf("unexpected status code attribute: %d", code)
+	}
+}
+
+func TestStreamClientTraceInterceptor_EndsSpanWithStream(t *testing.T) {
+	interceptor := StreamClientTraceInterceptor()
+	for _, tc := range []struct {
+		name          string
+		serverStreams bool
+		recv          []error
+		wantEnded     []bool
+	}{
+		{name: "unary response", recv: []error{nil}, wantEnded: []bool{true}},
+		{name: "server stream", serverStreams: true, recv: []error{nil, io.EOF}, wantEnded: []bool{false, true}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var span *trace.Span
+			fake := &fakeClientStream{recv: tc.recv}
+			stream, err := interceptor(context.Background(), &grpc.StreamDesc{ServerStreams: tc.serverStreams}, nil, "/svc/stream", func(
+				ctx context.Context,
+				desc *grpc.StreamDesc,
+				cc *grpc.ClientConn,
+				method string,
+				opts ...grpc.CallOption,
+			) (grpc.ClientStream, error) {
+				span = trace.SpanFromContext(ctx)
+				return fake, nil
+			})
+			if err != nil {
+				t.Fatalf("interceptor returned error: %v", err)
+			}
+			if span.Ended() {
+				t.Fatal("the span should run until the stream finishes")
+			}
+			for i, want := range tc.wantEnded {
+				_ = stream.RecvMsg(nil)
+				if span.Ended() != want {
+					t.Fatalf("unexpected end after message %d: %v", i, span.Ended())
+				}
+			}
+			if span.Status().Code != trace.StatusUnset {
+				t.Fatalf("unexpected status: %+v", span.Status())
+			}
+		})
+	}
+}
+
+func TestStreamClientTraceInterceptor_EndsSpanWhenCallerCancels(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var span *trace.Span
+	_, err := StreamClientTraceInterceptor()(ctx, &grpc.StreamDesc{ServerStreams: true}, nil, "/svc/stream", func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		span = trace.SpanFromContext(ctx)
+		return &fakeClientStream{}, nil
+	})
+	if err != nil {
+		t.Fatalf("interceptor returned error: %v", err)
+	}
+
+	// stream을 읽지 않고 버린다.
+	cancel()
+	deadline := time.Now().Add(2 * time.Second)
+	for !span.Ended() {
+		if time.Now().After(deadline) {
+			t.Fatal("the span should end when the call is canceled")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if span.Status().Code != trace.StatusError {
+		t.Fatalf("a canceled stream should fail the span: %+v", span.Status())
+	}
+	for _, f := range span.Attributes() {
+		if f.Key == "rpc.grpc.status_code" && f.Integer != int64(codes.Canceled) {
+			t.Fatalf("unexpected status code attribute: %d", f.Integer)
+		}
+	}
+}
+
+type fakeClientStream struct {
+	grpc.ClientStream
+	recv []error
+}
+
+func (f *fakeClientStream) RecvMsg(any) error {
+	err := f.recv[0]
+	f.recv = f.recv[1:]
+	return err
+}
+
 func TestFirstMetadataValue_TrimsAndSkipsEmpty(t *testing.T) {
 	got := firstMetadataValue([]string{"", "  ", " value ", "ignored"})
 	if got != "value" {
@@ -204,3 +475,54 @@ func isHexLen(s string, l int) bool {
 	_, err := hex.DecodeString(s)
 	return err == nil
 }
+
+func TestTraceInterceptors_PropagateTrustedDebugFlag(t *testing.T) {
+	trust, err := kitlog.NewDebugTrust(kitlog.DebugTrustConfig{TrustedCIDRs: []string{"10.0.0.0/8"}})
+	if err != nil {
+		t.Fatalf("NewDebugTrust returned error: %v", err)
+	}
+	server := UnaryServerTraceInterceptorWithConfig(TraceConfig{DebugTrust: trust})
+
+	incoming := func(addr string) context.Context {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(debugMetadataKey, "1"))
+		return peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(addr), Port: 5000}})
+	}
+
+	var serverCtx context.Context
+	handler := func(ctx context.Context, req any) (any, error) {
+		serverCtx = ctx
+		return nil, nil
+	}
+
+	_, _ = server(incoming("192.168.0.1"), nil, &grpc.UnaryServerInfo{}, handler)
+	if kitlog.IsDebugLog(serverCtx) {
+		t.Fatal("debug flag from an untrusted peer should be ignored")
+	}
+	_, _ = UnaryServerTraceInterceptor()(incoming("10.0.0.1"), nil, &grpc.UnaryServerInfo{}, handler)
+	if kitlog.IsDebugLog(serverCtx) {
+		t.Fatal("debug flag should be ignored without DebugTrust")
+	}
+	_, _ = server(incoming("10.0.0.1"), nil, &grpc.UnaryServerInfo{}, handler)
+	if !kitlog.IsDebugLog(serverCtx) {
+		t.Fatal("debug flag from a trusted peer should be accepted")
+	}
+
+	var outgoing context.Context
+	err = UnaryClientTraceInterceptor()(serverCtx, "/svc/method", nil, nil, nil, func(
+		ctx context.Context,
+		method string,
+		req, reply any,
+		cc *grpc.ClientConn,
+		opts ...grpc.CallOption,
+	) error {
+		outgoing = ctx
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("interceptor returned error: %v", err)
+	}
+	md, _ := metadata.FromOutgoingContext(outgoing)
+	if got := firstMetadataValue(md.Get(debugMetadataKey)); got != "1" {
+		t.Fatalf("debug flag should be propagated, got: %q", got)
+	}
+}
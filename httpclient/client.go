@@ -9,13 +9,23 @@ import (
 	"time"
 
 	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+	"github.com/NamhaeSusan/my-go-kit/propagation"
+	"github.com/NamhaeSusan/my-go-kit/trace"
+	"go.uber.org/zap"
 )
 
-
+// loggerName is the kitlog.Named logger used for retry logs.
+const loggerName = "httpclient"
 
 type Config struct {
 	HTTPClient *http.Client
 	Retry      RetryConfig
+	// Propagator writes the trace of the context to each request. nil uses
+	// propagation.Default at request time.
+	Propagator propagation.Propagator
+	// DisableTracing turns off client spans and trace headers, for a client
+	// that ships telemetry and must not trace itself.
+	DisableTracing bool
 }
 
 type RetryConfig struct {
@@ -33,6 +43,8 @@ type Client struct {
 	maxDelay          time.Duration
 	retryableStatuses map[int]struct{}
 	retryableMethods  map[string]struct{}
+	propagator        propagation.Propagator
+	disableTracing    bool
 }
 
 func New(cfg Config) *Client {
@@ -85,6 +97,8 @@ func New(cfg Config) *Client {
 		maxDelay:          maxDelay,
 		retryableStatuses: toStatusSet(statuses),
 		retryableMethods:  toMethodSet(methods),
+		propagator:        cfg.Propagator,
+		disableTracing:    cfg.DisableTracing,
 	}
 }
 
@@ -100,23 +114,39 @@ func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, err
 	}
 
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		clonedReq, err := cloneRequest(ctx, req)
+		// 시도마다 client span을 만들고 요청 context로 넘겨 transport에서도 보이게 한다.
+		spanCtx := ctx
+		var span *trace.Span
+		if !c.disableTracing {
+			spanCtx, span = trace.Start(ctx, req.Method,
+				trace.WithKind(trace.SpanKindClient),
+				trace.WithAttributes(httpClientAttributes(req, attempt)...),
+			)
+		}
+		clonedReq, err := cloneRequest(spanCtx, req)
 		if err != nil {
+			endClientSpan(span, nil, err)
 			return nil, err
 		}
 
-		c.setTraceHeaderFromContext(ctx, clonedReq)
+		if !c.disableTracing {
+			c.setTraceHeaderFromContext(spanCtx, clonedReq)
+		}
 
 		resp, doErr := c.httpClient.Do(clonedReq)
+		endClientSpan(span, resp, doErr)
 		if !c.shouldRetry(req, resp, doErr, attempt, maxAttempts) {
 			return resp, doErr
 		}
 
+		delay := c.nextDelay(attempt)
+		logRetry(ctx, req, resp, doErr, attempt, delay)
+
 		if resp != nil && resp.Body != nil {
 			_ = resp.Body.Close()
 		}
 
-		if err := sleepWithContext(ctx, c.nextDelay(attempt)); err != nil {
+		if err := sleepWithContext(ctx, delay); err != nil {
 			return nil, err
 		}
 	}
@@ -129,16 +159,65 @@ func (c *Client) setTraceHeaderFromContext(ctx context.Context, req *http.Reques
 		req.Header = make(http.Header)
 	}
 
-	traceID := kitlog.GetTraceID(ctx)
-	if traceID == kitlog.Unknown {
-		traceID = kitlog.NewTraceID()
+	// ID 생성과 헤더 형식은 propagator가 정한다. 이전 시도의 헤더가 남지 않도록 먼저 지운다.
+	p := c.propagator
+	if p == nil {
+		p = propagation.Default()
+	}
+	for _, field := range p.Fields() {
+		req.Header.Del(field)
+	}
+	p.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	if debug := kitlog.GetDebugLog(ctx); debug != "" {
+		req.Header.Set(kitlog.DebugHeader, debug)
+	}
+}
+
+func httpClientAttributes(req *http.Request, attempt int) []zap.Field {
+	fields := []zap.Field{
+		zap.String("http.request.method", req.Method),
+		zap.String("server.address", req.URL.Hostname()),
+		zap.String("url.full", req.URL.Redacted()),
+	}
+	if attempt > 1 {
+		fields = append(fields, zap.Int("http.request.resend_count", attempt-1))
+	}
+	return fields
+}
+
+// endClientSpan ends the span of one attempt. A transport error or a 4xx/5xx
+// status fails it.
+func endClientSpan(span *trace.Span, resp *http.Response, err error) {
+	defer span.End()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(trace.StatusError, err.Error())
+		return
+	}
+
+	span.SetAttributes(zap.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(trace.StatusError, http.StatusText(resp.StatusCode))
+	}
+}
+
+func logRetry(ctx context.Context, req *http.Request, resp *http.Response, err error, attempt int, delay time.Duration) {
+	fields := append(
+		kitlog.FromContext(ctx),
+		zap.String("method", req.Method),
+		zap.String("host", req.URL.Host),
+		zap.String("path", req.URL.Path),
+		zap.Int("attempt", attempt),
+		zap.Int64("delay", delay.Milliseconds()),
+	)
+	if err != nil {
+		fields = append(fields, kitlog.Err(err))
+	} else if resp != nil {
+		fields = append(fields, zap.Int("status", resp.StatusCode))
 	}
-	req.Header.Set(kitlog.TraceHeader, traceID)
 
-	// pSpanID는 현재 spanId와 동일한 값으로 내려준다. 다음 client 입장에서는 parent.
-	// 다음 spanID는 새로 생성해서 내려준다.
-	req.Header.Set(kitlog.PSpanHeader, kitlog.GetSpanID(ctx))
-	req.Header.Set(kitlog.SpanHeader, kitlog.NewSpanID())
+	kitlog.Named(loggerName).Debug("http request retry", fields...)
 }
 
 func (c *Client) shouldRetry(originReq *http.Request, resp *http.Response, err error, attempt, maxAttempts int) bool {
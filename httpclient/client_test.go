@@ -11,26 +11,23 @@ import (
 	"time"
 
 	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+	"github.com/NamhaeSusan/my-go-kit/propagation"
+	"github.com/NamhaeSusan/my-go-kit/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestClientInjectsTraceFromContext(t *testing.T) {
+	var got http.Header
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if got := r.Header.Get(kitlog.TraceHeader); got != "trace-ctx-1" {
-			t.Fatalf("unexpected trace header: %q", got)
-		}
-		// spanID는 새로 생성되어야 한다 (원본 span-ctx-1이 아닌 값).
-		if got := r.Header.Get(kitlog.SpanHeader); got == "" || got == "span-ctx-1" {
-			t.Fatalf("span header should be newly generated, got: %q", got)
-		}
-		// 현재 spanID가 다음 서비스의 pSpanID로 전파된다.
-		if got := r.Header.Get(kitlog.PSpanHeader); got != "span-ctx-1" {
-			t.Fatalf("unexpected pspan header: %q", got)
-		}
+		got = r.Header.Clone()
 		w.WriteHeader(http.StatusOK)
 	}))
 	defer server.Close()
 
-	client := New(Config{HTTPClient: server.Client()})
+	httpClient, spans := newSpanRecordingClient(server)
+	client := New(Config{HTTPClient: httpClient})
 	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
 	if err != nil {
 		t.Fatalf("failed to build request: %v", err)
@@ -45,25 +42,129 @@ func TestClientInjectsTraceFromContext(t *testing.T) {
 		t.Fatalf("Do returned error: %v", err)
 	}
 	_ = resp.Body.Close()
+
+	if got := got.Get(kitlog.TraceHeader); got != "trace-ctx-1" {
+		t.Fatalf("unexpected trace header: %q", got)
+	}
+	// spanID는 새로 생성되어야 한다 (원본 span-ctx-1이 아닌 값).
+	if got := got.Get(kitlog.SpanHeader); got == "" || got == "span-ctx-1" {
+		t.Fatalf("span header should be newly generated, got: %q", got)
+	}
+	// 현재 span의 자식인 client span이 다음 서비스의 pSpanID로 전파된다.
+	span := spans.only(t)
+	if span.ParentSpanID() != "span-ctx-1" || span.TraceID() != "trace-ctx-1" || span.Kind() != trace.SpanKindClient {
+		t.Fatalf("unexpected client span: %s/%s/%s", span.TraceID(), span.ParentSpanID(), span.Kind())
+	}
+	if got := got.Get(kitlog.PSpanHeader); got != span.SpanID() {
+		t.Fatalf("unexpected pspan header: %q", got)
+	}
 }
 
-func TestClientGeneratesTraceWhenMissing(t *testing.T) {
+func TestClientSendsTraceParent(t *testing.T) {
+	const traceID, spanID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	httpClient, spans := newSpanRecordingClient(server)
+	client := New(Config{HTTPClient: httpClient})
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+
+	ctx := kitlog.WithTraceID(context.Background(), traceID)
+	ctx = kitlog.WithSpanID(ctx, spanID)
+	ctx = kitlog.WithSampled(ctx, false)
+	ctx = kitlog.WithTraceState(ctx, "vendor=abc")
+
+	resp, err := client.Do(ctx, req)
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	span := spans.only(t)
+	if span.ParentSpanID() != spanID {
+		t.Fatalf("unexpected parent of the client span: %q", span.ParentSpanID())
+	}
+	if got := got.Get(propagation.TraceParentHeader); got != "00-"+traceID+"-"+span.SpanID()+"-00" {
+		t.Errorf("unexpected traceparent: %q", got)
+	}
+	if got := got.Get(propagation.TraceStateHeader); got != "vendor=abc" {
+		t.Errorf("unexpected tracestate: %q", got)
+	}
+}
+
+func TestClientUsesConfiguredPropagator(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if got := r.Header.Get(kitlog.TraceHeader); got == "" {
-			t.Fatalf("unexpected trace header: %q", got)
+		if got := r.Header.Get(kitlog.TraceHeader); got != "" {
+			t.Errorf("kit headers should not be sent: %q", got)
+		}
+		if got := r.Header.Get(propagation.B3Header); !strings.HasPrefix(got, "4bf92f3577b34da6a3ce929d0e0e4736-") {
+			t.Errorf("unexpected b3 header: %q", got)
 		}
-		if got := r.Header.Get(kitlog.SpanHeader); got == "" {
-			t.Fatalf("unexpected span header: %q", got)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := New(Config{HTTPClient: server.Client(), Propagator: propagation.B3{SingleHeader: true}})
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	req.Header.Set(propagation.B3Header, "stale")
+
+	resp, err := client.Do(kitlog.WithTraceID(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736"), req)
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	_ = resp.Body.Close()
+}
+
+func TestClientPropagatesDebugLogFlag(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get(kitlog.DebugHeader))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	debugCtx, release := kitlog.WithDebugLog(context.Background(), "token-1")
+	defer release()
+
+	client := New(Config{HTTPClient: server.Client()})
+	for _, ctx := range []context.Context{context.Background(), debugCtx} {
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		if err != nil {
+			t.Fatalf("failed to build request: %v", err)
 		}
-		if got := r.Header.Get(kitlog.PSpanHeader); got != "unknown" {
-			t.Fatalf("unexpected pspan header: %q", got)
+		resp, err := client.Do(ctx, req)
+		if err != nil {
+			t.Fatalf("Do returned error: %v", err)
 		}
+		_ = resp.Body.Close()
+	}
+
+	if len(got) != 2 || got[0] != "" || got[1] != "token-1" {
+		t.Fatalf("unexpected debug headers: %q", got)
+	}
+}
+
+func TestClientGeneratesTraceWhenMissing(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
 		w.WriteHeader(http.StatusOK)
 	}))
 	defer server.Close()
 
+	httpClient, spans := newSpanRecordingClient(server)
 	client := New(Config{
-		HTTPClient: server.Client(),
+		HTTPClient: httpClient,
 	})
 
 	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
@@ -76,6 +177,128 @@ func TestClientGeneratesTraceWhenMissing(t *testing.T) {
 		t.Fatalf("Do returned error: %v", err)
 	}
 	_ = resp.Body.Close()
+
+	if got := got.Get(kitlog.TraceHeader); got == "" {
+		t.Fatalf("unexpected trace header: %q", got)
+	}
+	if got := got.Get(kitlog.SpanHeader); got == "" {
+		t.Fatalf("unexpected span header: %q", got)
+	}
+	// 새 trace의 root는 client span이다.
+	span := spans.only(t)
+	if span.ParentSpanID() != "" || span.TraceID() != got.Get(kitlog.TraceHeader) {
+		t.Fatalf("the client span should be the root: %s/%q", span.TraceID(), span.ParentSpanID())
+	}
+	if got := got.Get(kitlog.PSpanHeader); got != span.SpanID() {
+		t.Fatalf("unexpected pspan header: %q", got)
+	}
+}
+
+func TestClientRecordsSpanPerAttempt(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	httpClient, spans := newSpanRecordingClient(server)
+	client := New(Config{
+		HTTPClient: httpClient,
+		Retry:      RetryConfig{MaxAttempts: 2, BaseDelay: time.Millisecond},
+	})
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/orders", nil)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+
+	resp, err := client.Do(kitlog.WithSpanID(context.Background(), "span-ctx-1"), req)
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if len(spans.spans) != 2 {
+		t.Fatalf("expected one span per attempt, got: %d", len(spans.spans))
+	}
+	failed, ok := spans.spans[0], spans.spans[1]
+	if !failed.Ended() || failed.Status().Code != trace.StatusError || failed.Name() != http.MethodGet {
+		t.Fatalf("unexpected failed attempt: %s ended=%v %+v", failed.Name(), failed.Ended(), failed.Status())
+	}
+	if !ok.Ended() || ok.Status().Code != trace.StatusUnset || ok.ParentSpanID() != failed.ParentSpanID() {
+		t.Fatalf("unexpected retried attempt: %+v", ok.Status())
+	}
+	attrs := map[string]zap.Field{}
+	for _, f := range ok.Attributes() {
+		attrs[f.Key] = f
+	}
+	if attrs["http.response.status_code"].Integer != http.StatusOK || attrs["http.request.resend_count"].Integer != 1 ||
+		!strings.HasSuffix(attrs["url.full"].String, "/orders") {
+		t.Fatalf("unexpected attributes: %v", ok.Attributes())
+	}
+}
+
+func TestClientDisableTracing(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	httpClient, spans := newSpanRecordingClient(server)
+	client := New(Config{HTTPClient: httpClient, DisableTracing: true})
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+
+	ctx := kitlog.WithTraceID(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	ctx, release := kitlog.WithDebugLog(ctx, "token-1")
+	defer release()
+	resp, err := client.Do(ctx, req)
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if len(spans.spans) != 1 || spans.spans[0] != nil {
+		t.Fatalf("expected no client span, got: %v", spans.spans)
+	}
+	for _, h := range []string{kitlog.TraceHeader, kitlog.SpanHeader, propagation.TraceParentHeader, kitlog.DebugHeader} {
+		if v := got.Get(h); v != "" {
+			t.Fatalf("unexpected %s header: %q", h, v)
+		}
+	}
+}
+
+// spanRecorder records the client span of every request it sends.
+type spanRecorder struct {
+	base  http.RoundTripper
+	spans []*trace.Span
+}
+
+func newSpanRecordingClient(server *httptest.Server) (*http.Client, *spanRecorder) {
+	client := server.Client()
+	recorder := &spanRecorder{base: client.Transport}
+	client.Transport = recorder
+	return client, recorder
+}
+
+func (r *spanRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.spans = append(r.spans, trace.SpanFromContext(req.Context()))
+	return r.base.RoundTrip(req)
+}
+
+func (r *spanRecorder) only(t *testing.T) *trace.Span {
+	t.Helper()
+	if len(r.spans) != 1 || r.spans[0] == nil {
+		t.Fatalf("expected one client span, got: %v", r.spans)
+	}
+	return r.spans[0]
 }
 
 func TestClientRetriesOnRetryableStatus(t *testing.T) {
@@ -184,3 +407,60 @@ type roundTripperFunc func(*http.Request) (*http.Response, error)
 func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
 	return f(req)
 }
+
+func TestClientLogsRetriesThroughNamedLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	prev := zap.L()
+	zap.ReplaceGlobals(zap.New(core))
+	t.Cleanup(func() { zap.ReplaceGlobals(prev) })
+
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := New(Config{
+		HTTPClient: server.Client(),
+		Retry: RetryConfig{
+			MaxAttempts: 2,
+			BaseDelay:   1 * time.Millisecond,
+		},
+	})
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/items", nil)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+
+	resp, err := client.Do(kitlog.WithTraceID(context.Background(), "trace-retry"), req)
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if logs.Len() != 1 {
+		t.Fatalf("expected one retry log, got: %d", logs.Len())
+	}
+	entry := logs.All()[0]
+	if entry.Level != zapcore.DebugLevel {
+		t.Fatalf("retries should be logged at debug level, got: %s", entry.Level)
+	}
+	if entry.LoggerName != loggerName {
+		t.Fatalf("unexpected logger name: %q", entry.LoggerName)
+	}
+	fields := entry.ContextMap()
+	if got := fields["status"]; got != int64(http.StatusBadGateway) {
+		t.Fatalf("unexpected status: %#v", got)
+	}
+	if got := fields["path"]; got != "/items" {
+		t.Fatalf("unexpected path: %#v", got)
+	}
+	if got := fields["traceId"]; got != "trace-retry" {
+		t.Fatalf("unexpected traceId: %#v", got)
+	}
+}
@@ -0,0 +1,219 @@
+package log
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultAsyncBufferSize    = 8192
+	defaultAsyncFlushInterval = time.Second
+	defaultAsyncFlushSize     = 256 * 1024 // 256KB
+)
+
+// OverflowPolicy decides what an async writer does when its buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes the caller wait until there is room.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest discards the entry being written.
+	OverflowDropNewest
+	// OverflowDropOldest discards the oldest buffered entry.
+	OverflowDropOldest
+)
+
+// AsyncConfig moves writes off the caller's goroutine. Entries are kept in a
+// bounded buffer and written in batches by a background goroutine.
+type AsyncConfig struct {
+	// BufferSize is the number of entries the buffer holds. Defaults to 8192.
+	BufferSize int
+	// Policy applies when the buffer is full. Defaults to OverflowBlock.
+	Policy OverflowPolicy
+	// FlushInterval is the longest time an entry stays buffered. Defaults to
+	// 1s.
+	FlushInterval time.Duration
+	// FlushSize flushes as soon as this many bytes are buffered. Defaults to
+	// 256KB.
+	FlushSize int
+}
+
+func checkAsyncConfig(cfg *AsyncConfig) error {
+	switch cfg.Policy {
+	case OverflowBlock, OverflowDropNewest, OverflowDropOldest:
+	default:
+		return errors.New("log: unknown async overflow policy")
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaultAsyncBufferSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultAsyncFlushInterval
+	}
+	if cfg.FlushSize <= 0 {
+		cfg.FlushSize = defaultAsyncFlushSize
+	}
+	return nil
+}
+
+// asyncWriter is a zapcore.WriteSyncer that buffers encoded entries in a ring
+// and writes them to out from a background goroutine.
+type asyncWriter struct {
+	out    zapcore.WriteSyncer
+	policy OverflowPolicy
+	size   int
+
+	mu      sync.Mutex
+	notFull *sync.Cond
+	ring    [][]byte
+	head    int
+	count   int
+	pending int
+	closed  bool
+
+	// flushMu keeps batches in order between the background goroutine and
+	// Sync.
+	flushMu sync.Mutex
+	batch   []byte
+
+	dropped atomic.Uint64
+	wake    chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func newAsyncWriter(out zapcore.WriteSyncer, cfg AsyncConfig) *asyncWriter {
+	w := &asyncWriter{
+		out:     out,
+		policy:  cfg.Policy,
+		size:    cfg.FlushSize,
+		ring:    make([][]byte, cfg.BufferSize),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	w.notFull = sync.NewCond(&w.mu)
+	go w.run(cfg.FlushInterval)
+	return w
+}
+
+func (w *asyncWriter) run(interval time.Duration) {
+	defer close(w.stopped)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.wake:
+		case <-w.done:
+			return
+		}
+		_ = w.flush()
+	}
+}
+
+// Write copies p into the buffer; zap reuses p after Write returns. After
+// Close, p is dropped: out may already be closed by then.
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		w.dropped.Add(1)
+		return len(p), nil
+	}
+
+	for w.count == len(w.ring) {
+		switch w.policy {
+		case OverflowDropNewest:
+			w.mu.Unlock()
+			w.dropped.Add(1)
+			return len(p), nil
+		case OverflowDropOldest:
+			w.pending -= len(w.ring[w.head])
+			w.ring[w.head] = nil
+			w.head = (w.head + 1) % len(w.ring)
+			w.count--
+			w.dropped.Add(1)
+		default:
+			w.signal()
+			w.notFull.Wait()
+			if w.closed {
+				w.mu.Unlock()
+				w.dropped.Add(1)
+				return len(p), nil
+			}
+		}
+	}
+
+	w.ring[(w.head+w.count)%len(w.ring)] = append([]byte(nil), p...)
+	w.count++
+	w.pending += len(p)
+	if w.pending >= w.size {
+		w.signal()
+	}
+	w.mu.Unlock()
+	return len(p), nil
+}
+
+func (w *asyncWriter) signal() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// flush writes every buffered entry to out as one batch.
+func (w *asyncWriter) flush() error {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	w.mu.Lock()
+	w.batch = w.batch[:0]
+	for ; w.count > 0; w.count-- {
+		w.batch = append(w.batch, w.ring[w.head]...)
+		w.ring[w.head] = nil
+		w.head = (w.head + 1) % len(w.ring)
+	}
+	w.pending = 0
+	w.notFull.Broadcast()
+	w.mu.Unlock()
+
+	if len(w.batch) == 0 {
+		return nil
+	}
+	_, err := w.out.Write(w.batch)
+	return err
+}
+
+// Sync flushes the buffer and syncs out. zap calls it for entries above
+// ErrorLevel, so Fatal entries are written before the process exits.
+func (w *asyncWriter) Sync() error {
+	return errors.Join(w.flush(), w.out.Sync())
+}
+
+// Close stops the background goroutine after draining the buffer. Later
+// writes are dropped and counted by Dropped.
+func (w *asyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.notFull.Broadcast()
+	w.mu.Unlock()
+
+	close(w.done)
+	<-w.stopped
+	return w.Sync()
+}
+
+// Dropped returns the number of entries discarded because the buffer was
+// full or the writer was closed.
+func (w *asyncWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
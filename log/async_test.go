@@ -0,0 +1,192 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingWriter records writes and blocks them until release is closed.
+type blockingWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	writes  int
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	if w.release != nil {
+		<-w.release
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes++
+	return w.buf.Write(p)
+}
+
+func (w *blockingWriter) Sync() error { return nil }
+
+func (w *blockingWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func newAsyncWriterForTest(t *testing.T, out *blockingWriter, cfg AsyncConfig) *asyncWriter {
+	t.Helper()
+	if err := checkAsyncConfig(&cfg); err != nil {
+		t.Fatalf("checkAsyncConfig returned error: %v", err)
+	}
+	w := newAsyncWriter(out, cfg)
+	t.Cleanup(func() { _ = w.Close() })
+	return w
+}
+
+func TestAsyncWriterBatchesUntilSync(t *testing.T) {
+	out := &blockingWriter{}
+	w := newAsyncWriterForTest(t, out, AsyncConfig{FlushInterval: time.Hour})
+
+	for i := range 3 {
+		_, _ = fmt.Fprintf(w, "line %d\n", i)
+	}
+	if got := out.String(); got != "" {
+		t.Fatalf("writes should be buffered, got: %q", got)
+	}
+	if err := w.Sync(); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	if got := out.String(); got != "line 0\nline 1\nline 2\n" || out.writes != 1 {
+		t.Fatalf("expected one ordered batch, got %d writes: %q", out.writes, got)
+	}
+}
+
+func TestAsyncWriterFlushesOnSizeAndInterval(t *testing.T) {
+	out := &blockingWriter{}
+	w := newAsyncWriterForTest(t, out, AsyncConfig{FlushInterval: time.Hour, FlushSize: 8})
+
+	_, _ = w.Write([]byte("0123456789\n"))
+	waitFor(t, func() bool { return out.String() != "" })
+
+	out2 := &blockingWriter{}
+	w2 := newAsyncWriterForTest(t, out2, AsyncConfig{FlushInterval: 10 * time.Millisecond})
+	_, _ = w2.Write([]byte("tick\n"))
+	waitFor(t, func() bool { return out2.String() == "tick\n" })
+}
+
+func TestAsyncWriterOverflowPolicies(t *testing.T) {
+	tests := []struct {
+		policy OverflowPolicy
+		want   string
+	}{
+		{policy: OverflowDropNewest, want: "a\nb\n"},
+		{policy: OverflowDropOldest, want: "c\nd\n"},
+	}
+	for _, tt := range tests {
+		out := &blockingWriter{}
+		w := newAsyncWriterForTest(t, out, AsyncConfig{BufferSize: 2, Policy: tt.policy, FlushInterval: time.Hour})
+		for _, line := range []string{"a\n", "b\n", "c\n", "d\n"} {
+			_, _ = w.Write([]byte(line))
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close returned error: %v", err)
+		}
+		if got := out.String(); got != tt.want {
+			t.Fatalf("policy %d: unexpected output: %q", tt.policy, got)
+		}
+		if got := w.Dropped(); got != 2 {
+			t.Fatalf("policy %d: unexpected dropped count: %d", tt.policy, got)
+		}
+	}
+}
+
+func TestAsyncWriterBlockPolicyWaitsForRoom(t *testing.T) {
+	out := &blockingWriter{release: make(chan struct{})}
+	w := newAsyncWriterForTest(t, out, AsyncConfig{BufferSize: 1, FlushInterval: time.Hour})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, line := range []string{"a\n", "b\n", "c\n"} {
+			_, _ = w.Write([]byte(line))
+		}
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("writer should block while the buffer is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(out.release)
+	<-done
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if got := out.String(); got != "a\nb\nc\n" || w.Dropped() != 0 {
+		t.Fatalf("unexpected output: %q (dropped %d)", got, w.Dropped())
+	}
+}
+
+func TestAsyncWriterDropsWritesAfterClose(t *testing.T) {
+	out := &blockingWriter{}
+	w := newAsyncWriterForTest(t, out, AsyncConfig{FlushInterval: time.Hour})
+
+	_, _ = w.Write([]byte("before\n"))
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if n, err := w.Write([]byte("after\n")); n != len("after\n") || err != nil {
+		t.Fatalf("unexpected write result: %d, %v", n, err)
+	}
+	if got := out.String(); got != "before\n" || w.Dropped() != 1 {
+		t.Fatalf("a write after Close should be dropped: %q (dropped %d)", got, w.Dropped())
+	}
+}
+
+func TestCheckAsyncConfigRejectsUnknownPolicy(t *testing.T) {
+	if _, err := New(Config{Stdout: new(false), Async: &AsyncConfig{Policy: OverflowPolicy(99)}}); err == nil {
+		t.Fatal("expected error for unknown policy")
+	}
+}
+
+func TestCloseDrainsAsyncFileOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l, err := New(Config{Stdout: new(false), FilePath: path, Async: &AsyncConfig{FlushInterval: time.Hour}})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	for i := range 100 {
+		l.Zap().Info(fmt.Sprintf("entry %d", i))
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if got := strings.Count(string(data), "\n"); got != 100 {
+		t.Fatalf("expected 100 lines after Close, got: %d", got)
+	}
+	if l.AsyncDropped() != 0 {
+		t.Fatalf("unexpected dropped count: %d", l.AsyncDropped())
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
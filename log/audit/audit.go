@@ -0,0 +1,488 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+)
+
+const (
+	defaultMaxSize       = 100 // 100MB
+	defaultBatchSize     = 100
+	defaultBatchInterval = time.Second
+	defaultFileMode      = 0o600
+	defaultDirMode       = 0o750
+
+	// hashSuffix precedes the hash at the end of every line.
+	hashSuffix = `,"hash":"`
+	hashLength = sha256.Size * 2
+)
+
+// genesis is the prev of the first record of a chain.
+var genesis = strings.Repeat("0", hashLength)
+
+// SyncPolicy decides when records are flushed to disk.
+type SyncPolicy int
+
+const (
+	// SyncEveryRecord fsyncs the file before Log returns.
+	SyncEveryRecord SyncPolicy = iota
+	// SyncBatch fsyncs after BatchSize records or BatchInterval, whichever
+	// comes first. Records written since the last fsync can be lost on a
+	// crash; the chain stays valid.
+	SyncBatch
+)
+
+// Config configures an audit Logger. Zero values fall back to the defaults.
+type Config struct {
+	// FilePath is the current audit file. Rotated files are kept next to it
+	// as <name>-<first seq>.<ext>. Required.
+	FilePath string
+	// Key makes the record hashes HMAC-SHA256 so that the chain cannot be
+	// recomputed after an edit without it. Verify needs the same key.
+	Key []byte
+
+	Sync SyncPolicy
+	// BatchSize and BatchInterval bound SyncBatch. Defaults to 100 records
+	// and 1s.
+	BatchSize     int
+	BatchInterval time.Duration
+
+	// MaxSize is the size in megabytes before rotation. Defaults to 100.
+	MaxSize int
+	// FileMode is applied when an audit file is created. Defaults to 0o600.
+	FileMode os.FileMode
+	// DirMode is applied when the directory is created. Defaults to 0o750.
+	DirMode os.FileMode
+}
+
+func checkConfig(cfg *Config) error {
+	if cfg.FilePath == "" {
+		return errors.New("audit: FilePath is required")
+	}
+	switch cfg.Sync {
+	case SyncEveryRecord, SyncBatch:
+	default:
+		return fmt.Errorf("audit: unknown sync policy %d", cfg.Sync)
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.BatchInterval <= 0 {
+		cfg.BatchInterval = defaultBatchInterval
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = defaultMaxSize
+	}
+	if cfg.FileMode == 0 {
+		cfg.FileMode = defaultFileMode
+	}
+	if cfg.DirMode == 0 {
+		cfg.DirMode = defaultDirMode
+	}
+	return nil
+}
+
+// Event is what an application records.
+type Event struct {
+	// Action is what happened, e.g. "user.delete". Required.
+	Action   string
+	Actor    string
+	Resource string
+	// Outcome is e.g. "success" or "denied".
+	Outcome string
+	Fields  map[string]any
+}
+
+// Record is one line of an audit file. Hash covers the line up to the hash
+// itself, including Prev, the hash of the previous record.
+type Record struct {
+	Seq      uint64         `json:"seq"`
+	Time     time.Time      `json:"time"`
+	Prev     string         `json:"prev"`
+	TraceID  string         `json:"traceId,omitempty"`
+	SpanID   string         `json:"spanId,omitempty"`
+	Action   string         `json:"action"`
+	Actor    string         `json:"actor,omitempty"`
+	Resource string         `json:"resource,omitempty"`
+	Outcome  string         `json:"outcome,omitempty"`
+	Fields   map[string]any `json:"fields,omitempty"`
+	Hash     string         `json:"hash"`
+}
+
+// Head identifies the last record of a chain. Keeping the Head of a Logger
+// outside the audit files (e.g. in the application log) lets Verify detect
+// records removed from the end of the current file.
+type Head struct {
+	Seq  uint64
+	Hash string
+}
+
+// Logger appends hash-chained records to an audit file. It is safe for
+// concurrent use.
+type Logger struct {
+	cfg     Config
+	maxSize int64
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	firstSeq uint64 // of the current file, 0 while it is empty
+	head     Head
+	pending  int
+	closed   bool
+
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// New opens cfg.FilePath and continues the chain of the records already in
+// it, or of the last rotated file when it is empty. It fails when the last
+// record is incomplete, e.g. after a crash during a write, so that the file
+// can be inspected before more records are appended.
+func New(cfg Config) (*Logger, error) {
+	if err := checkConfig(&cfg); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.FilePath), cfg.DirMode); err != nil {
+		return nil, fmt.Errorf("audit: %w", err)
+	}
+
+	l := &Logger{cfg: cfg, maxSize: int64(cfg.MaxSize) * 1024 * 1024, head: Head{Hash: genesis}}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	if cfg.Sync == SyncBatch {
+		l.done = make(chan struct{})
+		l.stopped = make(chan struct{})
+		go l.syncLoop()
+	}
+	return l, nil
+}
+
+// open opens the current file and restores the head of the chain.
+func (l *Logger) open() error {
+	first, last, err := readEnds(l.cfg.FilePath)
+	if err != nil {
+		return err
+	}
+	if last == nil {
+		// 현재 파일이 비어 있으면 마지막 보관 파일에서 체인을 이어간다.
+		rotated, err := RotatedFiles(l.cfg.FilePath)
+		if err != nil {
+			return err
+		}
+		if len(rotated) > 0 {
+			if _, last, err = readEnds(rotated[len(rotated)-1]); err != nil {
+				return err
+			}
+		}
+	}
+	if first != nil {
+		l.firstSeq = first.Seq
+	}
+	if last != nil {
+		l.head = Head{Seq: last.Seq, Hash: last.Hash}
+	}
+
+	f, err := os.OpenFile(l.cfg.FilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, l.cfg.FileMode)
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("audit: %w", err)
+	}
+	l.file = f
+	l.size = info.Size()
+	return syncDir(l.cfg.FilePath)
+}
+
+// Log appends ev with the trace and span IDs of ctx. With SyncEveryRecord
+// the record is on disk when Log returns nil.
+func (l *Logger) Log(ctx context.Context, ev Event) error {
+	if ev.Action == "" {
+		return errors.New("audit: Action is required")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return errors.New("audit: logger is closed")
+	}
+
+	rec := Record{
+		Seq:      l.head.Seq + 1,
+		Time:     time.Now().UTC(),
+		Prev:     l.head.Hash,
+		TraceID:  knownID(kitlog.GetTraceID(ctx)),
+		SpanID:   knownID(kitlog.GetSpanID(ctx)),
+		Action:   ev.Action,
+		Actor:    ev.Actor,
+		Resource: ev.Resource,
+		Outcome:  ev.Outcome,
+		Fields:   ev.Fields,
+	}
+	line, sum, err := encodeRecord(&rec, l.cfg.Key)
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	if l.size > 0 && l.size+int64(len(line)) > l.maxSize {
+		if err := l.rotateLocked(); err != nil {
+			return err
+		}
+	}
+	if n, err := l.file.Write(line); err != nil {
+		// 일부만 기록된 레코드가 체인을 끊지 않도록 잘라낸다.
+		if n > 0 {
+			_ = l.file.Truncate(l.size)
+		}
+		return fmt.Errorf("audit: %w", err)
+	}
+	l.size += int64(len(line))
+	if l.firstSeq == 0 {
+		l.firstSeq = rec.Seq
+	}
+	l.head = Head{Seq: rec.Seq, Hash: sum}
+
+	l.pending++
+	if l.cfg.Sync == SyncEveryRecord || l.pending >= l.cfg.BatchSize {
+		return l.syncLocked()
+	}
+	return nil
+}
+
+// Head returns the last record written by l.
+func (l *Logger) Head() Head {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.head
+}
+
+// Rotate moves the current file to <name>-<first seq>.<ext> and starts a new
+// one. The first record of the new file is chained to the last of the old
+// one. Rotating an empty file is a no-op.
+func (l *Logger) Rotate() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return errors.New("audit: logger is closed")
+	}
+	return l.rotateLocked()
+}
+
+func (l *Logger) rotateLocked() error {
+	if l.firstSeq == 0 {
+		return nil
+	}
+	if err := l.syncLocked(); err != nil {
+		return err
+	}
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	if err := os.Rename(l.cfg.FilePath, rotatedName(l.cfg.FilePath, l.firstSeq)); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	f, err := os.OpenFile(l.cfg.FilePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, l.cfg.FileMode)
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	l.file = f
+	l.size = 0
+	l.firstSeq = 0
+	return syncDir(l.cfg.FilePath)
+}
+
+// Sync flushes the records written so far to disk.
+func (l *Logger) Sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil
+	}
+	return l.syncLocked()
+}
+
+func (l *Logger) syncLocked() error {
+	if l.pending == 0 {
+		return nil
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	l.pending = 0
+	return nil
+}
+
+func (l *Logger) syncLoop() {
+	defer close(l.stopped)
+	ticker := time.NewTicker(l.cfg.BatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := l.Sync(); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "audit sync failed: %v\n", err)
+			}
+		case <-l.done:
+			return
+		}
+	}
+}
+
+// Close syncs and closes the file. Calling it more than once is a no-op.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	l.mu.Unlock()
+
+	if l.done != nil {
+		close(l.done)
+		<-l.stopped
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return errors.Join(l.syncLocked(), l.file.Close())
+}
+
+// encodeRecord returns the line of rec and its hash, and sets rec.Hash.
+func encodeRecord(rec *Record, key []byte) ([]byte, string, error) {
+	rec.Hash = ""
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return nil, "", err
+	}
+	// hash는 마지막 필드이므로 그 앞까지가 해시 대상이다.
+	body := bytes.TrimSuffix(data, []byte(hashSuffix+`"}`))
+	sum := hashBody(body, key)
+	rec.Hash = sum
+
+	line := make([]byte, 0, len(body)+len(hashSuffix)+hashLength+3)
+	line = append(line, body...)
+	line = append(line, hashSuffix...)
+	line = append(line, sum...)
+	line = append(line, '"', '}', '\n')
+	return line, sum, nil
+}
+
+func hashBody(body, key []byte) string {
+	var h hash.Hash
+	if key != nil {
+		h = hmac.New(sha256.New, key)
+	} else {
+		h = sha256.New()
+	}
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// readEnds returns the first and last records of path, nil for an empty or
+// missing file.
+func readEnds(path string) (first, last *Record, err error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("audit: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var line []byte
+	for n := 1; ; n++ {
+		line, err = r.ReadBytes('\n')
+		if len(line) == 0 && err != nil {
+			break
+		}
+		if line[len(line)-1] != '\n' {
+			return nil, nil, &VerifyError{File: path, Line: n, Reason: "incomplete record"}
+		}
+		rec, perr := parseLine(line)
+		if perr != nil {
+			return nil, nil, &VerifyError{File: path, Line: n, Reason: perr.Error()}
+		}
+		if first == nil {
+			first = rec
+		}
+		last = rec
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("audit: %w", err)
+	}
+	return first, last, nil
+}
+
+// rotatedName returns the name of the rotated file starting at seq. The
+// sequence is zero-padded so that the names sort in chain order.
+func rotatedName(path string, seq uint64) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s-%020d%s", strings.TrimSuffix(path, ext), seq, ext)
+}
+
+// RotatedFiles returns the rotated files of path, oldest first.
+func RotatedFiles(path string) ([]string, error) {
+	ext := filepath.Ext(path)
+	prefix := filepath.Base(strings.TrimSuffix(path, ext)) + "-"
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("audit: %w", err)
+	}
+
+	var files []string
+	for _, e := range entries {
+		name := e.Name()
+		seq, ok := strings.CutSuffix(strings.TrimPrefix(name, prefix), ext)
+		if !strings.HasPrefix(name, prefix) || !ok || len(seq) != 20 || strings.Trim(seq, "0123456789") != "" {
+			continue
+		}
+		files = append(files, filepath.Join(filepath.Dir(path), name))
+	}
+	slices.Sort(files)
+	return files, nil
+}
+
+func syncDir(path string) error {
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	defer dir.Close()
+	// 일부 파일시스템은 디렉터리 fsync를 지원하지 않으므로 오류를 무시한다.
+	_ = dir.Sync()
+	return nil
+}
+
+func knownID(id string) string {
+	if id == kitlog.Unknown {
+		return ""
+	}
+	return id
+}
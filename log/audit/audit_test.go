@@ -0,0 +1,174 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+)
+
+func newTestLogger(t *testing.T, cfg Config) *Logger {
+	t.Helper()
+	l, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	return l
+}
+
+func readRecords(t *testing.T, path string) []Record {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	var records []Record
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var rec Record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("invalid record %q: %v", line, err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestLogChainsRecordsWithTraceIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	l := newTestLogger(t, Config{FilePath: path})
+
+	ctx := kitlog.WithTraceID(context.Background(), "trace-1")
+	ctx = kitlog.WithSpanID(ctx, "span-1")
+	if err := l.Log(ctx, Event{Action: "user.delete", Actor: "admin", Resource: "user/42", Outcome: "success"}); err != nil {
+		t.Fatalf("Log returned error: %v", err)
+	}
+	if err := l.Log(context.Background(), Event{Action: "login", Fields: map[string]any{"ip": "10.0.0.1"}}); err != nil {
+		t.Fatalf("Log returned error: %v", err)
+	}
+
+	records := readRecords(t, path)
+	if len(records) != 2 {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+	first, second := records[0], records[1]
+	if first.Seq != 1 || first.Prev != genesis || first.TraceID != "trace-1" || first.SpanID != "span-1" {
+		t.Fatalf("unexpected first record: %+v", first)
+	}
+	if second.Seq != 2 || second.Prev != first.Hash || second.TraceID != "" || second.Fields["ip"] != "10.0.0.1" {
+		t.Fatalf("unexpected second record: %+v", second)
+	}
+	if head := l.Head(); head.Seq != 2 || head.Hash != second.Hash {
+		t.Fatalf("unexpected head: %+v", head)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != defaultFileMode {
+		t.Fatalf("unexpected file mode: %v (%v)", info, err)
+	}
+}
+
+func TestNewContinuesChainAfterRestartAndRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := New(Config{FilePath: path})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	_ = l.Log(context.Background(), Event{Action: "a"})
+	_ = l.Log(context.Background(), Event{Action: "b"})
+	if err := l.Rotate(); err != nil {
+		t.Fatalf("Rotate returned error: %v", err)
+	}
+	head := l.Head()
+	_ = l.Close()
+
+	// 회전 직후 재시작해도 보관 파일의 마지막 레코드에서 체인을 이어간다.
+	l = newTestLogger(t, Config{FilePath: path})
+	if got := l.Head(); got != head {
+		t.Fatalf("head was not restored: %+v, want %+v", got, head)
+	}
+	_ = l.Log(context.Background(), Event{Action: "c"})
+
+	rotated, err := RotatedFiles(path)
+	if err != nil || len(rotated) != 1 || filepath.Base(rotated[0]) != "audit-00000000000000000001.log" {
+		t.Fatalf("unexpected rotated files: %v (%v)", rotated, err)
+	}
+	if rec := readRecords(t, path)[0]; rec.Seq != 3 || rec.Prev != head.Hash {
+		t.Fatalf("the new file is not chained to the rotated one: %+v", rec)
+	}
+}
+
+func TestLogRotatesBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l := newTestLogger(t, Config{FilePath: path, MaxSize: 1})
+
+	big := strings.Repeat("x", 400*1024)
+	for range 5 {
+		if err := l.Log(context.Background(), Event{Action: "upload", Fields: map[string]any{"data": big}}); err != nil {
+			t.Fatalf("Log returned error: %v", err)
+		}
+	}
+	rotated, _ := RotatedFiles(path)
+	if len(rotated) != 2 {
+		t.Fatalf("expected 2 rotated files, got %v", rotated)
+	}
+	head, err := Verify(path, nil)
+	if err != nil || head.Seq != 5 {
+		t.Fatalf("unexpected verify result: %+v (%v)", head, err)
+	}
+}
+
+func TestNewRejectsIncompleteRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, _ := New(Config{FilePath: path})
+	_ = l.Log(context.Background(), Event{Action: "a"})
+	_ = l.Close()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("OpenFile returned error: %v", err)
+	}
+	_, _ = f.WriteString(`{"seq":2,"ti`)
+	_ = f.Close()
+
+	if _, err := New(Config{FilePath: path}); err == nil {
+		t.Fatal("expected error for an incomplete record")
+	}
+}
+
+func TestBatchSyncAndClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := New(Config{FilePath: path, Sync: SyncBatch, BatchSize: 2})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	for _, action := range []string{"a", "b", "c"} {
+		if err := l.Log(context.Background(), Event{Action: action}); err != nil {
+			t.Fatalf("Log returned error: %v", err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if err := l.Log(context.Background(), Event{Action: "d"}); err == nil {
+		t.Fatal("Log after Close should fail")
+	}
+	if n := len(readRecords(t, path)); n != 3 {
+		t.Fatalf("unexpected number of records: %d", n)
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	if err := checkConfig(&Config{}); err == nil {
+		t.Fatal("expected error without FilePath")
+	}
+	if err := checkConfig(&Config{FilePath: "a.log", Sync: 9}); err == nil {
+		t.Fatal("expected error for an unknown sync policy")
+	}
+	l := newTestLogger(t, Config{FilePath: filepath.Join(t.TempDir(), "b.log")})
+	if err := l.Log(context.Background(), Event{}); err == nil {
+		t.Fatal("expected error without Action")
+	}
+}
@@ -0,0 +1,116 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// VerifyError reports where an audit chain is broken.
+type VerifyError struct {
+	File string
+	// Line is 1-based.
+	Line   int
+	Reason string
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("audit: %s:%d: %s", e.File, e.Line, e.Reason)
+}
+
+// Verify checks the rotated files of path and path itself as one chain and
+// returns its last record. It detects edited, inserted, reordered and
+// removed records, a partially written last record and records removed from
+// the end of a rotated file. Records removed from the end of the current
+// file are only detected by comparing the result with a Head kept
+// elsewhere. key is Config.Key.
+//
+// The chain starts at the oldest file kept; a first record with Seq 1 must
+// have no predecessor.
+func Verify(path string, key []byte) (Head, error) {
+	files, err := RotatedFiles(path)
+	if err != nil {
+		return Head{}, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		files = append(files, path)
+	} else if !os.IsNotExist(err) {
+		return Head{}, fmt.Errorf("audit: %w", err)
+	}
+
+	var head Head
+	for _, file := range files {
+		if head, err = verifyFile(file, key, head); err != nil {
+			return Head{}, err
+		}
+	}
+	return head, nil
+}
+
+// verifyFile checks the records of file against prev, the last record of
+// the file before it. A zero prev trusts the first record.
+func verifyFile(file string, key []byte, prev Head) (Head, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return Head{}, fmt.Errorf("audit: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if len(line) == 0 && errors.Is(err, io.EOF) {
+			return prev, nil
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return Head{}, fmt.Errorf("audit: %w", err)
+		}
+		fail := func(format string, args ...any) (Head, error) {
+			return Head{}, &VerifyError{File: file, Line: n, Reason: fmt.Sprintf(format, args...)}
+		}
+		if line[len(line)-1] != '\n' {
+			return fail("incomplete record")
+		}
+
+		rec, err := parseLine(line)
+		if err != nil {
+			return fail("%v", err)
+		}
+		if want := hashBody(recordBody(line), key); rec.Hash != want {
+			return fail("record %d was modified", rec.Seq)
+		}
+		switch {
+		case prev.Hash == "" && rec.Seq == 1 && rec.Prev != genesis:
+			return fail("record 1 has a predecessor")
+		case prev.Hash != "" && rec.Seq != prev.Seq+1:
+			return fail("expected record %d, found %d", prev.Seq+1, rec.Seq)
+		case prev.Hash != "" && rec.Prev != prev.Hash:
+			return fail("record %d does not follow record %d", rec.Seq, prev.Seq)
+		}
+		prev = Head{Seq: rec.Seq, Hash: rec.Hash}
+	}
+}
+
+// parseLine decodes one line of an audit file, including its newline.
+func parseLine(line []byte) (*Record, error) {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	tail := len(hashSuffix) + hashLength + 2
+	if len(line) < tail || !bytes.HasPrefix(line[len(line)-tail:], []byte(hashSuffix)) || !bytes.HasSuffix(line, []byte(`"}`)) {
+		return nil, errors.New("malformed record")
+	}
+	var rec Record
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return nil, fmt.Errorf("malformed record: %v", err)
+	}
+	return &rec, nil
+}
+
+// recordBody returns the part of line covered by the hash.
+func recordBody(line []byte) []byte {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	return line[:len(line)-len(hashSuffix)-hashLength-2]
+}
@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeChain writes n records, rotating after every rotateEvery records
+// when it is positive, and returns the path of the current file.
+func writeChain(t *testing.T, key []byte, n, rotateEvery int) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := New(Config{FilePath: path, Key: key})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	defer l.Close()
+	for i := 1; i <= n; i++ {
+		if err := l.Log(context.Background(), Event{Action: fmt.Sprintf("action-%d", i), Actor: "admin"}); err != nil {
+			t.Fatalf("Log returned error: %v", err)
+		}
+		if rotateEvery > 0 && i%rotateEvery == 0 && i < n {
+			if err := l.Rotate(); err != nil {
+				t.Fatalf("Rotate returned error: %v", err)
+			}
+		}
+	}
+	return path
+}
+
+func editLines(t *testing.T, path string, edit func(lines []string) []string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile returned error: %v", err)
+	}
+	lines := edit(strings.SplitAfter(string(data), "\n"))
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "")), 0o600); err != nil {
+		t.Fatalf("WriteFile returned error: %v", err)
+	}
+}
+
+func TestVerifyAcceptsIntactChain(t *testing.T) {
+	key := []byte("secret")
+	path := writeChain(t, key, 7, 3)
+	head, err := Verify(path, key)
+	if err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+	if head.Seq != 7 {
+		t.Fatalf("unexpected head: %+v", head)
+	}
+	if _, err := Verify(path, []byte("other")); err == nil {
+		t.Fatal("Verify should fail with another key")
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	tests := map[string]func(t *testing.T, path string){
+		"edited record": func(t *testing.T, path string) {
+			editLines(t, path, func(lines []string) []string {
+				lines[1] = strings.Replace(lines[1], `"actor":"admin"`, `"actor":"guest"`, 1)
+				return lines
+			})
+		},
+		"removed record": func(t *testing.T, path string) {
+			editLines(t, path, func(lines []string) []string {
+				return append(lines[:1], lines[2:]...)
+			})
+		},
+		"reordered records": func(t *testing.T, path string) {
+			editLines(t, path, func(lines []string) []string {
+				lines[0], lines[1] = lines[1], lines[0]
+				return lines
+			})
+		},
+		"partial last record": func(t *testing.T, path string) {
+			editLines(t, path, func(lines []string) []string {
+				last := len(lines) - 2 // SplitAfter는 끝에 빈 문자열을 남긴다.
+				lines[last] = lines[last][:len(lines[last])/2]
+				return lines[:last+1]
+			})
+		},
+		"truncated rotated file": func(t *testing.T, path string) {
+			rotated, _ := RotatedFiles(path)
+			editLines(t, rotated[0], func(lines []string) []string {
+				return lines[:len(lines)-2]
+			})
+		},
+	}
+	for name, tamper := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := writeChain(t, nil, 6, 3)
+			tamper(t, path)
+			_, err := Verify(path, nil)
+			var verr *VerifyError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected a VerifyError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyDetectsTruncatedCurrentFileWithHead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l := newTestLogger(t, Config{FilePath: path})
+	for _, action := range []string{"a", "b", "c"} {
+		_ = l.Log(context.Background(), Event{Action: action})
+	}
+	want := l.Head()
+	_ = l.Close()
+
+	editLines(t, path, func(lines []string) []string { return lines[:2] })
+	got, err := Verify(path, nil)
+	if err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+	if got == want {
+		t.Fatal("a head kept elsewhere should reveal the removed record")
+	}
+}
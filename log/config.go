@@ -0,0 +1,264 @@
+package log
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	EncodingJSON    = "json"
+	EncodingConsole = "console"
+	// EncodingLogfmt writes key=value pairs.
+	EncodingLogfmt = "logfmt"
+	// EncodingDev writes colored, human-readable lines with the trace and
+	// span IDs in a short prefix. Meant for local development.
+	EncodingDev = "dev"
+
+	// ModeEnv selects the mode of every Config at runtime: "development"
+	// (or "dev") sets Development, "production" (or "prod") clears it.
+	ModeEnv = "LOG_MODE"
+
+	defaultMaxSize  = 1024 // 1GB
+	defaultMaxAge   = 7    // 7 days
+	defaultFileMode = 0o600
+	defaultDirMode  = 0o750
+
+	defaultReloadInterval = 2 * time.Second
+
+	serviceFieldName = "service"
+	versionFieldName = "version"
+	hostFieldName    = "host"
+)
+
+// Config configures the global logger built by InitWithConfig. Zero values
+// fall back to the defaults used by Init.
+type Config struct {
+	// Level is the minimum enabled level. The zero value is zapcore.InfoLevel.
+	Level zapcore.Level
+	// Encoding is EncodingJSON (default), EncodingConsole, EncodingLogfmt or
+	// EncodingDev.
+	Encoding string
+	// Development writes EncodingDev to stdout and stderr, including the
+	// Stdout and Stderr sinks without an Encoding. Files and network sinks
+	// keep their encoding. ModeEnv overrides it.
+	Development bool
+	// NamedLevels overrides the level of loggers returned by Named, keyed by
+	// logger name (e.g. "grpc").
+	NamedLevels map[string]zapcore.Level
+	// LevelSignals switches to debug on SIGUSR1 and restores Level on
+	// SIGUSR2. Ignored on platforms without these signals.
+	LevelSignals bool
+	// LevelRevertAfter reverts a runtime level change made through
+	// LevelHandler or SIGUSR1 back to Level after this duration. 0 disables it.
+	LevelRevertAfter time.Duration
+
+	// CloseOnExit closes the logger before the process exits on a Fatal
+	// entry or on one of ExitSignals so that buffered entries are written.
+	CloseOnExit bool
+	// ExitSignals close the logger and then terminate the process with the
+	// signal's default action. Only used with CloseOnExit; defaults to
+	// os.Interrupt and syscall.SIGTERM. Leave out signals the application
+	// handles itself for a graceful shutdown.
+	ExitSignals []os.Signal
+
+	// Stdout writes to os.Stdout. nil means true.
+	Stdout *bool
+	// Stderr writes to os.Stderr.
+	Stderr bool
+	// FilePath enables file output with rotation when non-empty.
+	FilePath string
+	Rotation RotationConfig
+	// FileMode is applied when the log file is created. Defaults to 0o600.
+	FileMode os.FileMode
+	// DirMode is applied when the log directory is created. Defaults to 0o750.
+	DirMode os.FileMode
+	// Sinks are extra outputs that receive a level range, such as an
+	// error-only file. See SinkConfig.
+	Sinks []SinkConfig
+
+	// Async writes every output from a background goroutine when non-nil.
+	// Close drains the buffer.
+	Async *AsyncConfig
+
+	// Sampling limits repeated entries when non-nil. See SamplingConfig.
+	Sampling *SamplingConfig
+
+	// Redaction masks secrets in messages and fields before they are
+	// encoded. See DefaultRedactRules.
+	Redaction []RedactRule
+
+	// ReloadInterval is how often the file of a Config read by ConfigFromFile
+	// or ConfigFromEnv is checked for changes. Defaults to 2s; negative
+	// disables polling and leaves SIGHUP and Logger.Reload.
+	ReloadInterval time.Duration
+	// configFile and fromEnv record how the Config was read so that Reload
+	// can read it again.
+	configFile string
+	fromEnv    bool
+
+	// SlogDefault installs a SlogHandler of the global logger as
+	// slog.Default. It follows the global logger when it is replaced.
+	SlogDefault bool
+
+	// Static fields added to every entry. Empty values are omitted.
+	ServiceName string
+	Version     string
+	Host        string
+	Fields      map[string]string
+}
+
+// RotationConfig controls the rotation of Config.FilePath. Without Interval
+// and MaxTotalSize, lumberjack rotates the file in place. With either of
+// them, entries go to date-stamped files next to FilePath (app-2024-05-01.log,
+// app-2024-05-01.1.log, ...) and FilePath becomes a symlink to the current
+// one.
+type RotationConfig struct {
+	// Interval also rotates daily or hourly.
+	Interval RotationInterval
+	// MaxTotalSize caps the size in megabytes of the current file and the
+	// rotated files together; the oldest are removed first. 0 disables it.
+	MaxTotalSize int
+	// MaxSize is the size in megabytes before rotation. Defaults to 1024.
+	MaxSize int
+	// MaxAge is the number of days to retain rotated files. Defaults to 7.
+	MaxAge int
+	// MaxBackups is the number of rotated files to retain. 0 keeps all.
+	MaxBackups int
+	// Compress gzips rotated files. nil means true.
+	Compress *bool
+	// LocalTime uses local time in rotated file names. nil means true.
+	LocalTime *bool
+}
+
+func checkConfig(cfg *Config) error {
+	switch mode := os.Getenv(ModeEnv); strings.ToLower(mode) {
+	case "":
+	case "development", "dev":
+		cfg.Development = true
+	case "production", "prod":
+		cfg.Development = false
+	default:
+		return fmt.Errorf("log: unknown %s %q", ModeEnv, mode)
+	}
+	if cfg.Encoding == "" {
+		cfg.Encoding = EncodingJSON
+	}
+	if err := checkEncoding(cfg.Encoding); err != nil {
+		return err
+	}
+	if cfg.Async != nil {
+		async := *cfg.Async
+		if err := checkAsyncConfig(&async); err != nil {
+			return err
+		}
+		cfg.Async = &async
+	}
+	if cfg.Sampling != nil {
+		sampling := *cfg.Sampling
+		checkSamplingConfig(&sampling)
+		cfg.Sampling = &sampling
+	}
+	if cfg.configFile != "" && cfg.ReloadInterval == 0 {
+		cfg.ReloadInterval = defaultReloadInterval
+	}
+	if cfg.CloseOnExit && len(cfg.ExitSignals) == 0 {
+		cfg.ExitSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	if cfg.Stdout == nil {
+		cfg.Stdout = new(true)
+	}
+	if cfg.FileMode == 0 {
+		cfg.FileMode = defaultFileMode
+	}
+	if cfg.DirMode == 0 {
+		cfg.DirMode = defaultDirMode
+	}
+	if err := checkRotationConfig(&cfg.Rotation); err != nil {
+		return err
+	}
+	if len(cfg.Sinks) > 0 {
+		sinks := slices.Clone(cfg.Sinks)
+		paths := map[string]bool{}
+		for i := range sinks {
+			if err := checkSinkConfig(cfg, i, &sinks[i]); err != nil {
+				return err
+			}
+			if path := sinks[i].FilePath; path != "" {
+				if paths[path] {
+					return fmt.Errorf("log: sink %d writes to %q twice", i, path)
+				}
+				paths[path] = true
+			}
+		}
+		cfg.Sinks = sinks
+	}
+	return nil
+}
+
+func checkEncoding(encoding string) error {
+	switch encoding {
+	case EncodingJSON, EncodingConsole, EncodingLogfmt, EncodingDev:
+		return nil
+	default:
+		return fmt.Errorf("log: unknown encoding %q", encoding)
+	}
+}
+
+// consoleEncoding returns the encoding of stdout and stderr.
+func (cfg *Config) consoleEncoding() string {
+	if cfg.Development {
+		return EncodingDev
+	}
+	return cfg.Encoding
+}
+
+func checkRotationConfig(r *RotationConfig) error {
+	switch r.Interval {
+	case RotateNone, RotateDaily, RotateHourly:
+	default:
+		return fmt.Errorf("log: unknown rotation interval %d", r.Interval)
+	}
+	if r.MaxTotalSize < 0 {
+		r.MaxTotalSize = 0
+	}
+	if r.MaxSize <= 0 {
+		r.MaxSize = defaultMaxSize
+	}
+	if r.MaxAge <= 0 {
+		r.MaxAge = defaultMaxAge
+	}
+	if r.MaxBackups < 0 {
+		r.MaxBackups = 0
+	}
+	if r.Compress == nil {
+		r.Compress = new(true)
+	}
+	if r.LocalTime == nil {
+		r.LocalTime = new(true)
+	}
+	return nil
+}
+
+func (cfg *Config) staticFields() []zap.Field {
+	var fields []zap.Field
+	add := func(key, value string) {
+		if value != "" {
+			fields = append(fields, zap.String(key, value))
+		}
+	}
+	add(serviceFieldName, cfg.ServiceName)
+	add(versionFieldName, cfg.Version)
+	add(hostFieldName, cfg.Host)
+	for _, key := range slices.Sorted(maps.Keys(cfg.Fields)) {
+		add(key, cfg.Fields[key])
+	}
+	return fields
+}
@@ -0,0 +1,70 @@
+package log
+
+import (
+	"testing"
+)
+
+func TestCheckConfigAppliesDefaults(t *testing.T) {
+	var cfg Config
+	if err := checkConfig(&cfg); err != nil {
+		t.Fatalf("checkConfig returned error: %v", err)
+	}
+
+	if cfg.Encoding != EncodingJSON {
+		t.Fatalf("unexpected encoding: %q", cfg.Encoding)
+	}
+	if cfg.Stdout == nil || !*cfg.Stdout {
+		t.Fatal("stdout should default to true")
+	}
+	if cfg.Rotation.MaxSize != defaultMaxSize || cfg.Rotation.MaxAge != defaultMaxAge {
+		t.Fatalf("unexpected rotation limits: %+v", cfg.Rotation)
+	}
+	if !*cfg.Rotation.Compress || !*cfg.Rotation.LocalTime {
+		t.Fatalf("compress and local time should default to true: %+v", cfg.Rotation)
+	}
+	if cfg.FileMode != defaultFileMode || cfg.DirMode != defaultDirMode {
+		t.Fatalf("unexpected modes: file=%v dir=%v", cfg.FileMode, cfg.DirMode)
+	}
+}
+
+func TestCheckConfigKeepsExplicitValues(t *testing.T) {
+	cfg := Config{
+		Encoding: EncodingConsole,
+		Stdout:   new(false),
+		Rotation: RotationConfig{
+			MaxSize:    10,
+			MaxAge:     1,
+			MaxBackups: 3,
+			Compress:   new(false),
+		},
+	}
+	if err := checkConfig(&cfg); err != nil {
+		t.Fatalf("checkConfig returned error: %v", err)
+	}
+
+	if cfg.Encoding != EncodingConsole || *cfg.Stdout {
+		t.Fatalf("explicit values overwritten: %+v", cfg)
+	}
+	if cfg.Rotation.MaxSize != 10 || cfg.Rotation.MaxAge != 1 || cfg.Rotation.MaxBackups != 3 || *cfg.Rotation.Compress {
+		t.Fatalf("explicit rotation overwritten: %+v", cfg.Rotation)
+	}
+}
+
+func TestStaticFieldsOmitsEmptyValues(t *testing.T) {
+	cfg := Config{ServiceName: "svc", Fields: map[string]string{"b": "2", "a": "1", "empty": ""}}
+	fields := cfg.staticFields()
+
+	var keys []string
+	for _, f := range fields {
+		keys = append(keys, f.Key)
+	}
+	want := []string{serviceFieldName, "a", "b"}
+	if len(keys) != len(want) {
+		t.Fatalf("unexpected keys: %v", keys)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Fatalf("unexpected keys: %v", keys)
+		}
+	}
+}
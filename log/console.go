@@ -0,0 +1,144 @@
+package log
+
+import (
+	"os"
+	"strings"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// shortIDLength is the length of the trace and span IDs in the prefix of
+	// EncodingDev lines.
+	shortIDLength = 8
+
+	colorReset   = "\x1b[0m"
+	colorRed     = "\x1b[31m"
+	colorYellow  = "\x1b[33m"
+	colorBlue    = "\x1b[34m"
+	colorMagenta = "\x1b[35m"
+	colorGray    = "\x1b[90m"
+)
+
+// devEncoder writes one human-readable line per entry for local
+// development:
+//
+//	10:00:00.123 INFO  [4bf92f35/00f067aa] payment order.go:42 order created orderId=o-1
+//
+// The trace and span IDs of FromContext are shortened into the prefix; the
+// other fields follow the message as in logfmt. Colors are disabled when the
+// NO_COLOR environment variable is set.
+type devEncoder struct {
+	*logfmtEncoder
+	color bool
+}
+
+func newDevEncoder() *devEncoder {
+	return &devEncoder{logfmtEncoder: newLogfmtEncoder(), color: os.Getenv("NO_COLOR") == ""}
+}
+
+func (e *devEncoder) Clone() zapcore.Encoder {
+	return &devEncoder{logfmtEncoder: e.clone(), color: e.color}
+}
+
+func (e *devEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	traceID, spanID, rest := splitTraceFields(fields)
+
+	line := logfmtBuffers.Get()
+	e.colored(line, colorGray, ent.Time.Format("15:04:05.000"))
+	line.AppendByte(' ')
+	e.colored(line, levelColor(ent.Level), padLevel(ent.Level))
+	if traceID != "" {
+		line.AppendByte(' ')
+		e.colored(line, colorGray, "["+shortID(traceID)+"/"+shortID(spanID)+"]")
+	}
+	if ent.LoggerName != "" {
+		line.AppendByte(' ')
+		line.AppendString(ent.LoggerName)
+	}
+	if ent.Caller.Defined {
+		line.AppendByte(' ')
+		e.colored(line, colorGray, ent.Caller.TrimmedPath())
+	}
+	line.AppendByte(' ')
+	line.AppendString(ent.Message)
+
+	e.appendFields(line, rest)
+	if ent.Stack != "" {
+		line.AppendByte('\n')
+		line.AppendString(ent.Stack)
+	}
+	line.AppendByte('\n')
+	return line, nil
+}
+
+func (e *devEncoder) colored(buf *buffer.Buffer, color, s string) {
+	if !e.color {
+		buf.AppendString(s)
+		return
+	}
+	buf.AppendString(color)
+	buf.AppendString(s)
+	buf.AppendString(colorReset)
+}
+
+// splitTraceFields takes the trace fields added by FromContext out of
+// fields. Unknown IDs are returned as "".
+func splitTraceFields(fields []zapcore.Field) (traceID, spanID string, rest []zapcore.Field) {
+	rest = make([]zapcore.Field, 0, len(fields))
+	for _, f := range fields {
+		if f.Type == zapcore.StringType {
+			switch f.Key {
+			case traceFieldName:
+				traceID = knownID(f.String)
+				continue
+			case spanIDFieldName:
+				spanID = knownID(f.String)
+				continue
+			case pSpanIDFieldName:
+				continue
+			}
+		}
+		rest = append(rest, f)
+	}
+	return traceID, spanID, rest
+}
+
+func knownID(id string) string {
+	if id == Unknown {
+		return ""
+	}
+	return id
+}
+
+func shortID(id string) string {
+	if id == "" {
+		return "-"
+	}
+	if len(id) > shortIDLength {
+		return id[:shortIDLength]
+	}
+	return id
+}
+
+func padLevel(lvl zapcore.Level) string {
+	s := lvl.CapitalString()
+	if len(s) < 5 {
+		s += strings.Repeat(" ", 5-len(s))
+	}
+	return s
+}
+
+func levelColor(lvl zapcore.Level) string {
+	switch {
+	case lvl <= zapcore.DebugLevel:
+		return colorMagenta
+	case lvl == zapcore.InfoLevel:
+		return colorBlue
+	case lvl == zapcore.WarnLevel:
+		return colorYellow
+	default:
+		return colorRed
+	}
+}
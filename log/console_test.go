@@ -0,0 +1,77 @@
+package log
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestDevEncoderPrefixesShortTraceIDs(t *testing.T) {
+	enc := newDevEncoder()
+	enc.color = false
+
+	ctx := WithTraceID(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	ctx = WithSpanID(ctx, "00f067aa0ba902b7")
+	ctx = WithPSpanID(ctx, "p-1")
+	ent := zapcore.Entry{
+		Level:      zapcore.InfoLevel,
+		Time:       time.Date(2024, 5, 1, 10, 0, 0, 123e6, time.Local),
+		LoggerName: "payment",
+		Message:    "order created",
+		Caller:     zapcore.NewEntryCaller(0, "/src/app/order.go", 42, true),
+	}
+	fields := append(FromContext(ctx), zap.String("orderId", "o-1"))
+
+	got := encodeLine(t, enc, ent, fields...)
+	want := "10:00:00.123 INFO  [4bf92f35/00f067aa] payment app/order.go:42 order created orderId=o-1\n"
+	if got != want {
+		t.Fatalf("unexpected line:\n got: %q\nwant: %q", got, want)
+	}
+
+	got = encodeLine(t, enc, zapcore.Entry{Level: zapcore.ErrorLevel, Time: ent.Time, Message: "failed", Stack: "main.main"},
+		FromContext(context.Background())...)
+	if got != "10:00:00.123 ERROR failed\nmain.main\n" {
+		t.Fatalf("unknown IDs should be omitted and the stack printed as is: %q", got)
+	}
+}
+
+func TestDevEncoderColorsLevels(t *testing.T) {
+	enc := newDevEncoder()
+	enc.color = true
+	clone := enc.Clone()
+	clone.AddString("service", "order")
+
+	got := encodeLine(t, clone, zapcore.Entry{Level: zapcore.WarnLevel, Time: time.Now(), Message: "slow"})
+	if !strings.Contains(got, colorYellow+"WARN "+colorReset) || !strings.HasSuffix(got, " slow service=order\n") {
+		t.Fatalf("unexpected line: %q", got)
+	}
+}
+
+func TestDevelopmentSelectedByModeEnv(t *testing.T) {
+	t.Setenv(ModeEnv, "development")
+	cfg := Config{Sinks: []SinkConfig{{Stderr: true}, {FilePath: "error.log"}}}
+	if err := checkConfig(&cfg); err != nil {
+		t.Fatalf("checkConfig returned error: %v", err)
+	}
+	if !cfg.Development || cfg.consoleEncoding() != EncodingDev || cfg.Encoding != EncodingJSON {
+		t.Fatalf("development mode was not applied: %+v", cfg)
+	}
+	if cfg.Sinks[0].Encoding != EncodingDev || cfg.Sinks[1].Encoding != EncodingJSON {
+		t.Fatalf("unexpected sink encodings: %q %q", cfg.Sinks[0].Encoding, cfg.Sinks[1].Encoding)
+	}
+
+	t.Setenv(ModeEnv, "PROD")
+	cfg = Config{Development: true}
+	if err := checkConfig(&cfg); err != nil || cfg.Development {
+		t.Fatalf("production mode should clear Development: %v", err)
+	}
+
+	t.Setenv(ModeEnv, "staging")
+	if err := checkConfig(&Config{}); err == nil {
+		t.Fatal("expected error for unknown mode")
+	}
+}
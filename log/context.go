@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
 const (
@@ -24,10 +25,12 @@ const (
 type traceIDKeyType struct{}
 type spanIDKeyType struct{}
 type pSpanIDKeyType struct{}
+type fieldsKeyType struct{}
 
 var TraceIDKey traceIDKeyType
 var SpanIDKey spanIDKeyType
 var PSpanIDKey pSpanIDKeyType
+var FieldsKey fieldsKeyType
 
 func NewTraceID() string {
 	u := uuid.New()
@@ -101,3 +104,50 @@ func GetPSpanID(ctx context.Context) string {
 
 	return pSpanID
 }
+
+// WithFields returns a context carrying fields on top of those already stored
+// in ctx. FromContext, and therefore every context-aware helper, appends them
+// to each entry. A field replaces an earlier one with the same key.
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if len(fields) == 0 {
+		return ctx
+	}
+
+	prev := GetFields(ctx)
+	merged := make([]zap.Field, 0, len(prev)+len(fields))
+	for _, field := range prev {
+		if !containsKey(fields, field.Key) {
+			merged = append(merged, field)
+		}
+	}
+	for i, field := range fields {
+		if !containsKey(fields[i+1:], field.Key) {
+			merged = append(merged, field)
+		}
+	}
+
+	return context.WithValue(ctx, FieldsKey, merged)
+}
+
+// GetFields returns the fields stored by WithFields. The returned slice must
+// not be modified.
+func GetFields(ctx context.Context) []zap.Field {
+	if ctx == nil {
+		return nil
+	}
+
+	fields, _ := ctx.Value(FieldsKey).([]zap.Field)
+	return fields
+}
+
+func containsKey(fields []zap.Field, key string) bool {
+	for _, field := range fields {
+		if field.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -3,6 +3,8 @@ package log
 import (
 	"context"
 	"testing"
+
+	"go.uber.org/zap"
 )
 
 func TestWithTraceIDAndGetTraceID(t *testing.T) {
@@ -56,3 +58,42 @@ func TestGetPSpanIDMissingReturnsUnknown(t *testing.T) {
 		t.Fatalf("unexpected pSpanId: %q", got)
 	}
 }
+
+func TestWithFieldsStacksAndOverrides(t *testing.T) {
+	ctx := WithFields(context.Background(), zap.String("userId", "u-1"), zap.String("tenant", "t-1"))
+	child := WithFields(ctx, zap.String("route", "/orders"), zap.String("userId", "u-2"))
+
+	if got := fieldMap(GetFields(ctx)); len(got) != 2 || got["userId"] != "u-1" {
+		t.Fatalf("parent fields should be unchanged: %v", got)
+	}
+
+	fields := GetFields(child)
+	if len(fields) != 3 {
+		t.Fatalf("unexpected field count: %d", len(fields))
+	}
+	got := fieldMap(fields)
+	if got["userId"] != "u-2" || got["tenant"] != "t-1" || got["route"] != "/orders" {
+		t.Fatalf("unexpected fields: %v", got)
+	}
+}
+
+func TestWithFieldsNilContextAndNoFields(t *testing.T) {
+	ctx := WithFields(nil, zap.Int("n", 1)) //nolint:staticcheck // intentional nil context test
+	if got := len(GetFields(ctx)); got != 1 {
+		t.Fatalf("unexpected field count: %d", got)
+	}
+	if same := WithFields(ctx); same != ctx {
+		t.Fatal("WithFields without fields should return ctx")
+	}
+	if got := GetFields(nil); got != nil { //nolint:staticcheck // intentional nil context test
+		t.Fatalf("expected nil fields, got: %v", got)
+	}
+}
+
+func fieldMap(fields []zap.Field) map[string]string {
+	m := make(map[string]string, len(fields))
+	for _, f := range fields {
+		m[f.Key] = f.String
+	}
+	return m
+}
@@ -0,0 +1,168 @@
+package log
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DebugHeader carries the per-request debug flag between services.
+const DebugHeader = "X-Debug-Log"
+
+type debugLogKeyType struct{}
+
+// DebugLogKey is the context key of the flag added by WithDebugLog.
+var DebugLogKey debugLogKeyType
+
+// activeDebugLogs counts the contexts marked by WithDebugLog that are not
+// released yet. While it is non-zero the level cores let every entry through
+// to Write, where the flag is known.
+var activeDebugLogs atomic.Int64
+
+// debugLogMarker is the Interface of the field that marks an entry logged
+// with a debug context.
+type debugLogMarker struct{}
+
+// WithDebugLog returns a copy of ctx whose entries are logged at DebugLevel
+// regardless of the logger level. value is what is propagated in DebugHeader
+// to other services; empty means "1". Callers must check that the flag comes
+// from a trusted source, see DebugTrust.
+//
+// The caller must call release when the request ends. Until then every
+// disabled entry of the process takes the slower path that looks for the
+// flag; afterwards the entries of ctx follow the level again, except on a
+// logger that got the flag with With.
+func WithDebugLog(ctx context.Context, value string) (_ context.Context, release func()) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if value == "" {
+		value = "1"
+	}
+
+	activeDebugLogs.Add(1)
+	var once sync.Once
+	release = func() {
+		once.Do(func() { activeDebugLogs.Add(-1) })
+	}
+	return context.WithValue(ctx, DebugLogKey, value), release
+}
+
+// GetDebugLog returns the flag value stored by WithDebugLog, or "".
+func GetDebugLog(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	value, _ := ctx.Value(DebugLogKey).(string)
+	return value
+}
+
+// IsDebugLog reports whether ctx was marked by WithDebugLog.
+func IsDebugLog(ctx context.Context) bool {
+	return GetDebugLog(ctx) != ""
+}
+
+func debugLogField() zap.Field {
+	return zap.Field{Type: zapcore.SkipType, Interface: debugLogMarker{}}
+}
+
+func hasDebugLogField(fields []zapcore.Field) bool {
+	for _, field := range fields {
+		if field.Type != zapcore.SkipType {
+			continue
+		}
+		if _, ok := field.Interface.(debugLogMarker); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// DebugTrustConfig configures which callers may turn on per-request debug
+// logging.
+type DebugTrustConfig struct {
+	// Secret accepts a DebugHeader value equal to DebugToken(Secret, traceID)
+	// from any caller.
+	Secret string
+	// TrustedCIDRs accept any non-empty DebugHeader value from these
+	// networks, e.g. "10.0.0.0/8".
+	TrustedCIDRs []string
+}
+
+// DebugTrust checks DebugHeader values of incoming requests.
+type DebugTrust struct {
+	secret []byte
+	nets   []netip.Prefix
+}
+
+// NewDebugTrust parses cfg. A DebugTrust without a secret and networks
+// rejects every request.
+func NewDebugTrust(cfg DebugTrustConfig) (*DebugTrust, error) {
+	t := &DebugTrust{}
+	if cfg.Secret != "" {
+		t.secret = []byte(cfg.Secret)
+	}
+	for _, cidr := range cfg.TrustedCIDRs {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("log: invalid trusted CIDR %q: %w", cidr, err)
+		}
+		t.nets = append(t.nets, prefix.Masked())
+	}
+	return t, nil
+}
+
+// Allowed reports whether value turns on debug logging for the request of
+// traceID coming from remoteAddr ("ip" or "ip:port"). A nil DebugTrust
+// allows nothing.
+func (t *DebugTrust) Allowed(value, traceID, remoteAddr string) bool {
+	value = strings.TrimSpace(value)
+	if t == nil || value == "" {
+		return false
+	}
+
+	if len(t.secret) > 0 && traceID != "" && traceID != Unknown {
+		if hmac.Equal([]byte(value), []byte(DebugToken(string(t.secret), traceID))) {
+			return true
+		}
+	}
+
+	addr, ok := parseRemoteAddr(remoteAddr)
+	if !ok {
+		return false
+	}
+	for _, prefix := range t.nets {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// DebugToken returns the DebugHeader value that turns on debug logging for
+// traceID in services sharing secret: hex(HMAC-SHA256(secret, traceID)).
+func DebugToken(secret, traceID string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(traceID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func parseRemoteAddr(remoteAddr string) (netip.Addr, bool) {
+	if addrPort, err := netip.ParseAddrPort(remoteAddr); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+	addr, err := netip.ParseAddr(remoteAddr)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
@@ -0,0 +1,75 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWithDebugLogLogsDebugForOneContext(t *testing.T) {
+	logs := installLevelCoreForTest(t, zapcore.WarnLevel)
+	ctx := context.Background()
+	active := activeDebugLogs.Load()
+	debugCtx, release := WithDebugLog(ctx, "")
+
+	Debugf(debugCtx, "debug for this request")
+	Named("grpc").Info("named info", FromContext(debugCtx)...)
+	Named("grpc").With(FromContext(debugCtx)...).Debug("with fields")
+	Debugf(context.Background(), "other request")
+
+	if got := logs.Len(); got != 3 {
+		t.Fatalf("expected entries of the debug context only, got: %v", logs.All())
+	}
+	if GetDebugLog(debugCtx) != "1" || IsDebugLog(ctx) {
+		t.Fatal("unexpected debug flag")
+	}
+
+	release()
+	release()
+	if activeDebugLogs.Load() != active {
+		t.Fatal("release should end the debug context once")
+	}
+	Debugf(debugCtx, "after release")
+	if logs.Len() != 3 {
+		t.Fatalf("entries after release should follow the level, got: %v", logs.All())
+	}
+}
+
+func TestDebugTrustAllowed(t *testing.T) {
+	trust, err := NewDebugTrust(DebugTrustConfig{Secret: "s3cret", TrustedCIDRs: []string{"10.0.0.0/8", "::1/128"}})
+	if err != nil {
+		t.Fatalf("NewDebugTrust returned error: %v", err)
+	}
+	token := DebugToken("s3cret", "trace-1")
+
+	tests := []struct {
+		name       string
+		value      string
+		traceID    string
+		remoteAddr string
+		want       bool
+	}{
+		{name: "trusted network", value: "1", remoteAddr: "10.1.2.3:5000", want: true},
+		{name: "trusted ipv6", value: "1", remoteAddr: "[::1]:5000", want: true},
+		{name: "untrusted network", value: "1", remoteAddr: "192.168.0.1", want: false},
+		{name: "valid token", value: token, traceID: "trace-1", remoteAddr: "192.168.0.1", want: true},
+		{name: "token of other trace", value: token, traceID: "trace-2", remoteAddr: "192.168.0.1", want: false},
+		{name: "empty value", value: "", remoteAddr: "10.1.2.3", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trust.Allowed(tt.value, tt.traceID, tt.remoteAddr); got != tt.want {
+				t.Fatalf("Allowed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	var nilTrust *DebugTrust
+	if nilTrust.Allowed("1", "trace-1", "10.1.2.3") {
+		t.Fatal("nil DebugTrust should allow nothing")
+	}
+	if _, err := NewDebugTrust(DebugTrustConfig{TrustedCIDRs: []string{"not-a-cidr"}}); err == nil {
+		t.Fatal("expected error for invalid CIDR")
+	}
+}
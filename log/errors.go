@@ -0,0 +1,214 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// maxErrorDepth bounds how many errors of a chain are recorded.
+	maxErrorDepth = 32
+
+	grpcCodeFieldName    = "grpcCode"
+	grpcMessageFieldName = "grpcMessage"
+	grpcDetailsFieldName = "grpcDetails"
+)
+
+// Err returns fields describing err under the "error" key:
+//
+//   - "error": err.Error()
+//   - "errorChain": the unwrap chain as [{"message","type"}, ...]. A member
+//     created by errors.Join (or any Unwrap() []error) ends the chain and lists
+//     the chain of every joined error under "causes".
+//   - "errorStack": the stack captured by WithStack, if any.
+//   - "grpcCode", "grpcMessage", "grpcDetails": when err carries a gRPC status.
+//
+// Use it with the structured helpers: log.Error(ctx, "failed", log.Err(err)).
+func Err(err error) zap.Field {
+	return NamedErr("error", err)
+}
+
+// NamedErr is like Err with key in place of "error".
+func NamedErr(key string, err error) zap.Field {
+	if err == nil {
+		return zap.Skip()
+	}
+	return zap.Inline(errorFields{key: key, err: err})
+}
+
+type errorFields struct {
+	key string
+	err error
+}
+
+func (e errorFields) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString(e.key, e.err.Error())
+	if err := enc.AddArray(e.key+"Chain", errorChain{err: e.err}); err != nil {
+		return err
+	}
+	if stack := findStack(e.err, 0); stack != nil {
+		enc.AddString(e.key+"Stack", stack.stack())
+	}
+
+	// status.FromError는 감싼 에러의 전체 메시지를 쓰므로 원래 status를 직접 찾는다.
+	var se interface{ GRPCStatus() *status.Status }
+	if !errors.As(e.err, &se) {
+		return nil
+	}
+	st := se.GRPCStatus()
+	if st == nil {
+		return nil
+	}
+	enc.AddString(grpcCodeFieldName, st.Code().String())
+	enc.AddString(grpcMessageFieldName, st.Message())
+	if details := grpcDetails(st); len(details) > 0 {
+		return enc.AddReflected(grpcDetailsFieldName, details)
+	}
+	return nil
+}
+
+// errorChain encodes the errors reachable through Unwrap.
+type errorChain struct {
+	err   error
+	depth int
+}
+
+func (c errorChain) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	depth := c.depth
+	for err := c.err; err != nil && depth < maxErrorDepth; depth++ {
+		// WithStack은 메시지를 바꾸지 않으므로 체인에서 생략한다.
+		if s, ok := err.(*stackError); ok {
+			err = s.err
+			continue
+		}
+
+		joined, isJoin := err.(interface{ Unwrap() []error })
+		node := errorNode{err: err}
+		if isJoin {
+			node.causes = joined.Unwrap()
+			node.depth = depth + 1
+		}
+		if e := enc.AppendObject(node); e != nil {
+			return e
+		}
+		if isJoin {
+			return nil
+		}
+		err = errors.Unwrap(err)
+	}
+	return nil
+}
+
+type errorNode struct {
+	err    error
+	causes []error
+	depth  int
+}
+
+func (n errorNode) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("message", n.err.Error())
+	enc.AddString("type", fmt.Sprintf("%T", n.err))
+	if len(n.causes) == 0 {
+		return nil
+	}
+	return enc.AddArray("causes", zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error {
+		for _, cause := range n.causes {
+			if cause == nil {
+				continue
+			}
+			if err := enc.AppendArray(errorChain{err: cause, depth: n.depth}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// grpcDetails renders the details of st as protojson objects.
+func grpcDetails(st *status.Status) []json.RawMessage {
+	var details []json.RawMessage
+	for _, detail := range st.Details() {
+		msg, ok := detail.(proto.Message)
+		if !ok {
+			continue
+		}
+		data, err := protojson.Marshal(msg)
+		if err != nil {
+			continue
+		}
+		details = append(details, data)
+	}
+	return details
+}
+
+// WithStack returns err annotated with the stack of the caller. Err records
+// the innermost stack of a chain, so wrapping an annotated error again keeps
+// the original stack.
+func WithStack(err error) error {
+	if err == nil {
+		return nil
+	}
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	return &stackError{err: err, pcs: pcs[:n]}
+}
+
+type stackError struct {
+	err error
+	pcs []uintptr
+}
+
+func (e *stackError) Error() string { return e.err.Error() }
+
+func (e *stackError) Unwrap() error { return e.err }
+
+// stack formats the frames the way zap formats stacktraces.
+func (e *stackError) stack() string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(e.pcs)
+	for {
+		frame, more := frames.Next()
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(frame.Function)
+		b.WriteString("\n\t")
+		b.WriteString(frame.File)
+		b.WriteByte(':')
+		fmt.Fprint(&b, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// findStack returns the innermost stackError of err, searching joined errors
+// in order.
+func findStack(err error, depth int) *stackError {
+	var found *stackError
+	for ; err != nil && depth < maxErrorDepth; depth++ {
+		if s, ok := err.(*stackError); ok {
+			found = s
+		}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, cause := range joined.Unwrap() {
+				if s := findStack(cause, depth+1); s != nil {
+					return s
+				}
+			}
+			return found
+		}
+		err = errors.Unwrap(err)
+	}
+	return found
+}
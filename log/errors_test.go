@@ -0,0 +1,127 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func encodeErrForTest(t *testing.T, fields ...zap.Field) map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := zap.New(zapcore.NewCore(newEncoder(EncodingJSON), zapcore.AddSync(&buf), zapcore.DebugLevel))
+	logger.Error("failed", fields...)
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("failed to decode: %v (%s)", err, buf.String())
+	}
+	return entry
+}
+
+type codedError struct{ code int }
+
+func (e codedError) Error() string { return fmt.Sprintf("code %d", e.code) }
+
+func TestErrRecordsUnwrapChain(t *testing.T) {
+	err := fmt.Errorf("load user: %w", fmt.Errorf("query: %w", codedError{code: 7}))
+	entry := encodeErrForTest(t, Err(err))
+
+	if entry["error"] != "load user: query: code 7" {
+		t.Fatalf("unexpected error: %#v", entry["error"])
+	}
+	chain := entry["errorChain"].([]any)
+	if len(chain) != 3 {
+		t.Fatalf("unexpected chain: %#v", chain)
+	}
+	last := chain[2].(map[string]any)
+	if last["message"] != "code 7" || last["type"] != "log.codedError" {
+		t.Fatalf("unexpected root cause: %#v", last)
+	}
+	if _, ok := entry["errorStack"]; ok {
+		t.Fatal("errorStack should be omitted without WithStack")
+	}
+}
+
+func TestErrExpandsJoinedErrors(t *testing.T) {
+	err := fmt.Errorf("cleanup: %w", errors.Join(errors.New("close db"), fmt.Errorf("flush: %w", errors.New("disk full"))))
+	entry := encodeErrForTest(t, Err(err))
+
+	chain := entry["errorChain"].([]any)
+	if len(chain) != 2 {
+		t.Fatalf("unexpected chain: %#v", chain)
+	}
+	causes := chain[1].(map[string]any)["causes"].([]any)
+	if len(causes) != 2 {
+		t.Fatalf("unexpected causes: %#v", causes)
+	}
+	second := causes[1].([]any)
+	if len(second) != 2 || second[1].(map[string]any)["message"] != "disk full" {
+		t.Fatalf("unexpected joined chain: %#v", second)
+	}
+}
+
+func TestErrIncludesCapturedStack(t *testing.T) {
+	err := fmt.Errorf("handler: %w", WithStack(errors.New("boom")))
+	entry := encodeErrForTest(t, Err(err))
+
+	stack, _ := entry["errorStack"].(string)
+	if !strings.Contains(stack, "TestErrIncludesCapturedStack") {
+		t.Fatalf("stack should point to the caller of WithStack: %q", stack)
+	}
+	if chain := entry["errorChain"].([]any); len(chain) != 2 {
+		t.Fatalf("WithStack should not add a chain entry: %#v", chain)
+	}
+	if WithStack(nil) != nil {
+		t.Fatal("WithStack(nil) should be nil")
+	}
+}
+
+func TestErrExtractsGRPCStatus(t *testing.T) {
+	st, err := status.New(codes.NotFound, "user not found").WithDetails(wrapperspb.String("USER_MISSING"))
+	if err != nil {
+		t.Fatalf("WithDetails returned error: %v", err)
+	}
+	entry := encodeErrForTest(t, NamedErr("cause", fmt.Errorf("get user: %w", st.Err())))
+
+	if entry["grpcCode"] != "NotFound" || entry["grpcMessage"] != "user not found" {
+		t.Fatalf("unexpected grpc fields: %v", entry)
+	}
+	details := entry["grpcDetails"].([]any)
+	if len(details) != 1 || details[0] != "USER_MISSING" {
+		t.Fatalf("unexpected details: %#v", details)
+	}
+	if entry["cause"] != "get user: rpc error: code = NotFound desc = user not found" {
+		t.Fatalf("unexpected error message: %#v", entry["cause"])
+	}
+}
+
+func TestErrNilIsSkipped(t *testing.T) {
+	entry := encodeErrForTest(t, Err(nil))
+	if _, ok := entry["error"]; ok {
+		t.Fatalf("nil error should be skipped: %v", entry)
+	}
+}
+
+func TestErrorwUsesErrFields(t *testing.T) {
+	logs := installLevelCoreForTest(t, zapcore.DebugLevel)
+
+	Errorw(t.Context(), "failed", "cause", errors.Join(errors.New("a"), errors.New("b")))
+
+	fields := logs.All()[0].ContextMap()
+	if fields["cause"] != "a\nb" {
+		t.Fatalf("unexpected cause: %#v", fields["cause"])
+	}
+	if _, ok := fields["causeChain"]; !ok {
+		t.Fatalf("causeChain should be recorded: %v", fields)
+	}
+}
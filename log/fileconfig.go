@@ -0,0 +1,329 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"go.uber.org/zap/zapcore"
+)
+
+// Environment variables read by ConfigFromEnv. They take precedence over
+// the file named by ConfigEnv.
+const (
+	// ConfigEnv names a YAML or JSON file loaded with ConfigFromFile.
+	ConfigEnv = "LOG_CONFIG"
+	// LevelEnv is Config.Level ("debug", "info", ...).
+	LevelEnv = "LOG_LEVEL"
+	// FormatEnv is Config.Encoding ("json", "console", "logfmt", "dev").
+	FormatEnv = "LOG_FORMAT"
+	// FileEnv is Config.FilePath.
+	FileEnv = "LOG_FILE"
+	// StdoutEnv is Config.Stdout ("true" or "false").
+	StdoutEnv = "LOG_STDOUT"
+	// RotateEnv is RotationConfig.Interval ("none", "daily" or "hourly").
+	RotateEnv = "LOG_ROTATE"
+	// MaxSizeEnv, MaxAgeEnv, MaxBackupsEnv and MaxTotalSizeEnv are the
+	// RotationConfig limits with the same units.
+	MaxSizeEnv      = "LOG_MAX_SIZE"
+	MaxAgeEnv       = "LOG_MAX_AGE"
+	MaxBackupsEnv   = "LOG_MAX_BACKUPS"
+	MaxTotalSizeEnv = "LOG_MAX_TOTAL_SIZE"
+	// CompressEnv is RotationConfig.Compress ("true" or "false").
+	CompressEnv = "LOG_COMPRESS"
+)
+
+// fileConfig is the schema of the files read by ConfigFromFile:
+//
+//	level: info
+//	format: json
+//	file: /var/log/app/app.log
+//	namedLevels: {grpc: warn}
+//	rotation: {interval: daily, maxSize: 100, maxAge: 7}
+//	sampling:
+//	  initial: 100
+//	  thereafter: 100
+//	  levels: {debug: {initial: 10, thereafter: 0}}
+//	redaction:
+//	  defaults: true
+//	  rules:
+//	    - keys: [phone]
+//	      strategy: partial
+//	    - pattern: "ord-[0-9]+"
+//	      strategy: hash
+type fileConfig struct {
+	Level       zapcore.Level            `json:"level"`
+	Format      string                   `json:"format"`
+	Development bool                     `json:"development"`
+	NamedLevels map[string]zapcore.Level `json:"namedLevels"`
+
+	Service string `json:"service"`
+	Version string `json:"version"`
+
+	Stdout   *bool              `json:"stdout"`
+	Stderr   bool               `json:"stderr"`
+	File     string             `json:"file"`
+	Rotation fileRotationConfig `json:"rotation"`
+
+	Sampling  *fileSamplingConfig  `json:"sampling"`
+	Redaction *fileRedactionConfig `json:"redaction"`
+}
+
+type fileRotationConfig struct {
+	Interval     string `json:"interval"`
+	MaxSize      int    `json:"maxSize"`
+	MaxAge       int    `json:"maxAge"`
+	MaxBackups   int    `json:"maxBackups"`
+	MaxTotalSize int    `json:"maxTotalSize"`
+	Compress     *bool  `json:"compress"`
+	LocalTime    *bool  `json:"localTime"`
+}
+
+type fileSamplingConfig struct {
+	Tick           fileDuration            `json:"tick"`
+	Initial        int                     `json:"initial"`
+	Thereafter     int                     `json:"thereafter"`
+	Levels         map[string]SamplingRule `json:"levels"`
+	Messages       map[string]SamplingRule `json:"messages"`
+	TraceRatio     float64                 `json:"traceRatio"`
+	ReportInterval fileDuration            `json:"reportInterval"`
+}
+
+type fileRedactionConfig struct {
+	// Defaults adds DefaultRedactRules before Rules.
+	Defaults bool             `json:"defaults"`
+	Rules    []fileRedactRule `json:"rules"`
+}
+
+type fileRedactRule struct {
+	Keys     []string `json:"keys"`
+	Pattern  string   `json:"pattern"`
+	Strategy string   `json:"strategy"`
+}
+
+// fileDuration reads a duration such as "1s" or "5m".
+type fileDuration time.Duration
+
+func (d *fileDuration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = fileDuration(v)
+	return nil
+}
+
+// ConfigFromFile reads a Config from a YAML (.yaml, .yml) or JSON (.json)
+// file. Unknown keys are an error. A Logger created from the result reloads
+// the level, named levels, sampling and redaction from the file when it
+// changes and on SIGHUP; see Logger.Reload.
+func ConfigFromFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("log: %w", err)
+	}
+
+	var fc fileConfig
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&fc)
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalWithOptions(data, &fc, yaml.Strict())
+	default:
+		return Config{}, fmt.Errorf("log: unknown config file extension %q", ext)
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("log: invalid config file %s: %w", path, err)
+	}
+
+	cfg, err := fc.config()
+	if err != nil {
+		return Config{}, fmt.Errorf("log: invalid config file %s: %w", path, err)
+	}
+	cfg.configFile = path
+	return cfg, nil
+}
+
+// ConfigFromEnv reads a Config from the environment variables above. If
+// ConfigEnv is set, the file is read first and the other variables override
+// it; the Logger then also reloads from the file as with ConfigFromFile, and
+// the variables keep their precedence on every reload.
+func ConfigFromEnv() (Config, error) {
+	var cfg Config
+	if path := os.Getenv(ConfigEnv); path != "" {
+		var err error
+		if cfg, err = ConfigFromFile(path); err != nil {
+			return Config{}, err
+		}
+		cfg.fromEnv = true
+	}
+
+	if v, ok := os.LookupEnv(LevelEnv); ok {
+		if err := cfg.Level.UnmarshalText([]byte(v)); err != nil {
+			return Config{}, fmt.Errorf("log: invalid %s %q", LevelEnv, v)
+		}
+	}
+	if v, ok := os.LookupEnv(FormatEnv); ok {
+		cfg.Encoding = v
+	}
+	if v, ok := os.LookupEnv(FileEnv); ok {
+		cfg.FilePath = v
+	}
+	if v, ok := os.LookupEnv(StdoutEnv); ok {
+		stdout, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("log: invalid %s %q", StdoutEnv, v)
+		}
+		cfg.Stdout = &stdout
+	}
+	if v, ok := os.LookupEnv(RotateEnv); ok {
+		interval, err := parseRotationInterval(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("log: invalid %s %q", RotateEnv, v)
+		}
+		cfg.Rotation.Interval = interval
+	}
+	for name, limit := range map[string]*int{
+		MaxSizeEnv:      &cfg.Rotation.MaxSize,
+		MaxAgeEnv:       &cfg.Rotation.MaxAge,
+		MaxBackupsEnv:   &cfg.Rotation.MaxBackups,
+		MaxTotalSizeEnv: &cfg.Rotation.MaxTotalSize,
+	} {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("log: invalid %s %q", name, v)
+		}
+		*limit = n
+	}
+	if v, ok := os.LookupEnv(CompressEnv); ok {
+		compress, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("log: invalid %s %q", CompressEnv, v)
+		}
+		cfg.Rotation.Compress = &compress
+	}
+	return cfg, nil
+}
+
+func (fc *fileConfig) config() (Config, error) {
+	cfg := Config{
+		Level:       fc.Level,
+		Encoding:    fc.Format,
+		Development: fc.Development,
+		NamedLevels: fc.NamedLevels,
+		ServiceName: fc.Service,
+		Version:     fc.Version,
+		Stdout:      fc.Stdout,
+		Stderr:      fc.Stderr,
+		FilePath:    fc.File,
+		Rotation: RotationConfig{
+			MaxSize:      fc.Rotation.MaxSize,
+			MaxAge:       fc.Rotation.MaxAge,
+			MaxBackups:   fc.Rotation.MaxBackups,
+			MaxTotalSize: fc.Rotation.MaxTotalSize,
+			Compress:     fc.Rotation.Compress,
+			LocalTime:    fc.Rotation.LocalTime,
+		},
+	}
+
+	var err error
+	if cfg.Rotation.Interval, err = parseRotationInterval(fc.Rotation.Interval); err != nil {
+		return Config{}, err
+	}
+	if fc.Sampling != nil {
+		if cfg.Sampling, err = fc.Sampling.config(); err != nil {
+			return Config{}, err
+		}
+	}
+	if fc.Redaction != nil {
+		if cfg.Redaction, err = fc.Redaction.rules(); err != nil {
+			return Config{}, err
+		}
+	}
+	return cfg, nil
+}
+
+func (fs *fileSamplingConfig) config() (*SamplingConfig, error) {
+	cfg := &SamplingConfig{
+		Tick:           time.Duration(fs.Tick),
+		Initial:        fs.Initial,
+		Thereafter:     fs.Thereafter,
+		Messages:       fs.Messages,
+		TraceRatio:     fs.TraceRatio,
+		ReportInterval: time.Duration(fs.ReportInterval),
+	}
+	if len(fs.Levels) > 0 {
+		cfg.Levels = make(map[zapcore.Level]SamplingRule, len(fs.Levels))
+		for name, rule := range fs.Levels {
+			var lvl zapcore.Level
+			if err := lvl.UnmarshalText([]byte(name)); err != nil {
+				return nil, fmt.Errorf("unknown sampling level %q", name)
+			}
+			cfg.Levels[lvl] = rule
+		}
+	}
+	return cfg, nil
+}
+
+func (fr *fileRedactionConfig) rules() ([]RedactRule, error) {
+	var rules []RedactRule
+	if fr.Defaults {
+		rules = DefaultRedactRules()
+	}
+	for i, r := range fr.Rules {
+		if len(r.Keys) == 0 && r.Pattern == "" {
+			return nil, fmt.Errorf("redaction rule %d has neither keys nor a pattern", i)
+		}
+		rule := RedactRule{Keys: r.Keys}
+		var err error
+		if rule.Strategy, err = parseMaskStrategy(r.Strategy); err != nil {
+			return nil, fmt.Errorf("redaction rule %d: %w", i, err)
+		}
+		if r.Pattern != "" {
+			if rule.Pattern, err = regexp.Compile(r.Pattern); err != nil {
+				return nil, fmt.Errorf("redaction rule %d: %w", i, err)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func parseRotationInterval(s string) (RotationInterval, error) {
+	switch strings.ToLower(s) {
+	case "", "none":
+		return RotateNone, nil
+	case "daily":
+		return RotateDaily, nil
+	case "hourly":
+		return RotateHourly, nil
+	default:
+		return RotateNone, fmt.Errorf("unknown rotation interval %q", s)
+	}
+}
+
+func parseMaskStrategy(s string) (MaskStrategy, error) {
+	switch strings.ToLower(s) {
+	case "", "full":
+		return MaskFull, nil
+	case "partial":
+		return MaskPartial, nil
+	case "hash":
+		return MaskHash, nil
+	default:
+		return MaskFull, fmt.Errorf("unknown mask strategy %q", s)
+	}
+}
@@ -0,0 +1,149 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile returned error: %v", err)
+	}
+	return path
+}
+
+func TestConfigFromFileReadsYAML(t *testing.T) {
+	path := writeConfigFile(t, "log.yaml", `
+level: warn
+format: logfmt
+service: order
+file: /var/log/order/app.log
+namedLevels:
+  grpc: error
+rotation:
+  interval: daily
+  maxSize: 10
+  compress: false
+sampling:
+  tick: 2s
+  initial: 5
+  thereafter: 10
+  levels:
+    debug: {initial: 1, thereafter: 0}
+redaction:
+  defaults: true
+  rules:
+    - keys: [phone]
+      strategy: partial
+    - pattern: "ord-[0-9]+"
+      strategy: hash
+`)
+	cfg, err := ConfigFromFile(path)
+	if err != nil {
+		t.Fatalf("ConfigFromFile returned error: %v", err)
+	}
+
+	if cfg.Level != zapcore.WarnLevel || cfg.Encoding != EncodingLogfmt || cfg.ServiceName != "order" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if cfg.FilePath != "/var/log/order/app.log" || cfg.NamedLevels["grpc"] != zapcore.ErrorLevel {
+		t.Fatalf("unexpected file or named levels: %+v", cfg)
+	}
+	if cfg.Rotation.Interval != RotateDaily || cfg.Rotation.MaxSize != 10 || *cfg.Rotation.Compress {
+		t.Fatalf("unexpected rotation: %+v", cfg.Rotation)
+	}
+	s := cfg.Sampling
+	if s == nil || s.Tick != 2*time.Second || s.Initial != 5 || s.Thereafter != 10 || s.Levels[zapcore.DebugLevel] != (SamplingRule{Initial: 1}) {
+		t.Fatalf("unexpected sampling: %+v", s)
+	}
+	if n := len(cfg.Redaction); n != len(DefaultRedactRules())+2 {
+		t.Fatalf("unexpected number of redaction rules: %d", n)
+	}
+	if last := cfg.Redaction[len(cfg.Redaction)-1]; last.Strategy != MaskHash || !last.Pattern.MatchString("ord-42") {
+		t.Fatalf("unexpected pattern rule: %+v", last)
+	}
+	if cfg.configFile != path {
+		t.Fatalf("config file should be recorded, got %q", cfg.configFile)
+	}
+}
+
+func TestConfigFromFileReadsJSON(t *testing.T) {
+	path := writeConfigFile(t, "log.json", `{"level":"debug","stdout":false,"sampling":{"traceRatio":0.5}}`)
+	cfg, err := ConfigFromFile(path)
+	if err != nil {
+		t.Fatalf("ConfigFromFile returned error: %v", err)
+	}
+	if cfg.Level != zapcore.DebugLevel || *cfg.Stdout || cfg.Sampling.TraceRatio != 0.5 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestConfigFromFileRejectsInvalidFiles(t *testing.T) {
+	tests := map[string]string{
+		"log.yaml": "levle: info\n",
+		"log.json": `{"level":"loud"}`,
+		"bad.yaml": "rotation: {interval: weekly}\n",
+		"tick.yml": "sampling: {tick: soon}\n",
+		"mask.yml": "redaction: {rules: [{keys: [a], strategy: blur}]}\n",
+		"rule.yml": "redaction: {rules: [{strategy: full}]}\n",
+		"log.toml": "level = 'info'\n",
+	}
+	for name, content := range tests {
+		if _, err := ConfigFromFile(writeConfigFile(t, name, content)); err == nil {
+			t.Errorf("%s: expected error for %q", name, content)
+		}
+	}
+	if _, err := ConfigFromFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected error for a missing file")
+	}
+}
+
+func TestConfigFromEnvOverridesFile(t *testing.T) {
+	path := writeConfigFile(t, "log.yaml", "level: warn\nformat: console\nfile: /tmp/from-file.log\n")
+	t.Setenv(ConfigEnv, path)
+	t.Setenv(LevelEnv, "debug")
+	t.Setenv(FileEnv, "/tmp/from-env.log")
+	t.Setenv(StdoutEnv, "false")
+	t.Setenv(RotateEnv, "hourly")
+	t.Setenv(MaxSizeEnv, "50")
+	t.Setenv(MaxBackupsEnv, "3")
+	t.Setenv(CompressEnv, "false")
+
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("ConfigFromEnv returned error: %v", err)
+	}
+	if cfg.Level != zapcore.DebugLevel || cfg.Encoding != EncodingConsole || cfg.FilePath != "/tmp/from-env.log" || *cfg.Stdout {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	r := cfg.Rotation
+	if r.Interval != RotateHourly || r.MaxSize != 50 || r.MaxBackups != 3 || *r.Compress {
+		t.Fatalf("unexpected rotation: %+v", r)
+	}
+	if cfg.configFile != path || !cfg.fromEnv {
+		t.Fatal("the config file should be reloaded with the environment")
+	}
+}
+
+func TestConfigFromEnvRejectsInvalidValues(t *testing.T) {
+	for name, value := range map[string]string{
+		LevelEnv:    "loud",
+		StdoutEnv:   "maybe",
+		RotateEnv:   "weekly",
+		MaxAgeEnv:   "7d",
+		CompressEnv: "gzip",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			if _, err := ConfigFromEnv(); err == nil {
+				t.Fatalf("expected error for %s=%q", name, value)
+			}
+		})
+	}
+}
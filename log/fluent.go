@@ -0,0 +1,203 @@
+package log
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultFluentTag = "app"
+
+// FluentConfig ships entries to Fluentd or Fluent Bit with the Forward
+// protocol in message mode: [tag, time, record]. The record has the keys of
+// the JSON encoder except the time, which is sent as EventTime.
+type FluentConfig struct {
+	// Network defaults to "tcp".
+	NetworkConfig
+	// Tag defaults to Config.ServiceName, then "app".
+	Tag string
+}
+
+func checkFluentConfig(cfg *Config, f *FluentConfig) error {
+	if err := checkNetworkConfig(&f.NetworkConfig, "tcp", "tcp", "unix"); err != nil {
+		return err
+	}
+	f.Tag = cmp.Or(f.Tag, cfg.ServiceName, defaultFluentTag)
+	return nil
+}
+
+// fluentEncoder encodes an entry as one Forward message.
+type fluentEncoder struct {
+	recordEncoder
+	tag string
+}
+
+func newFluentEncoder(f FluentConfig) *fluentEncoder {
+	return &fluentEncoder{recordEncoder: newRecordEncoder(), tag: f.Tag}
+}
+
+func (e *fluentEncoder) Clone() zapcore.Encoder {
+	c := *e
+	c.recordEncoder = e.clone()
+	return &c
+}
+
+func (e *fluentEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	b := []byte{0x93} // fixarray of 3
+	b = appendMsgpack(b, e.tag)
+	b = appendEventTime(b, ent.Time)
+	b = appendMsgpack(b, e.entryRecord(ent, fields))
+
+	buf := networkBuffers.Get()
+	_, _ = buf.Write(b)
+	return buf, nil
+}
+
+// appendEventTime appends t as the EventTime extension (type 0).
+func appendEventTime(b []byte, t time.Time) []byte {
+	b = append(b, 0xd7, 0x00) // fixext 8
+	b = binary.BigEndian.AppendUint32(b, uint32(t.Unix()))
+	return binary.BigEndian.AppendUint32(b, uint32(t.Nanosecond()))
+}
+
+// appendMsgpack appends v, one of the values returned by plainValue, in
+// MessagePack. Map keys are sorted so that the output is stable.
+func appendMsgpack(b []byte, v any) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(b, 0xc0)
+	case bool:
+		if v {
+			return append(b, 0xc3)
+		}
+		return append(b, 0xc2)
+	case string:
+		return appendMsgpackString(b, v)
+	case []byte:
+		return appendMsgpackBinary(b, v)
+	case int:
+		return appendMsgpackInt(b, int64(v))
+	case int8:
+		return appendMsgpackInt(b, int64(v))
+	case int16:
+		return appendMsgpackInt(b, int64(v))
+	case int32:
+		return appendMsgpackInt(b, int64(v))
+	case int64:
+		return appendMsgpackInt(b, v)
+	case uint:
+		return appendMsgpackUint(b, uint64(v))
+	case uint8:
+		return appendMsgpackUint(b, uint64(v))
+	case uint16:
+		return appendMsgpackUint(b, uint64(v))
+	case uint32:
+		return appendMsgpackUint(b, uint64(v))
+	case uint64:
+		return appendMsgpackUint(b, v)
+	case uintptr:
+		return appendMsgpackUint(b, uint64(v))
+	case float32:
+		return appendMsgpack(b, float64(v))
+	case float64:
+		b = append(b, 0xcb)
+		return binary.BigEndian.AppendUint64(b, math.Float64bits(v))
+	case []any:
+		b = appendMsgpackLen(b, len(v), 0x90, 0xdc)
+		for _, e := range v {
+			b = appendMsgpack(b, e)
+		}
+		return b
+	case map[string]any:
+		b = appendMsgpackLen(b, len(v), 0x80, 0xde)
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			b = appendMsgpackString(b, k)
+			b = appendMsgpack(b, v[k])
+		}
+		return b
+	default:
+		return appendMsgpackString(b, fmt.Sprint(v))
+	}
+}
+
+func appendMsgpackInt(b []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return appendMsgpackUint(b, uint64(v))
+	case v >= -32:
+		return append(b, byte(v))
+	case v >= math.MinInt8:
+		return append(b, 0xd0, byte(v))
+	case v >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(v))
+	case v >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(v))
+	}
+}
+
+func appendMsgpackUint(b []byte, v uint64) []byte {
+	switch {
+	case v <= 0x7f:
+		return append(b, byte(v))
+	case v <= math.MaxUint8:
+		return append(b, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xcf), v)
+	}
+}
+
+func appendMsgpackString(b []byte, s string) []byte {
+	switch n := len(s); {
+	case n <= 31:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, s...)
+}
+
+func appendMsgpackBinary(b []byte, p []byte) []byte {
+	switch n := len(p); {
+	case n <= math.MaxUint8:
+		b = append(b, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xc5), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xc6), uint32(n))
+	}
+	return append(b, p...)
+}
+
+// appendMsgpackLen appends the header of an array or a map: fix is the
+// fixarray or fixmap prefix, wide the 16-bit one followed by the 32-bit one.
+func appendMsgpackLen(b []byte, n int, fix, wide byte) []byte {
+	switch {
+	case n <= 15:
+		return append(b, fix|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, wide), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, wide+1), uint32(n))
+	}
+}
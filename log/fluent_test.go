@@ -0,0 +1,174 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestFluentSinkOverUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fluent.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("unix sockets are not available: %v", err)
+	}
+	defer ln.Close()
+
+	fluent := &FluentConfig{NetworkConfig: testNetworkConfig(t, "unix", path), Tag: "order.api"}
+	l := newNetworkSinkLogger(t, SinkConfig{Fluent: fluent})
+	conn := acceptWithin(t, ln)
+	before := time.Now()
+	l.Zap().Info("order created", zap.String("orderId", "o-1"), zap.Int("items", 3), zap.Bool("paid", true))
+
+	msg, ok := decodeMsgpack(t, bufio.NewReader(conn)).([]any)
+	if !ok || len(msg) != 3 {
+		t.Fatalf("expected [tag, time, record], got %#v", msg)
+	}
+	if msg[0] != "order.api" {
+		t.Fatalf("unexpected tag: %#v", msg[0])
+	}
+	if ts, ok := msg[1].(time.Time); !ok || ts.Before(before.Truncate(time.Second)) {
+		t.Fatalf("unexpected event time: %#v", msg[1])
+	}
+	record := msg[2].(map[string]any)
+	if record["msg"] != "order created" || record["level"] != "info" || record["orderId"] != "o-1" || record["items"] != int64(3) || record["paid"] != true {
+		t.Fatalf("unexpected record: %#v", record)
+	}
+}
+
+func TestAppendMsgpackSizes(t *testing.T) {
+	values := []any{
+		int64(-1), int64(-100), int64(-1000), int64(-100000), int64(math.MinInt64),
+		uint64(200), uint64(60000), uint64(1 << 20), uint64(math.MaxUint64),
+		1.5, nil, false, []byte{1, 2},
+		string(make([]byte, 40)), string(make([]byte, 300)),
+		make([]any, 20), map[string]any{"a": []any{"b"}},
+	}
+	for _, v := range values {
+		data := appendMsgpack(nil, v)
+		got := decodeMsgpack(t, bufio.NewReader(bytes.NewReader(data)))
+		if fmt.Sprint(got) != fmt.Sprint(normalizeMsgpack(v)) {
+			t.Fatalf("round trip of %#v returned %#v", v, got)
+		}
+	}
+}
+
+// normalizeMsgpack returns v as decodeMsgpack returns it.
+func normalizeMsgpack(v any) any {
+	switch v := v.(type) {
+	case uint64:
+		if v > math.MaxInt64 {
+			return v
+		}
+		return int64(v)
+	default:
+		return v
+	}
+}
+
+// decodeMsgpack decodes the subset of MessagePack written by appendMsgpack.
+func decodeMsgpack(t *testing.T, r *bufio.Reader) any {
+	t.Helper()
+	read := func(n int) []byte {
+		b := make([]byte, n)
+		if _, err := io.ReadFull(r, b); err != nil {
+			t.Fatalf("failed to read msgpack: %v", err)
+		}
+		return b
+	}
+	length := func(n int) int {
+		b := read(n)
+		switch n {
+		case 1:
+			return int(b[0])
+		case 2:
+			return int(binary.BigEndian.Uint16(b))
+		default:
+			return int(binary.BigEndian.Uint32(b))
+		}
+	}
+	array := func(n int) []any {
+		out := make([]any, n)
+		for i := range out {
+			out[i] = decodeMsgpack(t, r)
+		}
+		return out
+	}
+	object := func(n int) map[string]any {
+		out := make(map[string]any, n)
+		for range n {
+			key, _ := decodeMsgpack(t, r).(string)
+			out[key] = decodeMsgpack(t, r)
+		}
+		return out
+	}
+
+	b := read(1)[0]
+	switch {
+	case b <= 0x7f:
+		return int64(b)
+	case b >= 0xe0:
+		return int64(int8(b))
+	case b&0xf0 == 0x80:
+		return object(int(b & 0x0f))
+	case b&0xf0 == 0x90:
+		return array(int(b & 0x0f))
+	case b&0xe0 == 0xa0:
+		return string(read(int(b & 0x1f)))
+	}
+	switch b {
+	case 0xc0:
+		return nil
+	case 0xc2:
+		return false
+	case 0xc3:
+		return true
+	case 0xc4, 0xc5, 0xc6:
+		return read(length(1 << (b - 0xc4)))
+	case 0xcb:
+		return math.Float64frombits(binary.BigEndian.Uint64(read(8)))
+	case 0xcc:
+		return int64(read(1)[0])
+	case 0xcd:
+		return int64(binary.BigEndian.Uint16(read(2)))
+	case 0xce:
+		return int64(binary.BigEndian.Uint32(read(4)))
+	case 0xcf:
+		v := binary.BigEndian.Uint64(read(8))
+		if v > math.MaxInt64 {
+			return v
+		}
+		return int64(v)
+	case 0xd0:
+		return int64(int8(read(1)[0]))
+	case 0xd1:
+		return int64(int16(binary.BigEndian.Uint16(read(2))))
+	case 0xd2:
+		return int64(int32(binary.BigEndian.Uint32(read(4))))
+	case 0xd3:
+		return int64(binary.BigEndian.Uint64(read(8)))
+	case 0xd7:
+		if ext := read(1)[0]; ext != 0 {
+			t.Fatalf("unexpected extension type %d", ext)
+		}
+		b := read(8)
+		return time.Unix(int64(binary.BigEndian.Uint32(b)), int64(binary.BigEndian.Uint32(b[4:])))
+	case 0xd9, 0xda, 0xdb:
+		return string(read(length(1 << (b - 0xd9))))
+	case 0xdc, 0xdd:
+		return array(length(2 << (b - 0xdc)))
+	case 0xde, 0xdf:
+		return object(length(2 << (b - 0xde)))
+	}
+	t.Fatalf("unexpected msgpack type 0x%x", b)
+	return nil
+}
@@ -0,0 +1,148 @@
+package log
+
+import (
+	"cmp"
+	"encoding/binary"
+	"encoding/json"
+	"math/rand/v2"
+	"strings"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	gelfVersion = "1.1"
+
+	// gelfChunkSize keeps a chunk with its 12-byte header within the 8192
+	// bytes Graylog accepts per datagram.
+	gelfChunkSize = 8192 - 12
+	gelfMaxChunks = 128
+)
+
+var gelfChunkMagic = []byte{0x1e, 0x0f}
+
+// GELFConfig ships entries as GELF 1.1 messages. Fields become additional
+// fields ("_traceId", ...); objects and arrays are sent as JSON strings.
+// Messages over udp larger than one datagram are chunked; tcp messages are
+// terminated by a null byte.
+type GELFConfig struct {
+	// Network defaults to "udp".
+	NetworkConfig
+	// Host defaults to Config.Host, then os.Hostname.
+	Host string
+}
+
+func checkGELFConfig(cfg *Config, g *GELFConfig) error {
+	if err := checkNetworkConfig(&g.NetworkConfig, "udp", "udp", "tcp"); err != nil {
+		return err
+	}
+	g.Host = cmp.Or(g.Host, cfg.Host, hostname())
+	return nil
+}
+
+// gelfEncoder encodes an entry as one GELF message.
+type gelfEncoder struct {
+	recordEncoder
+	host   string
+	stream bool
+}
+
+func newGELFEncoder(g GELFConfig) *gelfEncoder {
+	return &gelfEncoder{recordEncoder: newRecordEncoder(), host: g.Host, stream: g.stream()}
+}
+
+func (e *gelfEncoder) Clone() zapcore.Encoder {
+	c := *e
+	c.recordEncoder = e.clone()
+	return &c
+}
+
+func (e *gelfEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	msg := map[string]any{
+		"version":       gelfVersion,
+		"host":          e.host,
+		"short_message": ent.Message,
+		"timestamp":     float64(ent.Time.UnixMilli()) / 1000,
+		"level":         syslogSeverity(ent.Level),
+	}
+	if ent.Stack != "" {
+		msg["full_message"] = ent.Stack
+	}
+	if ent.LoggerName != "" {
+		msg["_logger"] = ent.LoggerName
+	}
+	if ent.Caller.Defined {
+		msg["_caller"] = ent.Caller.TrimmedPath()
+	}
+	for k, v := range e.record(fields) {
+		msg[gelfFieldName(k)] = gelfValue(v)
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	buf := networkBuffers.Get()
+	_, _ = buf.Write(data)
+	if e.stream {
+		buf.AppendByte(0)
+	}
+	return buf, nil
+}
+
+// gelfFieldName returns the additional field name of key: "_" followed by
+// word characters, dots and dashes. "_id" is reserved by GELF.
+func gelfFieldName(key string) string {
+	key = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+	if key == "id" {
+		key = "id_"
+	}
+	return "_" + key
+}
+
+// gelfValue keeps strings, numbers and booleans; GELF has no other types.
+func gelfValue(v any) any {
+	switch v.(type) {
+	case nil:
+		return ""
+	case string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr, float32, float64:
+		return v
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// gelfChunks splits a udp message that does not fit in one datagram into
+// GELF chunks. A message that needs more than 128 chunks is dropped.
+func gelfChunks(p []byte) [][]byte {
+	if len(p) <= gelfChunkSize {
+		return [][]byte{p}
+	}
+	count := (len(p) + gelfChunkSize - 1) / gelfChunkSize
+	if count > gelfMaxChunks {
+		return nil
+	}
+
+	id := rand.Uint64()
+	chunks := make([][]byte, 0, count)
+	for i := range count {
+		part := p[i*gelfChunkSize : min((i+1)*gelfChunkSize, len(p))]
+		chunk := make([]byte, 0, 12+len(part))
+		chunk = append(chunk, gelfChunkMagic...)
+		chunk = binary.BigEndian.AppendUint64(chunk, id)
+		chunk = append(chunk, byte(i), byte(count))
+		chunks = append(chunks, append(chunk, part...))
+	}
+	return chunks
+}
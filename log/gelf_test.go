@@ -0,0 +1,103 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestGELFSinkOverUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket returned error: %v", err)
+	}
+	defer pc.Close()
+
+	gelf := &GELFConfig{NetworkConfig: testNetworkConfig(t, "udp", pc.LocalAddr().String()), Host: "web-1"}
+	l := newNetworkSinkLogger(t, SinkConfig{GELF: gelf})
+	l.Zap().With(zap.String("id", "req-1")).Error("charge failed",
+		zap.String("traceId", "t-1"),
+		zap.Int("attempt", 2),
+		zap.Strings("tags", []string{"a", "b"}),
+		zap.String("bad key", "x"),
+	)
+
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 64*1024)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("ReadFrom returned error: %v", err)
+	}
+	var msg map[string]any
+	if err := json.Unmarshal(buf[:n], &msg); err != nil {
+		t.Fatalf("message should be JSON: %v", err)
+	}
+
+	want := map[string]any{
+		"version":       "1.1",
+		"host":          "web-1",
+		"short_message": "charge failed",
+		"level":         float64(3),
+		"_traceId":      "t-1",
+		"_attempt":      float64(2),
+		"_tags":         `["a","b"]`,
+		"_bad_key":      "x",
+		"_id_":          "req-1",
+	}
+	for k, v := range want {
+		if msg[k] != v {
+			t.Fatalf("%s: got %#v, want %#v (%v)", k, msg[k], v, msg)
+		}
+	}
+	if _, ok := msg["timestamp"].(float64); !ok {
+		t.Fatalf("timestamp should be a number: %v", msg)
+	}
+}
+
+func TestGELFEncoderTerminatesStreams(t *testing.T) {
+	enc := newGELFEncoder(GELFConfig{NetworkConfig: NetworkConfig{Network: "tcp"}, Host: "h"})
+	buf, err := enc.EncodeEntry(zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now(), Message: "m", Stack: "stack"}, nil)
+	if err != nil {
+		t.Fatalf("EncodeEntry returned error: %v", err)
+	}
+	data := buf.Bytes()
+	if data[len(data)-1] != 0 || !bytes.Contains(data, []byte(`"full_message":"stack"`)) {
+		t.Fatalf("unexpected message: %q", data)
+	}
+}
+
+func TestGELFChunks(t *testing.T) {
+	small := []byte("{}")
+	if chunks := gelfChunks(small); len(chunks) != 1 || !bytes.Equal(chunks[0], small) {
+		t.Fatalf("small message should not be chunked: %q", chunks)
+	}
+
+	large := []byte(strings.Repeat("x", gelfChunkSize*2+10))
+	chunks := gelfChunks(large)
+	if len(chunks) != 3 {
+		t.Fatalf("unexpected chunk count: %d", len(chunks))
+	}
+	var joined []byte
+	for i, chunk := range chunks {
+		if !bytes.Equal(chunk[:2], gelfChunkMagic) || !bytes.Equal(chunk[2:10], chunks[0][2:10]) {
+			t.Fatalf("chunk %d has a wrong header", i)
+		}
+		if chunk[10] != byte(i) || chunk[11] != 3 || len(chunk) > 8192 {
+			t.Fatalf("chunk %d has a wrong sequence or size", i)
+		}
+		joined = append(joined, chunk[12:]...)
+	}
+	if !bytes.Equal(joined, large) {
+		t.Fatal("chunks do not rebuild the message")
+	}
+
+	if chunks := gelfChunks(make([]byte, gelfChunkSize*gelfMaxChunks+1)); chunks != nil {
+		t.Fatal("message over 128 chunks should be dropped")
+	}
+}
@@ -0,0 +1,251 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// levelState holds the runtime level, the named overrides and the pending
+// auto-revert of a Logger.
+type levelState struct {
+	atomic zap.AtomicLevel
+	named  atomic.Pointer[namedLevelSet]
+
+	mu          sync.Mutex
+	namedMu     sync.Mutex
+	base        zapcore.Level
+	revertAfter time.Duration
+	revertTimer *time.Timer
+}
+
+func newLevelState(lvl zapcore.Level, revertAfter time.Duration, named map[string]zapcore.Level) *levelState {
+	s := &levelState{
+		atomic:      zap.NewAtomicLevelAt(lvl),
+		base:        lvl,
+		revertAfter: revertAfter,
+	}
+	s.resetNamed(named)
+	return s
+}
+
+// Level returns the current level of the global logger.
+func Level() zapcore.Level {
+	return L().Level()
+}
+
+// SetLevel changes the level of the global logger and cancels a pending
+// auto-revert.
+func SetLevel(lvl zapcore.Level) {
+	L().SetLevel(lvl)
+}
+
+// SetLevelFor changes the level of the global logger and reverts it to the
+// configured Config.Level after d. d <= 0 disables the revert.
+func SetLevelFor(lvl zapcore.Level, d time.Duration) {
+	L().SetLevelFor(lvl, d)
+}
+
+// Level returns the current level of l.
+func (l *Logger) Level() zapcore.Level {
+	return l.levels.atomic.Level()
+}
+
+// SetLevel changes the level of l and cancels a pending auto-revert.
+func (l *Logger) SetLevel(lvl zapcore.Level) {
+	l.levels.setLevelFor(lvl, 0)
+}
+
+// SetLevelFor changes the level of l and reverts it to the configured
+// Config.Level after d. d <= 0 disables the revert.
+func (l *Logger) SetLevelFor(lvl zapcore.Level, d time.Duration) {
+	l.levels.setLevelFor(lvl, d)
+}
+
+func (s *levelState) setLevelFor(lvl zapcore.Level, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopRevertLocked()
+	s.atomic.SetLevel(lvl)
+
+	if d <= 0 || lvl == s.base {
+		return
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(d, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		// 이미 다른 SetLevel 호출로 교체된 타이머면 무시한다.
+		if s.revertTimer != timer {
+			return
+		}
+		s.revertTimer = nil
+		s.atomic.SetLevel(s.base)
+	})
+	s.revertTimer = timer
+}
+
+// resetBase replaces the configured level, as a reload does, and cancels a
+// pending auto-revert.
+func (s *levelState) resetBase(lvl zapcore.Level) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopRevertLocked()
+	s.base = lvl
+	s.atomic.SetLevel(lvl)
+}
+
+func (s *levelState) stopRevert() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopRevertLocked()
+}
+
+func (s *levelState) stopRevertLocked() {
+	if s.revertTimer != nil {
+		s.revertTimer.Stop()
+		s.revertTimer = nil
+	}
+}
+
+func (s *levelState) baseLevel() zapcore.Level {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.base
+}
+
+func (s *levelState) defaultRevertAfter() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.revertAfter
+}
+
+type levelPayload struct {
+	Logger string `json:"logger,omitempty"`
+	Level  string `json:"level"`
+	Revert string `json:"revert,omitempty"`
+}
+
+type levelErrorPayload struct {
+	Error string `json:"error"`
+}
+
+// LevelHandler returns an http.Handler that reads (GET) and changes (PUT,
+// POST) the level of the global logger. It always acts on the logger that is
+// global at request time.
+//
+// The new level is read from a JSON body such as {"level":"debug","revert":"5m"}
+// or from the "level" and "revert" query parameters. When revert is omitted,
+// Config.LevelRevertAfter is used; "0" keeps the level until changed again.
+//
+// A "logger" query parameter or body field targets the override of a Named
+// logger instead; DELETE removes that override.
+func LevelHandler() http.Handler {
+	return levelHandler(L)
+}
+
+// LevelHandler is like the package-level LevelHandler but always acts on l.
+func (l *Logger) LevelHandler() http.Handler {
+	return levelHandler(func() *Logger { return l })
+}
+
+func levelHandler(target func() *Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l := target()
+		name := r.URL.Query().Get("logger")
+
+		switch r.Method {
+		case http.MethodGet:
+			if name != "" {
+				writeLevelJSON(w, http.StatusOK, levelPayload{Logger: name, Level: l.NamedLevel(name).String()})
+				return
+			}
+			writeLevelJSON(w, http.StatusOK, levelPayload{Level: l.Level().String()})
+		case http.MethodDelete:
+			if name == "" {
+				writeLevelJSON(w, http.StatusBadRequest, levelErrorPayload{Error: "logger is required"})
+				return
+			}
+			l.UnsetNamedLevel(name)
+			writeLevelJSON(w, http.StatusOK, levelPayload{Logger: name, Level: l.NamedLevel(name).String()})
+		case http.MethodPut, http.MethodPost:
+			req, err := decodeLevelRequest(r)
+			if err != nil {
+				writeLevelJSON(w, http.StatusBadRequest, levelErrorPayload{Error: err.Error()})
+				return
+			}
+
+			var lvl zapcore.Level
+			if err := lvl.UnmarshalText([]byte(req.Level)); err != nil {
+				writeLevelJSON(w, http.StatusBadRequest, levelErrorPayload{Error: err.Error()})
+				return
+			}
+
+			if req.Logger != "" {
+				l.SetNamedLevel(req.Logger, lvl)
+				writeLevelJSON(w, http.StatusOK, levelPayload{Logger: req.Logger, Level: lvl.String()})
+				return
+			}
+
+			revert := l.levels.defaultRevertAfter()
+			if req.Revert != "" {
+				revert, err = time.ParseDuration(req.Revert)
+				if err != nil {
+					writeLevelJSON(w, http.StatusBadRequest, levelErrorPayload{Error: fmt.Sprintf("invalid revert: %v", err)})
+					return
+				}
+			}
+
+			l.SetLevelFor(lvl, revert)
+
+			resp := levelPayload{Level: lvl.String()}
+			if revert > 0 && lvl != l.levels.baseLevel() {
+				resp.Revert = revert.String()
+			}
+			writeLevelJSON(w, http.StatusOK, resp)
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST, DELETE")
+			writeLevelJSON(w, http.StatusMethodNotAllowed, levelErrorPayload{Error: "method not allowed"})
+		}
+	})
+}
+
+func decodeLevelRequest(r *http.Request) (levelPayload, error) {
+	req := levelPayload{
+		Logger: r.URL.Query().Get("logger"),
+		Level:  r.URL.Query().Get("level"),
+		Revert: r.URL.Query().Get("revert"),
+	}
+	if req.Level != "" {
+		return req, nil
+	}
+
+	if r.Body == nil {
+		return req, errors.New("level is required")
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		return req, fmt.Errorf("invalid body: %w", err)
+	}
+	if strings.TrimSpace(req.Level) == "" {
+		return req, errors.New("level is required")
+	}
+	return req, nil
+}
+
+func writeLevelJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
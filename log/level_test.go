@@ -0,0 +1,155 @@
+package log
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSetLevelChangesLevel(t *testing.T) {
+	resetLevelForTest(t, zapcore.InfoLevel, 0)
+
+	SetLevel(zapcore.DebugLevel)
+	if got := Level(); got != zapcore.DebugLevel {
+		t.Fatalf("unexpected level: %v", got)
+	}
+	if !L().Zap().Core().Enabled(zapcore.DebugLevel) {
+		t.Fatal("debug should be enabled")
+	}
+}
+
+func TestSetLevelForRevertsToConfiguredLevel(t *testing.T) {
+	resetLevelForTest(t, zapcore.WarnLevel, 0)
+
+	SetLevelFor(zapcore.DebugLevel, 20*time.Millisecond)
+	if got := Level(); got != zapcore.DebugLevel {
+		t.Fatalf("unexpected level: %v", got)
+	}
+
+	waitForLevel(t, zapcore.WarnLevel)
+}
+
+func TestSetLevelCancelsPendingRevert(t *testing.T) {
+	resetLevelForTest(t, zapcore.InfoLevel, 0)
+
+	SetLevelFor(zapcore.DebugLevel, 20*time.Millisecond)
+	SetLevel(zapcore.ErrorLevel)
+	time.Sleep(50 * time.Millisecond)
+
+	if got := Level(); got != zapcore.ErrorLevel {
+		t.Fatalf("revert should be canceled, got: %v", got)
+	}
+}
+
+func TestLevelHandlerGetAndPut(t *testing.T) {
+	resetLevelForTest(t, zapcore.InfoLevel, 0)
+	handler := LevelHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/log/level", nil))
+	if got := decodeLevelPayload(t, rec).Level; got != "info" {
+		t.Fatalf("unexpected level: %q", got)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/log/level", strings.NewReader(`{"level":"debug","revert":"20ms"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", rec.Code)
+	}
+	body := decodeLevelPayload(t, rec)
+	if body.Level != "debug" || body.Revert != "20ms" {
+		t.Fatalf("unexpected response: %+v", body)
+	}
+	if got := Level(); got != zapcore.DebugLevel {
+		t.Fatalf("unexpected level: %v", got)
+	}
+
+	waitForLevel(t, zapcore.InfoLevel)
+}
+
+func TestLevelHandlerUsesQueryAndDefaultRevert(t *testing.T) {
+	resetLevelForTest(t, zapcore.InfoLevel, 20*time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	LevelHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/log/level?level=warn", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", rec.Code)
+	}
+	if got := Level(); got != zapcore.WarnLevel {
+		t.Fatalf("unexpected level: %v", got)
+	}
+
+	waitForLevel(t, zapcore.InfoLevel)
+}
+
+func TestLevelHandlerRejectsInvalidRequests(t *testing.T) {
+	resetLevelForTest(t, zapcore.InfoLevel, 0)
+	handler := LevelHandler()
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		status int
+	}{
+		{name: "unknown level", method: http.MethodPut, body: `{"level":"loud"}`, status: http.StatusBadRequest},
+		{name: "missing level", method: http.MethodPut, body: ``, status: http.StatusBadRequest},
+		{name: "invalid revert", method: http.MethodPut, body: `{"level":"debug","revert":"soon"}`, status: http.StatusBadRequest},
+		{name: "invalid method", method: http.MethodPatch, status: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, "/log/level", strings.NewReader(tt.body)))
+			if rec.Code != tt.status {
+				t.Fatalf("unexpected status: %d", rec.Code)
+			}
+		})
+	}
+
+	if got := Level(); got != zapcore.InfoLevel {
+		t.Fatalf("level should not change, got: %v", got)
+	}
+}
+
+// resetLevelForTest installs a global Logger at lvl that writes to an
+// observer, restoring the previous one on cleanup.
+func resetLevelForTest(t *testing.T, lvl zapcore.Level, revertAfter time.Duration) *observer.ObservedLogs {
+	t.Helper()
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := newLoggerFromCore(core, newLevelState(lvl, revertAfter, nil))
+	restore := ReplaceGlobals(l)
+	t.Cleanup(func() {
+		restore()
+		_ = l.Close()
+	})
+	return logs
+}
+
+func waitForLevel(t *testing.T, want zapcore.Level) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for Level() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("level did not become %v, got: %v", want, Level())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func decodeLevelPayload(t *testing.T, rec *httptest.ResponseRecorder) levelPayload {
+	t.Helper()
+
+	var body levelPayload
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return body
+}
@@ -0,0 +1,247 @@
+package log
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+var logfmtBuffers = buffer.NewPool()
+
+// logfmtEncoder writes entries as key=value pairs:
+//
+//	time=2024-05-01T10:00:00Z level=info caller=order.go:42 msg="order created" traceId=t-1 orderId=o-1
+//
+// Objects are flattened with dotted keys (user.id=1) and arrays are written
+// as [a,b]. Values with spaces, quotes or "=" are quoted.
+type logfmtEncoder struct {
+	// buf holds the fields added with With, each with a leading space.
+	buf    *buffer.Buffer
+	prefix string
+}
+
+func newLogfmtEncoder() *logfmtEncoder {
+	return &logfmtEncoder{buf: logfmtBuffers.Get()}
+}
+
+func (e *logfmtEncoder) Clone() zapcore.Encoder {
+	return e.clone()
+}
+
+func (e *logfmtEncoder) clone() *logfmtEncoder {
+	c := &logfmtEncoder{buf: logfmtBuffers.Get(), prefix: e.prefix}
+	_, _ = c.buf.Write(e.buf.Bytes())
+	return c
+}
+
+func (e *logfmtEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	line := logfmtBuffers.Get()
+	line.AppendString("time=")
+	line.AppendString(ent.Time.Format(time.RFC3339Nano))
+	line.AppendString(" level=")
+	line.AppendString(ent.Level.String())
+	if ent.LoggerName != "" {
+		line.AppendString(" logger=")
+		appendLogfmtValue(line, ent.LoggerName)
+	}
+	if ent.Caller.Defined {
+		line.AppendString(" caller=")
+		appendLogfmtValue(line, ent.Caller.TrimmedPath())
+	}
+	line.AppendString(" msg=")
+	appendLogfmtValue(line, ent.Message)
+
+	e.appendFields(line, fields)
+	if ent.Stack != "" {
+		line.AppendString(" stacktrace=")
+		appendLogfmtValue(line, ent.Stack)
+	}
+	line.AppendByte('\n')
+	return line, nil
+}
+
+// appendFields appends the With fields of e followed by fields to line.
+func (e *logfmtEncoder) appendFields(line *buffer.Buffer, fields []zapcore.Field) {
+	_, _ = line.Write(e.buf.Bytes())
+	enc := &logfmtEncoder{buf: line, prefix: e.prefix}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+}
+
+func (e *logfmtEncoder) addKey(key string) {
+	e.buf.AppendByte(' ')
+	appendLogfmtKey(e.buf, e.prefix+key)
+	e.buf.AppendByte('=')
+}
+
+func (e *logfmtEncoder) add(key, value string) {
+	e.addKey(key)
+	appendLogfmtValue(e.buf, value)
+}
+
+func (e *logfmtEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	value, err := logfmtArray(arr)
+	e.add(key, value)
+	return err
+}
+
+func (e *logfmtEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	prefix := e.prefix
+	e.prefix += key + "."
+	err := obj.MarshalLogObject(e)
+	e.prefix = prefix
+	return err
+}
+
+func (e *logfmtEncoder) AddBinary(key string, v []byte) {
+	e.add(key, base64.StdEncoding.EncodeToString(v))
+}
+func (e *logfmtEncoder) AddByteString(key string, v []byte) { e.add(key, string(v)) }
+func (e *logfmtEncoder) AddBool(key string, v bool)         { e.add(key, strconv.FormatBool(v)) }
+func (e *logfmtEncoder) AddComplex128(key string, v complex128) {
+	e.add(key, strconv.FormatComplex(v, 'g', -1, 128))
+}
+func (e *logfmtEncoder) AddComplex64(key string, v complex64) {
+	e.add(key, strconv.FormatComplex(complex128(v), 'g', -1, 64))
+}
+func (e *logfmtEncoder) AddDuration(key string, v time.Duration) { e.add(key, v.String()) }
+func (e *logfmtEncoder) AddFloat64(key string, v float64) {
+	e.add(key, strconv.FormatFloat(v, 'g', -1, 64))
+}
+func (e *logfmtEncoder) AddFloat32(key string, v float32) {
+	e.add(key, strconv.FormatFloat(float64(v), 'g', -1, 32))
+}
+func (e *logfmtEncoder) AddInt(key string, v int)        { e.AddInt64(key, int64(v)) }
+func (e *logfmtEncoder) AddInt64(key string, v int64)    { e.add(key, strconv.FormatInt(v, 10)) }
+func (e *logfmtEncoder) AddInt32(key string, v int32)    { e.AddInt64(key, int64(v)) }
+func (e *logfmtEncoder) AddInt16(key string, v int16)    { e.AddInt64(key, int64(v)) }
+func (e *logfmtEncoder) AddInt8(key string, v int8)      { e.AddInt64(key, int64(v)) }
+func (e *logfmtEncoder) AddString(key, v string)         { e.add(key, v) }
+func (e *logfmtEncoder) AddTime(key string, v time.Time) { e.add(key, v.Format(time.RFC3339Nano)) }
+func (e *logfmtEncoder) AddUint(key string, v uint)      { e.AddUint64(key, uint64(v)) }
+func (e *logfmtEncoder) AddUint64(key string, v uint64)  { e.add(key, strconv.FormatUint(v, 10)) }
+func (e *logfmtEncoder) AddUint32(key string, v uint32)  { e.AddUint64(key, uint64(v)) }
+func (e *logfmtEncoder) AddUint16(key string, v uint16)  { e.AddUint64(key, uint64(v)) }
+func (e *logfmtEncoder) AddUint8(key string, v uint8)    { e.AddUint64(key, uint64(v)) }
+func (e *logfmtEncoder) AddUintptr(key string, v uintptr) {
+	e.AddUint64(key, uint64(v))
+}
+
+func (e *logfmtEncoder) AddReflected(key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	e.add(key, string(data))
+	return nil
+}
+
+func (e *logfmtEncoder) OpenNamespace(key string) {
+	e.prefix += key + "."
+}
+
+// logfmtArray renders arr as [a,b,c]; objects in it are rendered as
+// {k=v k=v}.
+func logfmtArray(arr zapcore.ArrayMarshaler) (string, error) {
+	enc := &logfmtArrayEncoder{}
+	err := arr.MarshalLogArray(enc)
+	return "[" + strings.Join(enc.elems, ",") + "]", err
+}
+
+type logfmtArrayEncoder struct {
+	elems []string
+}
+
+func (a *logfmtArrayEncoder) append(v string) { a.elems = append(a.elems, v) }
+
+func (a *logfmtArrayEncoder) AppendArray(arr zapcore.ArrayMarshaler) error {
+	value, err := logfmtArray(arr)
+	a.append(value)
+	return err
+}
+
+func (a *logfmtArrayEncoder) AppendObject(obj zapcore.ObjectMarshaler) error {
+	enc := newLogfmtEncoder()
+	defer enc.buf.Free()
+	err := obj.MarshalLogObject(enc)
+	a.append("{" + strings.TrimPrefix(enc.buf.String(), " ") + "}")
+	return err
+}
+
+func (a *logfmtArrayEncoder) AppendReflected(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	a.append(string(data))
+	return nil
+}
+
+func (a *logfmtArrayEncoder) AppendBool(v bool)         { a.append(strconv.FormatBool(v)) }
+func (a *logfmtArrayEncoder) AppendByteString(v []byte) { a.append(string(v)) }
+func (a *logfmtArrayEncoder) AppendComplex128(v complex128) {
+	a.append(strconv.FormatComplex(v, 'g', -1, 128))
+}
+func (a *logfmtArrayEncoder) AppendComplex64(v complex64)    { a.AppendComplex128(complex128(v)) }
+func (a *logfmtArrayEncoder) AppendDuration(v time.Duration) { a.append(v.String()) }
+func (a *logfmtArrayEncoder) AppendFloat64(v float64)        { a.append(strconv.FormatFloat(v, 'g', -1, 64)) }
+func (a *logfmtArrayEncoder) AppendFloat32(v float32) {
+	a.append(strconv.FormatFloat(float64(v), 'g', -1, 32))
+}
+func (a *logfmtArrayEncoder) AppendInt(v int)         { a.AppendInt64(int64(v)) }
+func (a *logfmtArrayEncoder) AppendInt64(v int64)     { a.append(strconv.FormatInt(v, 10)) }
+func (a *logfmtArrayEncoder) AppendInt32(v int32)     { a.AppendInt64(int64(v)) }
+func (a *logfmtArrayEncoder) AppendInt16(v int16)     { a.AppendInt64(int64(v)) }
+func (a *logfmtArrayEncoder) AppendInt8(v int8)       { a.AppendInt64(int64(v)) }
+func (a *logfmtArrayEncoder) AppendString(v string)   { a.append(v) }
+func (a *logfmtArrayEncoder) AppendTime(v time.Time)  { a.append(v.Format(time.RFC3339Nano)) }
+func (a *logfmtArrayEncoder) AppendUint(v uint)       { a.AppendUint64(uint64(v)) }
+func (a *logfmtArrayEncoder) AppendUint64(v uint64)   { a.append(strconv.FormatUint(v, 10)) }
+func (a *logfmtArrayEncoder) AppendUint32(v uint32)   { a.AppendUint64(uint64(v)) }
+func (a *logfmtArrayEncoder) AppendUint16(v uint16)   { a.AppendUint64(uint64(v)) }
+func (a *logfmtArrayEncoder) AppendUint8(v uint8)     { a.AppendUint64(uint64(v)) }
+func (a *logfmtArrayEncoder) AppendUintptr(v uintptr) { a.AppendUint64(uint64(v)) }
+
+// appendLogfmtKey writes key with the characters that would break parsing
+// replaced by '_'.
+func appendLogfmtKey(buf *buffer.Buffer, key string) {
+	if key == "" {
+		key = "_"
+	}
+	buf.AppendString(strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError {
+			return '_'
+		}
+		return r
+	}, key))
+}
+
+// appendLogfmtValue writes v, quoted when it is empty or contains spaces,
+// quotes, '=' or non-printable characters.
+func appendLogfmtValue(buf *buffer.Buffer, v string) {
+	if needsLogfmtQuote(v) {
+		buf.AppendString(strconv.Quote(v))
+		return
+	}
+	buf.AppendString(v)
+}
+
+func needsLogfmtQuote(v string) bool {
+	if v == "" {
+		return true
+	}
+	for _, r := range v {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package log
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type testUser struct {
+	ID   int
+	Name string
+}
+
+func (u testUser) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddInt("id", u.ID)
+	enc.AddString("name", u.Name)
+	return nil
+}
+
+func encodeLine(t *testing.T, enc zapcore.Encoder, ent zapcore.Entry, fields ...zap.Field) string {
+	t.Helper()
+	buf, err := enc.EncodeEntry(ent, fields)
+	if err != nil {
+		t.Fatalf("EncodeEntry returned error: %v", err)
+	}
+	defer buf.Free()
+	return buf.String()
+}
+
+func TestLogfmtEncoderWritesKeyValuePairs(t *testing.T) {
+	enc := newLogfmtEncoder().Clone()
+	enc.AddString("service", "order api")
+
+	ent := zapcore.Entry{
+		Level:      zapcore.WarnLevel,
+		Time:       time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		LoggerName: "payment",
+		Message:    `charge "slow"`,
+		Caller:     zapcore.NewEntryCaller(0, "/src/app/order.go", 42, true),
+	}
+	got := encodeLine(t, enc, ent,
+		zap.String("traceId", "t-1"),
+		zap.Int("attempt", 2),
+		zap.Duration("elapsed", 1500*time.Millisecond),
+		zap.Object("user", testUser{ID: 7, Name: "kim"}),
+		zap.Strings("tags", []string{"a", "b"}),
+		zap.String("empty", ""),
+		zap.String("bad key", "x=y"),
+		zap.Error(errors.New("card declined")),
+	)
+
+	want := `time=2024-05-01T10:00:00Z level=warn logger=payment caller=app/order.go:42 msg="charge \"slow\"" ` +
+		`service="order api" traceId=t-1 attempt=2 elapsed=1.5s user.id=7 user.name=kim tags=[a,b] empty="" bad_key="x=y" error="card declined"` + "\n"
+	if got != want {
+		t.Fatalf("unexpected line:\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestLogfmtEncoderNamespacesAndClones(t *testing.T) {
+	base := newLogfmtEncoder()
+	ns := base.Clone()
+	ns.OpenNamespace("http")
+	ns.AddInt("status", 500)
+
+	ent := zapcore.Entry{Time: time.Unix(0, 0).UTC(), Message: "m", Stack: "main.main\n\tmain.go:1"}
+	got := encodeLine(t, ns, ent, zap.String("path", "/a"), zap.Objects("users", []testUser{{ID: 1, Name: "a"}}))
+	if !strings.Contains(got, ` http.status=500 http.path=/a http.users="[{id=1 name=a}]"`) {
+		t.Fatalf("namespace was not applied: %q", got)
+	}
+	if !strings.Contains(got, `stacktrace="main.main\n\tmain.go:1"`) {
+		t.Fatalf("stacktrace should be quoted on one line: %q", got)
+	}
+	if plain := encodeLine(t, base, ent); strings.Contains(plain, "http") {
+		t.Fatalf("Clone should not share fields with the original: %q", plain)
+	}
+}
+
+func TestNewWritesLogfmtFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l, err := New(Config{Stdout: new(false), FilePath: path, Encoding: EncodingLogfmt})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	l.Zap().Info("hello", zap.String("k", "v"))
+	_ = l.Close()
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	data := string(raw)
+	if !strings.HasPrefix(data, "time=") || !strings.Contains(data, " level=info ") || !strings.Contains(data, " msg=hello k=v\n") {
+		t.Fatalf("unexpected file content: %q", data)
+	}
+}
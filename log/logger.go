@@ -2,11 +2,15 @@ package log
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"syscall"
 
 	"go.uber.org/zap"
@@ -15,100 +19,471 @@ import (
 )
 
 var (
-	initOnce sync.Once
-	initErr  error
-	sigMu    sync.Mutex
-	sigCh    chan os.Signal
+	// initMu serializes Init and Close on the global logger.
+	initMu sync.Mutex
+	global atomic.Pointer[Logger]
+	// owned reports whether the global logger was created by Init, in which
+	// case the next Init or Close closes it.
+	owned     bool
+	nopLogger = newLoggerFromCore(zapcore.NewNopCore(), newLevelState(zapcore.InfoLevel, 0, nil))
 )
 
-// Init initializes the global logger. If logFilePath is non-empty, file
-// output with lumberjack rotation is added. Init is safe for concurrent use
-// but only the first call takes effect (sync.Once). If the first call fails,
-// the error is permanent — callers should panic or os.Exit on failure.
-func Init(logFilePath string) error {
-	initOnce.Do(func() {
-		atomicLevel := zap.NewAtomicLevelAt(zapcore.InfoLevel)
-
-		encoderConfig := zapcore.EncoderConfig{
-			TimeKey:        "time",
-			LevelKey:       "level",
-			NameKey:        "logger",
-			CallerKey:      "caller",
-			MessageKey:     "msg",
-			StacktraceKey:  "stacktrace",
-			FunctionKey:    zapcore.OmitKey,
-			LineEnding:     zapcore.DefaultLineEnding,
-			EncodeLevel:    zapcore.LowercaseLevelEncoder,
-			EncodeTime:     zapcore.RFC3339NanoTimeEncoder,
-			EncodeDuration: zapcore.SecondsDurationEncoder,
-			EncodeCaller:   zapcore.ShortCallerEncoder,
+// Logger owns a set of cores together with their level, named level
+// overrides, file rotation and signal handling. Loggers can be created and
+// closed any number of times; the package-level functions act on the one
+// installed by Init or ReplaceGlobals.
+type Logger struct {
+	// plain has no caller skip; wrapped adds the skip of the package-level
+	// helpers and is what zap.L() returns while l is global.
+	plain   *zap.Logger
+	wrapped *zap.Logger
+	levels  *levelState
+	files   []fileWriter
+	asyncs  []*asyncWriter
+	nets    []*netWriter
+	reload  *reloader
+	// closers stop background work such as the sampling report on Close.
+	closers []func()
+
+	mu     sync.Mutex
+	sigCh  chan os.Signal
+	closed bool
+}
+
+// New builds a Logger from cfg without touching the global logger.
+func New(cfg Config) (*Logger, error) {
+	if err := checkConfig(&cfg); err != nil {
+		return nil, err
+	}
+
+	// 레벨 필터링은 levelCore가 담당하므로 개별 core는 모든 레벨을 받는다.
+	allLevels := zapcore.DebugLevel
+
+	var cores []zapcore.Core
+	var asyncs []*asyncWriter
+	var files []fileWriter
+	var nets []*netWriter
+	newCore := func(enc zapcore.Encoder, ws zapcore.WriteSyncer, enab zapcore.LevelEnabler) zapcore.Core {
+		if cfg.Async != nil {
+			w := newAsyncWriter(ws, *cfg.Async)
+			asyncs = append(asyncs, w)
+			ws = w
 		}
+		return zapcore.NewCore(enc, ws, enab)
+	}
+	fail := func(err error) (*Logger, error) {
+		for _, w := range asyncs {
+			_ = w.Close()
+		}
+		for _, f := range files {
+			_ = f.Close()
+		}
+		for _, w := range nets {
+			_ = w.Close()
+		}
+		return nil, err
+	}
 
-		var cores []zapcore.Core
+	if *cfg.Stdout {
+		cores = append(cores, newCore(newEncoder(cfg.consoleEncoding()), zapcore.AddSync(os.Stdout), allLevels))
+	}
+	if cfg.Stderr {
+		cores = append(cores, newCore(newEncoder(cfg.consoleEncoding()), zapcore.AddSync(os.Stderr), allLevels))
+	}
+	if cfg.FilePath != "" {
+		fileLogger, err := newFileLogger(cfg)
+		if err != nil {
+			return fail(err)
+		}
+		files = append(files, fileLogger)
+		cores = append(cores, newCore(newEncoder(cfg.Encoding), zapcore.AddSync(fileLogger), allLevels))
+	}
 
-		consoleEncoder := zapcore.NewJSONEncoder(encoderConfig)
-		consoleCore := zapcore.NewCore(consoleEncoder, zapcore.AddSync(os.Stdout), atomicLevel)
-		cores = append(cores, consoleCore)
+	for _, sink := range cfg.Sinks {
+		// 네트워크 sink는 프레임 단위로 보내야 하므로 비동기 배치를 쓰지 않는다.
+		core, w, err := sink.networkCore(cfg)
+		if err != nil {
+			return fail(err)
+		}
+		if core != nil {
+			nets = append(nets, w)
+			cores = append(cores, core)
+			continue
+		}
 
-		if logFilePath != "" {
-			if err := os.MkdirAll(filepath.Dir(logFilePath), 0o750); err != nil {
-				initErr = err
-				return
-			}
-			fileLogger := &lumberjack.Logger{
-				Filename:  logFilePath,
-				MaxSize:   1024, // 1GB
-				MaxAge:    7,    // 7 days
-				Compress:  true,
-				LocalTime: true,
+		var ws zapcore.WriteSyncer
+		switch {
+		case sink.Stdout:
+			ws = zapcore.AddSync(os.Stdout)
+		case sink.Stderr:
+			ws = zapcore.AddSync(os.Stderr)
+		default:
+			fileLogger, err := newFileLogger(sink.fileConfig(cfg))
+			if err != nil {
+				return fail(err)
 			}
+			files = append(files, fileLogger)
+			ws = zapcore.AddSync(fileLogger)
+		}
+		cores = append(cores, newCore(newEncoder(sink.Encoding), ws, zap.LevelEnablerFunc(sink.enabled)))
+	}
+
+	levels := newLevelState(cfg.Level, cfg.LevelRevertAfter, cfg.NamedLevels)
+	sampling := newSampler(cfg.Sampling)
+	redaction := &redactRules{}
+	redaction.Store(newRedactor(cfg.Redaction))
+	var reload *reloader
+	if cfg.configFile != "" {
+		// 리로드로 샘플링을 켤 수 있도록 규칙 없는 sampler를 둔다.
+		if sampling == nil {
+			sampling = &sampler{counts: make(map[samplingKey]int)}
+		}
+		reload = newReloader(cfg, levels, sampling, redaction)
+	}
+	core := zapcore.NewTee(cores...)
+	if reload != nil || redaction.Load() != nil {
+		core = &redactCore{Core: core, rules: redaction}
+	}
+	// 샘플링에서 버려질 항목은 마스킹하지 않도록 redactCore 앞에 둔다.
+	core = newSamplerCore(core, sampling)
+	opts := []zap.Option{zap.Fields(cfg.staticFields()...)}
+	fatalHook := &closeOnFatal{}
+	if cfg.CloseOnExit {
+		opts = append(opts, zap.WithFatalHook(fatalHook))
+	}
+	l := newLoggerFromCore(core, levels, opts...)
+	fatalHook.l = l
+	l.files = files
+	l.asyncs = asyncs
+	l.nets = nets
+	l.reload = reload
+	if sampling != nil {
+		interval := defaultSamplingReportInterval
+		if cfg.Sampling != nil {
+			interval = cfg.Sampling.ReportInterval
+		}
+		if interval > 0 {
+			l.closers = append(l.closers, sampling.startReporter(l.plain.Named(samplingLoggerName), interval))
+		}
+	}
+	if reload != nil && cfg.ReloadInterval > 0 {
+		l.closers = append(l.closers, reload.watch(cfg.ReloadInterval))
+	}
+
+	var signals []os.Signal
+	if len(files) > 0 || reload != nil {
+		signals = append(signals, syscall.SIGHUP)
+	}
+	if cfg.LevelSignals {
+		signals = append(signals, levelSignals...)
+	}
+	var exitSignals []os.Signal
+	if cfg.CloseOnExit {
+		exitSignals = cfg.ExitSignals
+		signals = append(signals, exitSignals...)
+	}
+	if len(signals) > 0 {
+		l.startSignalListener(signals, exitSignals)
+	}
+
+	return l, nil
+}
+
+func newLoggerFromCore(core zapcore.Core, levels *levelState, opts ...zap.Option) *Logger {
+	plain := zap.New(newLevelCore(core, levels), append([]zap.Option{zap.AddCaller()}, opts...)...)
+	return &Logger{
+		plain:   plain,
+		wrapped: plain.WithOptions(zap.AddCallerSkip(1)),
+		levels:  levels,
+	}
+}
+
+// L returns the global Logger. Before Init it is a no-op Logger.
+func L() *Logger {
+	if l := global.Load(); l != nil {
+		return l
+	}
+	return nopLogger
+}
+
+// Zap returns the underlying zap logger of l.
+func (l *Logger) Zap() *zap.Logger {
+	return l.plain
+}
+
+// ReplaceGlobals installs l as the global logger (including zap.L()) and
+// returns a function that restores the previous one. The caller keeps
+// ownership of l and must Close it.
+func ReplaceGlobals(l *Logger) func() {
+	initMu.Lock()
+	defer initMu.Unlock()
+
+	prev, prevOwned := global.Load(), owned
+	restoreZap := replaceGlobalsLocked(l, false)
+	return func() {
+		initMu.Lock()
+		defer initMu.Unlock()
+
+		restoreZap()
+		global.Store(prev)
+		owned = prevOwned
+	}
+}
+
+func replaceGlobalsLocked(l *Logger, own bool) func() {
+	global.Store(l)
+	owned = own
+	return zap.ReplaceGlobals(l.wrapped)
+}
+
+// Init initializes the global logger. If logFilePath is non-empty, file
+// output with lumberjack rotation is added. Init is a thin wrapper around
+// InitWithConfig using the default Config.
+func Init(logFilePath string) error {
+	return InitWithConfig(Config{FilePath: logFilePath})
+}
+
+// InitWithConfig builds a Logger from cfg and installs it as the global
+// logger, closing the one created by a previous Init. It is safe for
+// concurrent use and may be called again after a failure or after Close;
+// on failure the current global logger is left untouched.
+func InitWithConfig(cfg Config) error {
+	l, err := New(cfg)
+	if err != nil {
+		return err
+	}
+
+	initMu.Lock()
+	defer initMu.Unlock()
+
+	prev, prevOwned := global.Load(), owned
+	replaceGlobalsLocked(l, true)
+	if cfg.SlogDefault {
+		// 특정 Logger에 묶지 않고 호출 시점의 전역 logger로 기록한다.
+		slog.SetDefault(slog.New(NewSlogHandler(nil)))
+	}
+	if prev != nil && prevOwned {
+		_ = prev.Close()
+	}
+	return nil
+}
 
-			sigCh = make(chan os.Signal, 1)
-			signal.Notify(sigCh, syscall.SIGHUP)
-			go func() {
-				for range sigCh {
-					if err := fileLogger.Rotate(); err != nil {
-						_, _ = fmt.Fprintf(os.Stderr, "log rotate failed: %v\n", err)
+// startSignalListener handles the exit signals (close and terminate), SIGHUP
+// (rotate the log files and reload the config file) and the level signals until Close is called.
+func (l *Logger) startSignalListener(signals, exitSignals []os.Signal) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sigCh = make(chan os.Signal, 1)
+	signal.Notify(l.sigCh, signals...)
+	go func(ch <-chan os.Signal) {
+		for sig := range ch {
+			switch {
+			case slices.Contains(exitSignals, sig):
+				l.exitOnSignal(sig)
+				return
+			case sig == syscall.SIGHUP:
+				if err := l.Rotate(); err != nil {
+					_, _ = fmt.Fprintf(os.Stderr, "log rotate failed: %v\n", err)
+				}
+				if l.reload != nil {
+					if err := l.Reload(); err != nil {
+						_, _ = fmt.Fprintf(os.Stderr, "log reload failed: %v\n", err)
 					}
 				}
-			}()
+			default:
+				l.levels.handleSignal(sig)
+			}
+		}
+	}(l.sigCh)
+}
 
-			fileEncoder := zapcore.NewJSONEncoder(encoderConfig)
-			fileCore := zapcore.NewCore(fileEncoder, zapcore.AddSync(fileLogger), atomicLevel)
-			cores = append(cores, fileCore)
+// Rotate rotates the log files of l, as SIGHUP does.
+func (l *Logger) Rotate() error {
+	var errs []error
+	for _, f := range l.files {
+		if err := f.Rotate(); err != nil {
+			errs = append(errs, err)
 		}
+	}
+	return errors.Join(errs...)
+}
 
-		core := zapcore.NewTee(cores...)
-		logger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
-		zap.ReplaceGlobals(logger)
-	})
+// AsyncDropped returns the number of entries dropped by the async writers of
+// l because their buffer was full or l was closed.
+func (l *Logger) AsyncDropped() uint64 {
+	var n uint64
+	for _, w := range l.asyncs {
+		n += w.Dropped()
+	}
+	return n
+}
 
-	return initErr
+// NetworkDropped returns the number of entries dropped by the network sinks
+// of l while their collector was down and their spool was missing or full.
+func (l *Logger) NetworkDropped() uint64 {
+	var n uint64
+	for _, w := range l.nets {
+		n += w.Dropped()
+	}
+	return n
+}
+
+// Sync flushes the cores of l.
+func (l *Logger) Sync() error {
+	return l.plain.Sync()
+}
+
+// Close stops the signal listener and background work, syncs l, drains the
+// async buffers and closes its files. It is safe
+// for concurrent use and calling it more than once is a no-op.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	if l.sigCh != nil {
+		signal.Stop(l.sigCh)
+		close(l.sigCh)
+		l.sigCh = nil
+	}
+	l.mu.Unlock()
+
+	l.levels.stopRevert()
+	for _, stop := range l.closers {
+		stop()
+	}
+
+	errs := []error{l.Sync()}
+	// 파일을 닫기 전에 비동기 버퍼를 모두 비운다.
+	for _, w := range l.asyncs {
+		errs = append(errs, w.Close())
+	}
+	for _, f := range l.files {
+		errs = append(errs, f.Close())
+	}
+	for _, w := range l.nets {
+		errs = append(errs, w.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func newEncoder(encoding string) zapcore.Encoder {
+	encoderConfig := zapcore.EncoderConfig{
+		TimeKey:        "time",
+		LevelKey:       "level",
+		NameKey:        "logger",
+		CallerKey:      "caller",
+		MessageKey:     "msg",
+		StacktraceKey:  "stacktrace",
+		FunctionKey:    zapcore.OmitKey,
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeTime:     zapcore.RFC3339NanoTimeEncoder,
+		EncodeDuration: zapcore.SecondsDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	}
+
+	switch encoding {
+	case EncodingConsole:
+		return zapcore.NewConsoleEncoder(encoderConfig)
+	case EncodingLogfmt:
+		return newLogfmtEncoder()
+	case EncodingDev:
+		return newDevEncoder()
+	default:
+		return zapcore.NewJSONEncoder(encoderConfig)
+	}
+}
+
+// newFileLogger creates the log directory and returns the writer of
+// cfg.FilePath: a rotatingFile when a time-based or total-size policy is set,
+// lumberjack otherwise.
+func newFileLogger(cfg Config) (fileWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(cfg.FilePath), cfg.DirMode); err != nil {
+		return nil, err
+	}
+	if cfg.Rotation.Interval != RotateNone || cfg.Rotation.MaxTotalSize > 0 {
+		return newRotatingFile(cfg.FilePath, cfg.Rotation, cfg.FileMode), nil
+	}
+
+	// lumberjack은 기존 파일의 권한을 유지하므로 먼저 파일을 만든다.
+	f, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, cfg.FileMode)
+	switch {
+	case err == nil:
+		// umask 영향을 받지 않도록 생성 직후 권한을 다시 지정한다.
+		chmodErr := f.Chmod(cfg.FileMode)
+		closeErr := f.Close()
+		if chmodErr != nil {
+			return nil, chmodErr
+		}
+		if closeErr != nil {
+			return nil, closeErr
+		}
+	case !os.IsExist(err):
+		return nil, err
+	}
+
+	return &lumberjack.Logger{
+		Filename:   cfg.FilePath,
+		MaxSize:    cfg.Rotation.MaxSize,
+		MaxAge:     cfg.Rotation.MaxAge,
+		MaxBackups: cfg.Rotation.MaxBackups,
+		Compress:   *cfg.Rotation.Compress,
+		LocalTime:  *cfg.Rotation.LocalTime,
+	}, nil
+}
+
+// AsyncDropped returns the number of entries dropped by the async writers of
+// the global logger.
+func AsyncDropped() uint64 {
+	return L().AsyncDropped()
+}
+
+// NetworkDropped returns the number of entries dropped by the network sinks
+// of the global logger.
+func NetworkDropped() uint64 {
+	return L().NetworkDropped()
 }
 
 func Sync() error {
 	return zap.L().Sync()
 }
 
-// Close stops the SIGHUP listener (if started) and syncs the global logger.
-// Safe for concurrent use. After Close, Init cannot be called again (sync.Once is consumed).
+// Close closes the global logger: it stops its signal listener, syncs it and
+// closes its files. Init may be called again afterwards.
 func Close() error {
-	sigMu.Lock()
-	if sigCh != nil {
-		signal.Stop(sigCh)
-		close(sigCh)
-		sigCh = nil
-	}
-	sigMu.Unlock()
-	return Sync()
+	initMu.Lock()
+	defer initMu.Unlock()
+
+	return L().Close()
 }
 
+// FromContext returns the trace fields of ctx followed by the fields added
+// with WithFields. If ctx carries a TailBuffer, the WithDebugLog flag or a
+// WithSampled decision, a field that is not encoded routes the entry
+// accordingly.
 func FromContext(ctx context.Context) []zap.Field {
-	return []zap.Field{
+	extra := GetFields(ctx)
+	fields := make([]zap.Field, 0, 4+len(extra))
+	fields = append(fields,
 		zap.String(traceFieldName, GetTraceID(ctx)),
 		zap.String(spanIDFieldName, GetSpanID(ctx)),
 		zap.String(pSpanIDFieldName, GetPSpanID(ctx)),
+	)
+	fields = append(fields, extra...)
+	if b := GetTailBuffer(ctx); b != nil {
+		fields = append(fields, b.field())
+	}
+	if IsDebugLog(ctx) {
+		fields = append(fields, debugLogField())
 	}
+	if sampled, ok := GetSampled(ctx); ok {
+		fields = append(fields, sampledLogField(sampled))
+	}
+	return fields
 }
 
 func Debugf(ctx context.Context, msgFormat string, args ...any) {
@@ -127,6 +502,16 @@ func Errorf(ctx context.Context, msgFormat string, args ...any) {
 	zap.L().Error(formatMessage(msgFormat, args...), FromContext(ctx)...)
 }
 
+// Fatalf logs at FatalLevel and then calls os.Exit(1).
+func Fatalf(ctx context.Context, msgFormat string, args ...any) {
+	zap.L().Fatal(formatMessage(msgFormat, args...), FromContext(ctx)...)
+}
+
+// Panicf logs at PanicLevel and then panics with the message.
+func Panicf(ctx context.Context, msgFormat string, args ...any) {
+	zap.L().Panic(formatMessage(msgFormat, args...), FromContext(ctx)...)
+}
+
 func formatMessage(msgFormat string, args ...any) string {
 	if len(args) == 0 {
 		return msgFormat
@@ -2,9 +2,10 @@ package log
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
-	"sync"
+	"strings"
 	"testing"
 
 	"go.uber.org/zap"
@@ -65,6 +66,27 @@ func TestFromContextUsesUnknownWhenMissing(t *testing.T) {
 	}
 }
 
+func TestFromContextAppendsContextFields(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	prev := zap.L()
+	zap.ReplaceGlobals(zap.New(core))
+	t.Cleanup(func() { zap.ReplaceGlobals(prev) })
+
+	ctx := WithTraceID(context.Background(), "t-1")
+	ctx = WithFields(ctx, zap.String("userId", "u-1"))
+	ctx = WithFields(ctx, zap.String("tenant", "acme"))
+
+	if got := len(FromContext(ctx)); got != 5 {
+		t.Fatalf("unexpected field count: %d", got)
+	}
+
+	Infof(ctx, "with fields")
+	fields := logs.All()[0].ContextMap()
+	if fields[traceFieldName] != "t-1" || fields["userId"] != "u-1" || fields["tenant"] != "acme" {
+		t.Fatalf("unexpected fields: %v", fields)
+	}
+}
+
 func TestLevelHelpersWriteContextFields(t *testing.T) {
 	core, logs := observer.New(zapcore.DebugLevel)
 	logger := zap.New(core)
@@ -153,11 +175,15 @@ func TestCloseStopsSIGHUPListener(t *testing.T) {
 	if err := Init(logPath); err != nil {
 		t.Fatalf("Init returned error: %v", err)
 	}
+	l := L()
+	if l.sigCh == nil {
+		t.Fatal("expected sigCh to be set after Init with a file")
+	}
 
 	// Close may return a benign stdout sync error — only check sigCh cleanup.
 	_ = Close()
 
-	if sigCh != nil {
+	if l.sigCh != nil {
 		t.Fatal("expected sigCh to be nil after Close")
 	}
 }
@@ -176,7 +202,194 @@ func TestCloseWithoutFileInit(t *testing.T) {
 }
 
 func resetInitStateForTest() {
-	initErr = nil
-	initOnce = sync.Once{}
-	sigCh = nil
+	initMu.Lock()
+	defer initMu.Unlock()
+
+	global.Store(nil)
+	owned = false
+}
+
+func TestInitWithConfigWritesFileWithStaticFields(t *testing.T) {
+	prev := zap.L()
+	defer zap.ReplaceGlobals(prev)
+
+	resetInitStateForTest()
+
+	logPath := filepath.Join(t.TempDir(), "logs", "app.log")
+	err := InitWithConfig(Config{
+		Level:       zapcore.WarnLevel,
+		Stdout:      new(false),
+		FilePath:    logPath,
+		FileMode:    0o640,
+		ServiceName: "svc",
+		Version:     "v1.2.3",
+		Host:        "host-1",
+		Fields:      map[string]string{"region": "kr"},
+	})
+	if err != nil {
+		t.Fatalf("InitWithConfig returned error: %v", err)
+	}
+	t.Cleanup(func() { _ = Close() })
+
+	Infof(context.Background(), "filtered")
+	Warnf(context.Background(), "kept")
+	_ = Sync()
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected one line, got: %q", data)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("failed to decode log line: %v", err)
+	}
+	want := map[string]string{
+		"msg":     "kept",
+		"service": "svc",
+		"version": "v1.2.3",
+		"host":    "host-1",
+		"region":  "kr",
+	}
+	for key, value := range want {
+		if got := entry[key]; got != value {
+			t.Fatalf("unexpected %s: %#v", key, got)
+		}
+	}
+
+	info, err := os.Stat(logPath)
+	if err != nil {
+		t.Fatalf("failed to stat log file: %v", err)
+	}
+	if got := info.Mode().Perm(); got != 0o640 {
+		t.Fatalf("unexpected file mode: %v", got)
+	}
+}
+
+func TestInitWithConfigRejectsUnknownEncoding(t *testing.T) {
+	prev := zap.L()
+	defer zap.ReplaceGlobals(prev)
+
+	resetInitStateForTest()
+	if err := InitWithConfig(Config{Encoding: "xml"}); err == nil {
+		t.Fatal("expected error for unknown encoding")
+	}
+}
+
+func TestNewLoggerDoesNotTouchGlobal(t *testing.T) {
+	prevZap := zap.L()
+	prevGlobal := L()
+
+	logPath := filepath.Join(t.TempDir(), "instance.log")
+	l, err := New(Config{Stdout: new(false), FilePath: logPath, Level: zapcore.DebugLevel})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if zap.L() != prevZap || L() != prevGlobal {
+		t.Fatal("New should not replace the global logger")
+	}
+
+	l.Zap().Debug("instance line")
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("second Close should be a no-op, got: %v", err)
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), "instance line") {
+		t.Fatalf("unexpected file content: %q", data)
+	}
+}
+
+func TestInitRecoversAfterFailureAndClose(t *testing.T) {
+	prev := zap.L()
+	defer zap.ReplaceGlobals(prev)
+
+	resetInitStateForTest()
+	before := zap.L()
+
+	if err := InitWithConfig(Config{Encoding: "xml"}); err == nil {
+		t.Fatal("expected error for unknown encoding")
+	}
+	if zap.L() != before {
+		t.Fatal("failed Init should leave the global logger untouched")
+	}
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.log")
+	if err := InitWithConfig(Config{Stdout: new(false), FilePath: first}); err != nil {
+		t.Fatalf("Init after failure returned error: %v", err)
+	}
+	firstLogger := L()
+	_ = Close()
+
+	second := filepath.Join(dir, "second.log")
+	if err := InitWithConfig(Config{Stdout: new(false), FilePath: second}); err != nil {
+		t.Fatalf("Init after Close returned error: %v", err)
+	}
+	t.Cleanup(func() { _ = Close() })
+	if L() == firstLogger {
+		t.Fatal("Init should install a new logger")
+	}
+
+	Infof(context.Background(), "second logger")
+	_ = Sync()
+	data, err := os.ReadFile(second)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), "second logger") {
+		t.Fatalf("unexpected file content: %q", data)
+	}
+}
+
+func TestInitClosesPreviousInitLogger(t *testing.T) {
+	prev := zap.L()
+	defer zap.ReplaceGlobals(prev)
+
+	resetInitStateForTest()
+	dir := t.TempDir()
+	if err := Init(filepath.Join(dir, "a.log")); err != nil {
+		t.Fatalf("Init returned error: %v", err)
+	}
+	first := L()
+	if err := Init(filepath.Join(dir, "b.log")); err != nil {
+		t.Fatalf("Init returned error: %v", err)
+	}
+	t.Cleanup(func() { _ = Close() })
+
+	if !first.closed || first.sigCh != nil {
+		t.Fatal("previous Init logger should be closed")
+	}
+}
+
+func TestReplaceGlobalsRestoresPrevious(t *testing.T) {
+	prevZap := zap.L()
+	prevGlobal := L()
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := newLoggerFromCore(core, newLevelState(zapcore.InfoLevel, 0, nil))
+	restore := ReplaceGlobals(l)
+
+	if L() != l || Level() != zapcore.InfoLevel {
+		t.Fatal("ReplaceGlobals should install the logger")
+	}
+	Infof(context.Background(), "via global")
+	if logs.Len() != 1 {
+		t.Fatalf("unexpected log count: %d", logs.Len())
+	}
+
+	restore()
+	if zap.L() != prevZap || L() != prevGlobal {
+		t.Fatal("restore should reinstall the previous logger")
+	}
 }
@@ -0,0 +1,205 @@
+package log
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// namedLevelSet is an immutable snapshot of the per-logger level overrides.
+type namedLevelSet struct {
+	levels map[string]zapcore.Level
+	// minLevel is the lowest override, used by Enabled to let entries
+	// through to Check where the logger name is known.
+	minLevel zapcore.Level
+}
+
+// Named returns a child of the global logger with the given name. Its level
+// follows SetNamedLevel overrides for name or its dotted parents ("grpc"
+// applies to "grpc.client") and falls back to the global level.
+func Named(name string) *zap.Logger {
+	return rootLogger().Named(name)
+}
+
+// SetNamedLevel overrides the level of the loggers returned by Named(name).
+func SetNamedLevel(name string, lvl zapcore.Level) {
+	L().SetNamedLevel(name, lvl)
+}
+
+// UnsetNamedLevel removes the override of name so that it follows the global
+// level again.
+func UnsetNamedLevel(name string) {
+	L().UnsetNamedLevel(name)
+}
+
+// NamedLevel returns the effective level of the loggers returned by
+// Named(name).
+func NamedLevel(name string) zapcore.Level {
+	return L().NamedLevel(name)
+}
+
+// NamedLevels returns a copy of the current overrides.
+func NamedLevels() map[string]zapcore.Level {
+	return L().NamedLevels()
+}
+
+// Named returns a child of l with the given name.
+func (l *Logger) Named(name string) *zap.Logger {
+	return l.plain.Named(name)
+}
+
+// SetNamedLevel overrides the level of the loggers returned by l.Named(name).
+func (l *Logger) SetNamedLevel(name string, lvl zapcore.Level) {
+	s := l.levels
+	s.namedMu.Lock()
+	defer s.namedMu.Unlock()
+
+	levels := s.namedLevels()
+	levels[name] = lvl
+	s.storeNamed(levels)
+}
+
+// UnsetNamedLevel removes the override of name.
+func (l *Logger) UnsetNamedLevel(name string) {
+	s := l.levels
+	s.namedMu.Lock()
+	defer s.namedMu.Unlock()
+
+	levels := s.namedLevels()
+	delete(levels, name)
+	s.storeNamed(levels)
+}
+
+// NamedLevel returns the effective level of the loggers returned by
+// l.Named(name).
+func (l *Logger) NamedLevel(name string) zapcore.Level {
+	if lvl, ok := l.levels.named.Load().lookup(name); ok {
+		return lvl
+	}
+	return l.Level()
+}
+
+// NamedLevels returns a copy of the current overrides of l.
+func (l *Logger) NamedLevels() map[string]zapcore.Level {
+	return l.levels.namedLevels()
+}
+
+func (s *levelState) resetNamed(levels map[string]zapcore.Level) {
+	s.namedMu.Lock()
+	defer s.namedMu.Unlock()
+
+	copied := make(map[string]zapcore.Level, len(levels))
+	for name, lvl := range levels {
+		copied[name] = lvl
+	}
+	s.storeNamed(copied)
+}
+
+func (s *levelState) namedLevels() map[string]zapcore.Level {
+	levels := make(map[string]zapcore.Level)
+	if set := s.named.Load(); set != nil {
+		for name, lvl := range set.levels {
+			levels[name] = lvl
+		}
+	}
+	return levels
+}
+
+func (s *levelState) storeNamed(levels map[string]zapcore.Level) {
+	if len(levels) == 0 {
+		s.named.Store(nil)
+		return
+	}
+
+	set := &namedLevelSet{levels: levels, minLevel: zapcore.FatalLevel}
+	for _, lvl := range levels {
+		if lvl < set.minLevel {
+			set.minLevel = lvl
+		}
+	}
+	s.named.Store(set)
+}
+
+func (s *namedLevelSet) lookup(name string) (zapcore.Level, bool) {
+	if s == nil || name == "" {
+		return 0, false
+	}
+	for {
+		if lvl, ok := s.levels[name]; ok {
+			return lvl, true
+		}
+		idx := strings.LastIndexByte(name, '.')
+		if idx < 0 {
+			return 0, false
+		}
+		name = name[:idx]
+	}
+}
+
+// rootLogger returns zap.L() without the caller skip added for the
+// package-level helpers when it belongs to the global Logger.
+func rootLogger() *zap.Logger {
+	l := zap.L()
+	if g := global.Load(); g != nil && g.wrapped == l {
+		return g.plain
+	}
+	return l
+}
+
+// levelCore applies the level and the named overrides of a Logger in front
+// of cores that accept every level.
+type levelCore struct {
+	zapcore.Core
+	levels *levelState
+	// tail and debug come from the fields added with With.
+	tail  *TailBuffer
+	debug bool
+}
+
+func newLevelCore(core zapcore.Core, levels *levelState) zapcore.Core {
+	return &levelCore{Core: core, levels: levels}
+}
+
+func (c *levelCore) Enabled(lvl zapcore.Level) bool {
+	if c.levels.atomic.Enabled(lvl) || tailBuffered(lvl) || activeDebugLogs.Load() > 0 {
+		return true
+	}
+	set := c.levels.named.Load()
+	return set != nil && lvl >= set.minLevel
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &levelCore{Core: c.Core.With(fields), levels: c.levels, tail: c.tail, debug: c.debug}
+	if b := tailBufferField(fields); b != nil {
+		clone.tail = b
+	}
+	if hasDebugLogField(fields) {
+		clone.debug = true
+	}
+	return clone
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	enabled := c.levels.enabledFor(ent.LoggerName, ent.Level)
+	// TailBuffer와 debug 플래그는 필드로 전달되므로 Write에서 판단한다.
+	if tailBuffered(ent.Level) || (!enabled && (c.debug || activeDebugLogs.Load() > 0)) {
+		return ce.AddCore(ent, &routeCore{core: c.Core, tail: c.tail, debug: c.debug, enabled: enabled})
+	}
+	if !enabled {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// tailBuffered reports whether entries of lvl may go to a TailBuffer.
+func tailBuffered(lvl zapcore.Level) bool {
+	return lvl < zapcore.WarnLevel && activeTailBuffers.Load() > 0
+}
+
+func (s *levelState) enabledFor(name string, lvl zapcore.Level) bool {
+	if override, ok := s.named.Load().lookup(name); ok {
+		return lvl >= override
+	}
+	return s.atomic.Enabled(lvl)
+}
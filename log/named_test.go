@@ -0,0 +1,110 @@
+package log
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestNamedLevelOverridesGlobalLevel(t *testing.T) {
+	logs := installLevelCoreForTest(t, zapcore.InfoLevel)
+	SetNamedLevel("grpc", zapcore.ErrorLevel)
+	SetNamedLevel("db", zapcore.DebugLevel)
+
+	Named("grpc").Info("quiet")
+	Named("grpc.client").Warn("quiet child")
+	Named("grpc").Error("loud")
+	Named("db").Debug("verbose")
+	Named("http").Debug("filtered")
+	Named("http").Info("global")
+	Infof(context.Background(), "root")
+
+	var got []string
+	for _, entry := range logs.All() {
+		got = append(got, entry.LoggerName+":"+entry.Message)
+	}
+	want := []string{"grpc:loud", "db:verbose", "http:global", ":root"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected entries: %v", got)
+	}
+}
+
+func TestUnsetNamedLevelFallsBackToGlobal(t *testing.T) {
+	installLevelCoreForTest(t, zapcore.WarnLevel)
+	SetNamedLevel("grpc", zapcore.DebugLevel)
+	if got := NamedLevel("grpc.server"); got != zapcore.DebugLevel {
+		t.Fatalf("unexpected inherited level: %v", got)
+	}
+
+	UnsetNamedLevel("grpc")
+	if got := NamedLevel("grpc"); got != zapcore.WarnLevel {
+		t.Fatalf("unexpected level after unset: %v", got)
+	}
+	if got := len(NamedLevels()); got != 0 {
+		t.Fatalf("expected no overrides, got: %d", got)
+	}
+}
+
+func TestInitWithConfigAppliesNamedLevels(t *testing.T) {
+	prev := zap.L()
+	defer zap.ReplaceGlobals(prev)
+
+	resetInitStateForTest()
+	err := InitWithConfig(Config{
+		Stdout:      new(false),
+		NamedLevels: map[string]zapcore.Level{"grpc": zapcore.ErrorLevel},
+	})
+	if err != nil {
+		t.Fatalf("InitWithConfig returned error: %v", err)
+	}
+	t.Cleanup(func() { _ = Close() })
+
+	if got := NamedLevel("grpc"); got != zapcore.ErrorLevel {
+		t.Fatalf("unexpected named level: %v", got)
+	}
+	if Named("grpc").Core().Enabled(zapcore.DebugLevel) {
+		t.Fatal("debug should be disabled")
+	}
+}
+
+func TestLevelHandlerChangesNamedLevel(t *testing.T) {
+	installLevelCoreForTest(t, zapcore.InfoLevel)
+	handler := LevelHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/log/level", strings.NewReader(`{"logger":"grpc","level":"error"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", rec.Code)
+	}
+	if got := NamedLevel("grpc"); got != zapcore.ErrorLevel {
+		t.Fatalf("unexpected named level: %v", got)
+	}
+	if got := Level(); got != zapcore.InfoLevel {
+		t.Fatalf("global level should not change, got: %v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/log/level?logger=grpc", nil))
+	if body := decodeLevelPayload(t, rec); body.Logger != "grpc" || body.Level != "error" {
+		t.Fatalf("unexpected response: %+v", body)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/log/level?logger=grpc", nil))
+	if body := decodeLevelPayload(t, rec); body.Level != "info" {
+		t.Fatalf("unexpected response: %+v", body)
+	}
+}
+
+// installLevelCoreForTest installs a global logger that filters through
+// levelCore the same way Init does.
+func installLevelCoreForTest(t *testing.T, lvl zapcore.Level) *observer.ObservedLogs {
+	t.Helper()
+	return resetLevelForTest(t, lvl, 0)
+}
@@ -0,0 +1,648 @@
+package log
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultNetworkTimeout = 5 * time.Second
+	defaultMinBackoff     = 100 * time.Millisecond
+	defaultMaxBackoff     = 30 * time.Second
+	defaultSpoolMaxSize   = 64 // MB
+	defaultNetQueueSize   = 1024
+
+	// spoolBatch is the number of spooled frames replayed per lock.
+	spoolBatch = 256
+)
+
+var (
+	networkBuffers = buffer.NewPool()
+	errSpoolFull   = errors.New("log: spool is full")
+)
+
+// NetworkConfig is the connection of a network sink (SyslogConfig,
+// GELFConfig, FluentConfig). The sink connects in the background and
+// reconnects with exponential backoff; entries logged while the collector is
+// down go to the spool file and are sent before new ones once it is back.
+type NetworkConfig struct {
+	// Network is "udp", "tcp", "unix" or "unixgram". The default depends on
+	// the protocol.
+	Network string
+	// Address is host:port, or the socket path for unix networks.
+	Address string
+	// TLS encrypts tcp connections when non-nil.
+	TLS *tls.Config
+	// DialTimeout and WriteTimeout default to 5s.
+	DialTimeout  time.Duration
+	WriteTimeout time.Duration
+	// MinBackoff and MaxBackoff bound the wait between reconnects. They
+	// default to 100ms and 30s.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// SpoolPath keeps the entries logged while the collector is down. Spooled
+	// entries survive a restart and are sent at least once. Without a spool
+	// they are dropped.
+	SpoolPath string
+	// SpoolMaxSize is the size in megabytes of the spool. Entries are dropped
+	// while it is full. Defaults to 64.
+	SpoolMaxSize int
+	// QueueSize is the number of entries waiting to be sent to a connected
+	// collector. Entries logged while it is full go to the spool, so a
+	// collector that stops reading does not block the caller. Defaults to
+	// 1024.
+	QueueSize int
+}
+
+func checkNetworkConfig(n *NetworkConfig, network string, networks ...string) error {
+	if n.Network == "" {
+		n.Network = network
+	}
+	switch {
+	case !slices.Contains(networks, n.Network):
+		return fmt.Errorf("log: unsupported network %q", n.Network)
+	case n.Address == "":
+		return errors.New("log: network sink needs an address")
+	case n.TLS != nil && n.Network != "tcp":
+		return fmt.Errorf("log: TLS is not supported over %s", n.Network)
+	}
+	if n.DialTimeout <= 0 {
+		n.DialTimeout = defaultNetworkTimeout
+	}
+	if n.WriteTimeout <= 0 {
+		n.WriteTimeout = defaultNetworkTimeout
+	}
+	if n.MinBackoff <= 0 {
+		n.MinBackoff = defaultMinBackoff
+	}
+	if n.MaxBackoff < n.MinBackoff {
+		n.MaxBackoff = max(defaultMaxBackoff, n.MinBackoff)
+	}
+	if n.SpoolMaxSize <= 0 {
+		n.SpoolMaxSize = defaultSpoolMaxSize
+	}
+	if n.QueueSize <= 0 {
+		n.QueueSize = defaultNetQueueSize
+	}
+	return nil
+}
+
+// stream reports whether n delivers bytes rather than datagrams, in which
+// case frames need a delimiter or a length prefix.
+func (n NetworkConfig) stream() bool {
+	return n.Network == "tcp" || n.Network == "unix"
+}
+
+// netWriter is a zapcore.WriteSyncer that sends every Write as one frame to
+// a collector. It never waits for the collector: a background goroutine sends
+// the queued frames, and frames go to the spool (or are dropped) while the
+// collector is down or the queue is full.
+type netWriter struct {
+	cfg NetworkConfig
+	// split turns a frame into datagrams, e.g. GELF chunks. nil sends it as
+	// is.
+	split func([]byte) [][]byte
+
+	mu     sync.Mutex
+	conn   net.Conn
+	spool  *spool
+	closed bool
+
+	// queue is only used while connected and the spool is empty, so that
+	// queued frames are always older than spooled ones.
+	queue   chan netFrame
+	wake    chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+	dropped atomic.Uint64
+}
+
+// netFrame is a queued frame, or a Sync marker when synced is non-nil.
+type netFrame struct {
+	p      []byte
+	synced chan struct{}
+}
+
+func newNetWriter(cfg NetworkConfig, split func([]byte) [][]byte, fileMode, dirMode os.FileMode) (*netWriter, error) {
+	w := &netWriter{
+		cfg:   cfg,
+		split: split,
+		queue: make(chan netFrame, cfg.QueueSize),
+		wake:  make(chan struct{}, 1),
+		done:  make(chan struct{}),
+	}
+	if cfg.SpoolPath != "" {
+		if err := os.MkdirAll(filepath.Dir(cfg.SpoolPath), dirMode); err != nil {
+			return nil, err
+		}
+		s, err := openSpool(cfg.SpoolPath, int64(cfg.SpoolMaxSize)*megabyte, fileMode)
+		if err != nil {
+			return nil, err
+		}
+		w.spool = s
+	}
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	go w.run()
+	return w, nil
+}
+
+// Write queues p for the background goroutine; zap reuses p after Write
+// returns.
+func (w *netWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		w.dropped.Add(1)
+		return len(p), nil
+	}
+	if w.conn != nil && !w.spooled() {
+		select {
+		case w.queue <- netFrame{p: append([]byte(nil), p...)}:
+			return len(p), nil
+		default:
+		}
+		// 큐가 가득 차면 spool에 넘기고, 큐를 비운 뒤 spool을 보내도록 깨운다.
+		select {
+		case w.wake <- struct{}{}:
+		default:
+		}
+	}
+	w.spoolFrame(p)
+	return len(p), nil
+}
+
+// spooled reports whether frames are waiting in the spool. The caller holds
+// w.mu.
+func (w *netWriter) spooled() bool {
+	return w.spool != nil && w.spool.pending()
+}
+
+// spoolFrame appends p to the spool, or drops it. The caller holds w.mu.
+func (w *netWriter) spoolFrame(p []byte) {
+	if w.spool == nil || w.spool.append(p) != nil {
+		w.dropped.Add(1)
+	}
+}
+
+// Sync waits up to WriteTimeout until the frames queued before it are sent.
+// Spooled frames are not waited for.
+func (w *netWriter) Sync() error {
+	w.mu.Lock()
+	connected := !w.closed && w.conn != nil
+	w.mu.Unlock()
+	if !connected {
+		return nil
+	}
+
+	timer := time.NewTimer(w.cfg.WriteTimeout)
+	defer timer.Stop()
+	marker := netFrame{synced: make(chan struct{})}
+	select {
+	case w.queue <- marker:
+	case <-timer.C:
+		return fmt.Errorf("log: %s collector %s is not reading", w.cfg.Network, w.cfg.Address)
+	}
+	select {
+	case <-marker.synced:
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("log: %s collector %s is not reading", w.cfg.Network, w.cfg.Address)
+	}
+}
+
+// Close stops reconnecting, sends the queued frames and closes the
+// connection. Spooled frames stay in the spool for the next start.
+func (w *netWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	w.cancel()
+	<-w.done
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var errs []error
+	if w.conn != nil {
+		errs = append(errs, w.conn.Close())
+		w.conn = nil
+	}
+	if w.spool != nil {
+		errs = append(errs, w.spool.close())
+	}
+	return errors.Join(errs...)
+}
+
+// Dropped returns the number of frames dropped because the collector was
+// down or slow and the spool was missing or full.
+func (w *netWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+func (w *netWriter) send(conn net.Conn, p []byte) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(w.cfg.WriteTimeout)); err != nil {
+		return err
+	}
+	if w.split == nil {
+		_, err := conn.Write(p)
+		return err
+	}
+	for _, packet := range w.split(p) {
+		if _, err := conn.Write(packet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run connects, sends the queued frames until the connection breaks and
+// reconnects with exponential backoff until Close.
+func (w *netWriter) run() {
+	defer close(w.done)
+
+	backoff := w.cfg.MinBackoff
+	reported := false
+	for {
+		conn, err := w.connect()
+		if err == nil {
+			backoff, reported = w.cfg.MinBackoff, false
+			if err := w.serve(conn); err == nil {
+				return
+			}
+			continue
+		}
+		if w.ctx.Err() != nil {
+			return
+		}
+		if !reported {
+			// 장애 동안 같은 메시지가 반복되지 않도록 한 번만 알린다.
+			_, _ = fmt.Fprintf(os.Stderr, "log: %s collector %s unavailable: %v\n", w.cfg.Network, w.cfg.Address, err)
+			reported = true
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-w.ctx.Done():
+			timer.Stop()
+			return
+		}
+		backoff = min(backoff*2, w.cfg.MaxBackoff)
+	}
+}
+
+// connect dials the collector and sends the spooled frames before Write
+// queues new ones.
+func (w *netWriter) connect() (net.Conn, error) {
+	conn, err := w.dial()
+	if err != nil {
+		return nil, err
+	}
+	if err := w.replay(conn); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// serve sends the queued frames, and the spooled ones after the queue
+// overflowed, until the connection breaks. It returns nil after Close.
+func (w *netWriter) serve(conn net.Conn) error {
+	for {
+		select {
+		case f := <-w.queue:
+			if err := w.sendFrame(conn, f); err != nil {
+				return err
+			}
+		case <-w.wake:
+			// spool의 프레임보다 먼저 큐에 들어온 프레임을 먼저 보낸다.
+			if err := w.sendQueued(conn); err != nil {
+				return err
+			}
+			if err := w.replay(conn); err != nil {
+				w.disconnect(conn, nil)
+				return err
+			}
+		case <-w.ctx.Done():
+			_ = w.sendQueued(conn)
+			return nil
+		}
+	}
+}
+
+// sendQueued sends the frames in the queue without waiting for more.
+func (w *netWriter) sendQueued(conn net.Conn) error {
+	for {
+		select {
+		case f := <-w.queue:
+			if err := w.sendFrame(conn, f); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+// sendFrame sends f, or moves it and the rest of the queue to the spool if
+// the connection is broken.
+func (w *netWriter) sendFrame(conn net.Conn, f netFrame) error {
+	if f.synced != nil {
+		close(f.synced)
+		return nil
+	}
+	if err := w.send(conn, f.p); err != nil {
+		w.disconnect(conn, f.p)
+		return err
+	}
+	return nil
+}
+
+// disconnect closes a broken conn and spools p and the queued frames, so that
+// they are sent first after reconnecting.
+func (w *netWriter) disconnect(conn net.Conn, p []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_ = conn.Close()
+	if w.conn == conn {
+		w.conn = nil
+	}
+	if p != nil {
+		w.spoolFrame(p)
+	}
+	for {
+		select {
+		case f := <-w.queue:
+			if f.synced != nil {
+				close(f.synced)
+				continue
+			}
+			w.spoolFrame(f.p)
+		default:
+			return
+		}
+	}
+}
+
+// replay sends the spooled frames and hands conn to Write once the spool is
+// empty.
+func (w *netWriter) replay(conn net.Conn) error {
+	for {
+		w.mu.Lock()
+		if w.closed {
+			w.mu.Unlock()
+			return net.ErrClosed
+		}
+		var frames [][]byte
+		var next int64
+		var err error
+		if w.spool != nil {
+			frames, next, err = w.spool.read(spoolBatch)
+		}
+		if err != nil || len(frames) == 0 {
+			if w.spool != nil {
+				err = errors.Join(err, w.spool.reset())
+			}
+			w.conn = conn
+			w.mu.Unlock()
+			if err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "log: spool %s reset: %v\n", w.cfg.SpoolPath, err)
+			}
+			return nil
+		}
+		w.mu.Unlock()
+
+		// 재전송 중에 들어온 로그는 spool 뒤에 쌓이므로 순서가 유지된다.
+		for _, frame := range frames {
+			if err := w.send(conn, frame); err != nil {
+				return err
+			}
+		}
+		w.mu.Lock()
+		w.spool.advance(next)
+		w.mu.Unlock()
+	}
+}
+
+func (w *netWriter) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: w.cfg.DialTimeout}
+	if w.cfg.TLS != nil {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: w.cfg.TLS}
+		return tlsDialer.DialContext(w.ctx, w.cfg.Network, w.cfg.Address)
+	}
+	return dialer.DialContext(w.ctx, w.cfg.Network, w.cfg.Address)
+}
+
+// spool is a file of length-prefixed frames. Frames before readOff were sent
+// already; the file is truncated once every frame is sent.
+type spool struct {
+	f       *os.File
+	max     int64
+	size    int64
+	readOff int64
+}
+
+func openSpool(path string, maxSize int64, mode os.FileMode) (*spool, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, mode)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &spool{f: f, max: maxSize, size: info.Size()}, nil
+}
+
+func (s *spool) append(p []byte) error {
+	if s.size+4+int64(len(p)) > s.max {
+		return errSpoolFull
+	}
+	frame := make([]byte, 4+len(p))
+	binary.BigEndian.PutUint32(frame, uint32(len(p)))
+	copy(frame[4:], p)
+	n, err := s.f.WriteAt(frame, s.size)
+	if err != nil {
+		// 일부만 기록된 프레임은 다음 append가 덮어쓴다.
+		return err
+	}
+	s.size += int64(n)
+	return nil
+}
+
+// read returns up to limit frames after readOff and the offset after them.
+// A truncated trailing frame ends the spool.
+func (s *spool) read(limit int) ([][]byte, int64, error) {
+	var frames [][]byte
+	off := s.readOff
+	var header [4]byte
+	for len(frames) < limit && off+4 <= s.size {
+		if _, err := s.f.ReadAt(header[:], off); err != nil {
+			return frames, off, err
+		}
+		n := int64(binary.BigEndian.Uint32(header[:]))
+		if off+4+n > s.size {
+			break
+		}
+		frame := make([]byte, n)
+		if _, err := s.f.ReadAt(frame, off+4); err != nil && !errors.Is(err, io.EOF) {
+			return frames, off, err
+		}
+		frames = append(frames, frame)
+		off += 4 + n
+	}
+	return frames, off, nil
+}
+
+// pending reports whether frames are waiting to be sent.
+func (s *spool) pending() bool {
+	return s.readOff < s.size
+}
+
+func (s *spool) advance(off int64) {
+	s.readOff = off
+}
+
+func (s *spool) reset() error {
+	s.size, s.readOff = 0, 0
+	return s.f.Truncate(0)
+}
+
+func (s *spool) close() error {
+	return s.f.Close()
+}
+
+// recordEncoder collects the fields of a network sink into a map that the
+// protocol encoders turn into frames. Fields added with With are kept in the
+// embedded encoder.
+type recordEncoder struct {
+	*zapcore.MapObjectEncoder
+}
+
+func newRecordEncoder() recordEncoder {
+	return recordEncoder{zapcore.NewMapObjectEncoder()}
+}
+
+func (e recordEncoder) clone() recordEncoder {
+	c := newRecordEncoder()
+	maps.Copy(c.Fields, e.Fields)
+	return c
+}
+
+// record returns the With fields and fields of an entry with values that
+// JSON and msgpack can encode.
+func (e recordEncoder) record(fields []zapcore.Field) map[string]any {
+	c := e.clone()
+	for _, f := range fields {
+		f.AddTo(c)
+	}
+	for k, v := range c.Fields {
+		c.Fields[k] = plainValue(v)
+	}
+	return c.Fields
+}
+
+// entryRecord returns the record of an entry with the keys of the JSON
+// encoder, without the time.
+func (e recordEncoder) entryRecord(ent zapcore.Entry, fields []zapcore.Field) map[string]any {
+	record := e.record(fields)
+	record["level"] = ent.Level.String()
+	if ent.LoggerName != "" {
+		record["logger"] = ent.LoggerName
+	}
+	if ent.Caller.Defined {
+		record["caller"] = ent.Caller.TrimmedPath()
+	}
+	record["msg"] = ent.Message
+	if ent.Stack != "" {
+		record["stacktrace"] = ent.Stack
+	}
+	return record
+}
+
+// plainValue converts the values stored by MapObjectEncoder to the form the
+// JSON encoder writes them in.
+func plainValue(v any) any {
+	switch v := v.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.Seconds()
+	case complex64, complex128:
+		return fmt.Sprint(v)
+	case error:
+		return v.Error()
+	case map[string]any:
+		// With 필드의 map은 여러 core가 공유하므로 복사본을 만든다.
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[k] = plainValue(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = plainValue(e)
+		}
+		return out
+	case nil, bool, string, []byte,
+		int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr,
+		float32, float64:
+		return v
+	default:
+		// AddReflected 값은 JSON 표현으로 맞춘다.
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		var out any
+		if err := json.Unmarshal(data, &out); err != nil {
+			return string(data)
+		}
+		return out
+	}
+}
+
+// syslogSeverity maps a level to an RFC 5424 severity, which GELF uses too.
+func syslogSeverity(lvl zapcore.Level) int {
+	switch {
+	case lvl <= zapcore.DebugLevel:
+		return 7
+	case lvl == zapcore.InfoLevel:
+		return 6
+	case lvl == zapcore.WarnLevel:
+		return 4
+	case lvl == zapcore.ErrorLevel:
+		return 3
+	case lvl == zapcore.DPanicLevel:
+		return 2
+	case lvl == zapcore.PanicLevel:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,310 @@
+package log
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// testNetworkConfig spools entries so that none is lost before the sink
+// connects.
+func testNetworkConfig(t *testing.T, network, address string) NetworkConfig {
+	return NetworkConfig{
+		SpoolPath:    filepath.Join(t.TempDir(), "sink.spool"),
+		Network:      network,
+		Address:      address,
+		DialTimeout:  time.Second,
+		WriteTimeout: time.Second,
+		MinBackoff:   10 * time.Millisecond,
+		MaxBackoff:   20 * time.Millisecond,
+	}
+}
+
+func newNetworkSinkLogger(t *testing.T, sink SinkConfig) *Logger {
+	t.Helper()
+	l, err := New(Config{Stdout: new(false), Sinks: []SinkConfig{sink}})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	return l
+}
+
+// readSyslogFrame reads one octet-counted syslog message.
+func readSyslogFrame(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	size, err := r.ReadString(' ')
+	if err != nil {
+		t.Fatalf("failed to read frame length: %v", err)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(size))
+	if err != nil {
+		t.Fatalf("invalid frame length %q", size)
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		t.Fatalf("failed to read frame: %v", err)
+	}
+	return string(msg)
+}
+
+func acceptWithin(t *testing.T, ln net.Listener) net.Conn {
+	t.Helper()
+	type result struct {
+		conn net.Conn
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		conn, err := ln.Accept()
+		ch <- result{conn, err}
+	}()
+	select {
+	case r := <-ch:
+		if r.err != nil {
+			t.Fatalf("Accept returned error: %v", r.err)
+		}
+		t.Cleanup(func() { _ = r.conn.Close() })
+		_ = r.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		return r.conn
+	case <-time.After(5 * time.Second):
+		t.Fatal("the sink did not connect")
+		return nil
+	}
+}
+
+func TestNetworkSinkSpoolsUntilCollectorIsUp(t *testing.T) {
+	// 주소만 확보하고 collector는 나중에 띄운다.
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen returned error: %v", err)
+	}
+	addr := probe.Addr().String()
+	_ = probe.Close()
+
+	network := testNetworkConfig(t, "tcp", addr)
+	network.SpoolPath = filepath.Join(t.TempDir(), "spool", "syslog.spool")
+	l := newNetworkSinkLogger(t, SinkConfig{Syslog: &SyslogConfig{NetworkConfig: network}})
+	l.Zap().Info("first while down")
+	l.Zap().Info("second while down")
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("address was taken: %v", err)
+	}
+	defer ln.Close()
+	r := bufio.NewReader(acceptWithin(t, ln))
+	l.Zap().Info("after reconnect")
+
+	for _, want := range []string{"first while down", "second while down", "after reconnect"} {
+		if got := readSyslogFrame(t, r); !strings.Contains(got, want) {
+			t.Fatalf("expected %q in order, got %q", want, got)
+		}
+	}
+	if n := l.NetworkDropped(); n != 0 {
+		t.Fatalf("spooled entries should not be dropped: %d", n)
+	}
+}
+
+func TestNetworkSinkReconnectsAfterDisconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen returned error: %v", err)
+	}
+	defer ln.Close()
+
+	network := testNetworkConfig(t, "tcp", ln.Addr().String())
+	l := newNetworkSinkLogger(t, SinkConfig{Syslog: &SyslogConfig{NetworkConfig: network}})
+	first := acceptWithin(t, ln)
+	l.Zap().Info("before disconnect")
+	if got := readSyslogFrame(t, bufio.NewReader(first)); !strings.Contains(got, "before disconnect") {
+		t.Fatalf("unexpected message: %q", got)
+	}
+	_ = first.Close()
+
+	// 끊긴 연결에 대한 쓰기는 몇 번 성공할 수 있으므로 새 연결이 생길 때까지 기록한다.
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(5 * time.Millisecond):
+				l.Zap().Info("after disconnect")
+			}
+		}
+	}()
+	second := bufio.NewReader(acceptWithin(t, ln))
+	if got := readSyslogFrame(t, second); !strings.Contains(got, "after disconnect") {
+		t.Fatalf("unexpected message: %q", got)
+	}
+}
+
+func TestNetworkSinkDoesNotWaitForCollectorThatStopsReading(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen returned error: %v", err)
+	}
+	defer ln.Close()
+
+	network := testNetworkConfig(t, "tcp", ln.Addr().String())
+	network.WriteTimeout = 500 * time.Millisecond
+	network.QueueSize = 4
+	l := newNetworkSinkLogger(t, SinkConfig{Syslog: &SyslogConfig{NetworkConfig: network}})
+	// 연결만 받고 읽지 않아 소켓 버퍼가 가득 차게 한다.
+	acceptWithin(t, ln)
+
+	msg := strings.Repeat("x", 64*1024)
+	for i := range 400 {
+		start := time.Now()
+		l.Zap().Info(msg)
+		if d := time.Since(start); d > 250*time.Millisecond {
+			t.Fatalf("write %d waited %s for the collector", i, d)
+		}
+	}
+	if n := l.NetworkDropped(); n != 0 {
+		t.Fatalf("entries should go to the spool: %d dropped", n)
+	}
+}
+
+func TestNetworkSinkOverTLS(t *testing.T) {
+	cert, pool := newTestCertificate(t)
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatalf("Listen returned error: %v", err)
+	}
+	defer ln.Close()
+
+	network := testNetworkConfig(t, "tcp", ln.Addr().String())
+	network.TLS = &tls.Config{RootCAs: pool, ServerName: "127.0.0.1"}
+	l := newNetworkSinkLogger(t, SinkConfig{Syslog: &SyslogConfig{NetworkConfig: network}})
+	conn := acceptWithin(t, ln)
+	l.Zap().Info("over tls")
+
+	if got := readSyslogFrame(t, bufio.NewReader(conn)); !strings.Contains(got, "over tls") {
+		t.Fatalf("unexpected message: %q", got)
+	}
+}
+
+func TestNetworkSinkDropsWithoutSpool(t *testing.T) {
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen returned error: %v", err)
+	}
+	addr := probe.Addr().String()
+	_ = probe.Close()
+
+	network := testNetworkConfig(t, "tcp", addr)
+	network.SpoolPath = ""
+	l := newNetworkSinkLogger(t, SinkConfig{Syslog: &SyslogConfig{NetworkConfig: network}})
+	l.Zap().Info("lost")
+	if n := l.NetworkDropped(); n != 1 {
+		t.Fatalf("unexpected dropped count: %d", n)
+	}
+}
+
+func TestSpoolKeepsFramesAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.spool")
+	s, err := openSpool(path, 64, 0o600)
+	if err != nil {
+		t.Fatalf("openSpool returned error: %v", err)
+	}
+	for _, frame := range []string{"one", "two"} {
+		if err := s.append([]byte(frame)); err != nil {
+			t.Fatalf("append returned error: %v", err)
+		}
+	}
+	if err := s.append(make([]byte, 64)); err != errSpoolFull {
+		t.Fatalf("expected errSpoolFull, got %v", err)
+	}
+	_ = s.close()
+
+	s, err = openSpool(path, 64, 0o600)
+	if err != nil {
+		t.Fatalf("openSpool returned error: %v", err)
+	}
+	defer s.close()
+	frames, next, err := s.read(1)
+	if err != nil || len(frames) != 1 || string(frames[0]) != "one" {
+		t.Fatalf("unexpected frames: %q (%v)", frames, err)
+	}
+	s.advance(next)
+	frames, _, _ = s.read(10)
+	if len(frames) != 1 || string(frames[0]) != "two" {
+		t.Fatalf("unexpected frames: %q", frames)
+	}
+}
+
+func TestCheckConfigRejectsInvalidNetworkSinks(t *testing.T) {
+	tests := map[string]SinkConfig{
+		"no address":  {Syslog: &SyslogConfig{}},
+		"udp fluent":  {Fluent: &FluentConfig{NetworkConfig: NetworkConfig{Network: "udp", Address: "127.0.0.1:24224"}}},
+		"tls on udp":  {GELF: &GELFConfig{NetworkConfig: NetworkConfig{Address: "127.0.0.1:12201", TLS: &tls.Config{}}}},
+		"two targets": {Stderr: true, GELF: &GELFConfig{NetworkConfig: NetworkConfig{Address: "127.0.0.1:12201"}}},
+	}
+	for name, sink := range tests {
+		cfg := Config{Sinks: []SinkConfig{sink}}
+		if err := checkConfig(&cfg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	syslog := &SyslogConfig{NetworkConfig: NetworkConfig{Address: "127.0.0.1:514"}}
+	cfg := Config{ServiceName: "order api", Sinks: []SinkConfig{{Syslog: syslog, MinLevel: zapcore.WarnLevel}}}
+	if err := checkConfig(&cfg); err != nil {
+		t.Fatalf("checkConfig returned error: %v", err)
+	}
+	got := cfg.Sinks[0].Syslog
+	if got.Network != "udp" || got.AppName != "order_api" || got.Facility != defaultSyslogFacility || got.MaxBackoff != defaultMaxBackoff {
+		t.Fatalf("syslog defaults were not applied: %+v", got)
+	}
+	if syslog.Network != "" {
+		t.Fatal("checkConfig should not modify the caller's sink")
+	}
+}
+
+func newTestCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey returned error: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "log-test"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate returned error: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate returned error: %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, pool
+}
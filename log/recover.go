@@ -0,0 +1,71 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// exitProcess is replaced in tests.
+var exitProcess = os.Exit
+
+// RecoverAndLog recovers a panic, logs it at ErrorLevel with its stack and the
+// fields of ctx, and syncs the global logger. It must be deferred directly:
+//
+//	defer log.RecoverAndLog(ctx)
+func RecoverAndLog(ctx context.Context) {
+	if r := recover(); r != nil {
+		logPanic(ctx, r)
+	}
+}
+
+// RecoverAndRepanic is like RecoverAndLog but panics again with the recovered
+// value after the log is synced.
+func RecoverAndRepanic(ctx context.Context) {
+	if r := recover(); r != nil {
+		logPanic(ctx, r)
+		panic(r)
+	}
+}
+
+// Go runs fn in a new goroutine and logs a panic of fn instead of crashing the
+// process.
+func Go(ctx context.Context, fn func(ctx context.Context)) {
+	go func() {
+		defer RecoverAndLog(ctx)
+		fn(ctx)
+	}()
+}
+
+func logPanic(ctx context.Context, r any) {
+	fields := FromContext(ctx)
+	if err, ok := r.(error); ok {
+		fields = append(fields, NamedErr("panic", err))
+	} else {
+		fields = append(fields, zap.String("panic", fmt.Sprint(r)))
+	}
+	// RecoverAndLog 자신과 logPanic 프레임은 stack에서 제외한다.
+	fields = append(fields, zap.StackSkip("stacktrace", 2))
+
+	zap.L().Error("panic recovered", fields...)
+	_ = zap.L().Sync()
+}
+
+// closeOnFatal closes the Logger before the process exits on a Fatal entry.
+type closeOnFatal struct {
+	l *Logger
+}
+
+func (h *closeOnFatal) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {
+	_ = h.l.Close()
+	exitProcess(1)
+}
+
+// exitOnSignal closes l and terminates the process with sig.
+func (l *Logger) exitOnSignal(sig os.Signal) {
+	_ = l.Close()
+	reraise(sig)
+}
@@ -0,0 +1,116 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRecoverAndLogRecordsPanic(t *testing.T) {
+	logs := installLevelCoreForTest(t, zapcore.InfoLevel)
+	ctx := context.WithValue(context.Background(), TraceIDKey, "trace-1")
+
+	func() {
+		defer RecoverAndLog(ctx)
+		panic("boom")
+	}()
+
+	entries := logs.FilterMessage("panic recovered").All()
+	if len(entries) != 1 {
+		t.Fatalf("expected one panic entry, got: %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["panic"] != "boom" || fields[traceFieldName] != "trace-1" {
+		t.Fatalf("unexpected fields: %v", fields)
+	}
+	stack, _ := fields["stacktrace"].(string)
+	if !strings.Contains(stack, "TestRecoverAndLogRecordsPanic") || strings.Contains(stack, "logPanic") {
+		t.Fatalf("unexpected stack: %q", stack)
+	}
+}
+
+func TestRecoverAndRepanicPanicsAgain(t *testing.T) {
+	logs := installLevelCoreForTest(t, zapcore.InfoLevel)
+	cause := errors.New("boom")
+
+	defer func() {
+		if r := recover(); r != cause {
+			t.Fatalf("expected the original panic value, got: %v", r)
+		}
+		fields := logs.All()[0].ContextMap()
+		if fields["panic"] != "boom" {
+			t.Fatalf("error panics should be logged with Err fields: %v", fields)
+		}
+		if _, ok := fields["panicChain"]; !ok {
+			t.Fatalf("panicChain should be recorded: %v", fields)
+		}
+	}()
+
+	func() {
+		defer RecoverAndRepanic(context.Background())
+		panic(cause)
+	}()
+}
+
+func TestGoRecoversPanic(t *testing.T) {
+	logs := installLevelCoreForTest(t, zapcore.InfoLevel)
+
+	Go(context.Background(), func(context.Context) {
+		panic("in goroutine")
+	})
+
+	waitFor(t, func() bool { return logs.FilterMessage("panic recovered").Len() == 1 })
+}
+
+func TestCloseOnExitClosesBeforeFatalExit(t *testing.T) {
+	codes := make(chan int, 1)
+	exitProcess = func(code int) {
+		codes <- code
+		runtime.Goexit()
+	}
+	t.Cleanup(func() { exitProcess = os.Exit })
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	l, err := New(Config{
+		Stdout:      new(false),
+		FilePath:    path,
+		Async:       &AsyncConfig{FlushInterval: time.Hour},
+		CloseOnExit: true,
+	})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	go l.Zap().Fatal("bye")
+
+	if code := <-codes; code != 1 {
+		t.Fatalf("unexpected exit code: %d", code)
+	}
+	l.mu.Lock()
+	closed := l.closed
+	l.mu.Unlock()
+	if !closed {
+		t.Fatal("logger should be closed before exit")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), `"msg":"bye"`) {
+		t.Fatalf("fatal entry should be written: %q (%v)", data, err)
+	}
+}
+
+func TestCheckConfigDefaultsExitSignals(t *testing.T) {
+	cfg := Config{CloseOnExit: true}
+	if err := checkConfig(&cfg); err != nil {
+		t.Fatalf("checkConfig returned error: %v", err)
+	}
+	if len(cfg.ExitSignals) != 2 || cfg.ExitSignals[0] != os.Interrupt {
+		t.Fatalf("unexpected exit signals: %v", cfg.ExitSignals)
+	}
+}
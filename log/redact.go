@@ -0,0 +1,550 @@
+package log
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// MaskStrategy decides how a redacted value is rendered.
+type MaskStrategy int
+
+const (
+	// MaskFull replaces the value with "[REDACTED]".
+	MaskFull MaskStrategy = iota
+	// MaskPartial keeps the last quarter of the value (at most 4 characters)
+	// and masks the rest with '*'.
+	MaskPartial
+	// MaskHash replaces the value with a short SHA-256 digest so that equal
+	// values can still be correlated.
+	MaskHash
+)
+
+const redactedValue = "[REDACTED]"
+
+// RedactRule masks field values by key and/or string values matching a
+// pattern. Pattern rules also apply to the log message.
+type RedactRule struct {
+	// Keys are field keys, matched case-insensitively, whose whole value is
+	// masked.
+	Keys []string
+	// Pattern finds sensitive substrings in string values and messages. If it
+	// has a capturing group, only the first group is masked.
+	Pattern *regexp.Regexp
+	// Validate optionally confirms a Pattern match (e.g. a Luhn check).
+	Validate func(match string) bool
+	Strategy MaskStrategy
+}
+
+// KeyRule masks the values of the given field keys.
+func KeyRule(strategy MaskStrategy, keys ...string) RedactRule {
+	return RedactRule{Keys: keys, Strategy: strategy}
+}
+
+// EmailRule masks e-mail addresses.
+func EmailRule(strategy MaskStrategy) RedactRule {
+	return RedactRule{
+		Pattern:  regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+		Strategy: strategy,
+	}
+}
+
+// CardNumberRule masks 13-19 digit card numbers that pass the Luhn check.
+func CardNumberRule(strategy MaskStrategy) RedactRule {
+	return RedactRule{
+		Pattern:  regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`),
+		Validate: luhnValid,
+		Strategy: strategy,
+	}
+}
+
+// JWTRule masks JSON Web Tokens.
+func JWTRule(strategy MaskStrategy) RedactRule {
+	return RedactRule{
+		Pattern:  regexp.MustCompile(`eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`),
+		Strategy: strategy,
+	}
+}
+
+// BearerTokenRule masks the token of "Bearer <token>" credentials.
+func BearerTokenRule(strategy MaskStrategy) RedactRule {
+	return RedactRule{
+		Pattern:  regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]+=*)`),
+		Strategy: strategy,
+	}
+}
+
+// DefaultRedactRules returns rules for common secret keys and the built-in
+// value detectors.
+func DefaultRedactRules() []RedactRule {
+	return []RedactRule{
+		KeyRule(MaskFull, "password", "passwd", "secret", "token", "access_token", "refresh_token", "authorization", "cookie", "api_key", "apikey"),
+		BearerTokenRule(MaskFull),
+		JWTRule(MaskFull),
+		EmailRule(MaskPartial),
+		CardNumberRule(MaskPartial),
+	}
+}
+
+// redactor applies a fixed set of rules.
+type redactor struct {
+	keys     map[string]MaskStrategy
+	patterns []RedactRule
+}
+
+func newRedactor(rules []RedactRule) *redactor {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	r := &redactor{keys: make(map[string]MaskStrategy)}
+	for _, rule := range rules {
+		for _, key := range rule.Keys {
+			r.keys[strings.ToLower(key)] = rule.Strategy
+		}
+		if rule.Pattern != nil {
+			r.patterns = append(r.patterns, rule)
+		}
+	}
+	return r
+}
+
+func (r *redactor) keyStrategy(key string) (MaskStrategy, bool) {
+	strategy, ok := r.keys[strings.ToLower(key)]
+	return strategy, ok
+}
+
+// redactString masks every pattern match in s.
+func (r *redactor) redactString(s string) string {
+	for _, rule := range r.patterns {
+		s = rule.replace(s)
+	}
+	return s
+}
+
+func (rule RedactRule) replace(s string) string {
+	if !rule.Pattern.MatchString(s) {
+		return s
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range rule.Pattern.FindAllStringSubmatchIndex(s, -1) {
+		start, end := loc[0], loc[1]
+		if len(loc) >= 4 && loc[2] >= 0 {
+			start, end = loc[2], loc[3]
+		}
+		match := s[start:end]
+		if rule.Validate != nil && !rule.Validate(match) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(mask(match, rule.Strategy))
+		last = end
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+func mask(value string, strategy MaskStrategy) string {
+	switch strategy {
+	case MaskPartial:
+		n := utf8.RuneCountInString(value)
+		keep := min(n/4, 4)
+		runes := []rune(value)
+		return strings.Repeat("*", n-keep) + string(runes[n-keep:])
+	case MaskHash:
+		sum := sha256.Sum256([]byte(value))
+		return "sha256:" + hex.EncodeToString(sum[:8])
+	default:
+		return redactedValue
+	}
+}
+
+// redactFields returns fields with sensitive values masked. The input slice
+// is not modified.
+func (r *redactor) redactFields(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+	for i, field := range fields {
+		redacted, changed := r.redactField(field)
+		if !changed {
+			if out != nil {
+				out = append(out, field)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]zapcore.Field, i, len(fields))
+			copy(out, fields[:i])
+		}
+		out = append(out, redacted)
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+func (r *redactor) redactField(field zapcore.Field) (zapcore.Field, bool) {
+	if strategy, ok := r.keyStrategy(field.Key); ok && field.Type != zapcore.SkipType && field.Type != zapcore.NamespaceType {
+		return zap.String(field.Key, mask(fieldString(field), strategy)), true
+	}
+
+	switch field.Type {
+	case zapcore.StringType:
+		if redacted := r.redactString(field.String); redacted != field.String {
+			return zap.String(field.Key, redacted), true
+		}
+	case zapcore.ByteStringType:
+		value := string(field.Interface.([]byte))
+		if redacted := r.redactString(value); redacted != value {
+			return zap.String(field.Key, redacted), true
+		}
+	case zapcore.StringerType:
+		value := fieldString(field)
+		if redacted := r.redactString(value); redacted != value {
+			return zap.String(field.Key, redacted), true
+		}
+	case zapcore.ErrorType:
+		value := fieldString(field)
+		if redacted := r.redactString(value); redacted != value {
+			return zap.String(field.Key, redacted), true
+		}
+	case zapcore.ObjectMarshalerType:
+		return zap.Object(field.Key, redactedObject{r: r, inner: field.Interface.(zapcore.ObjectMarshaler)}), true
+	case zapcore.InlineMarshalerType:
+		return zap.Inline(redactedObject{r: r, inner: field.Interface.(zapcore.ObjectMarshaler)}), true
+	case zapcore.ArrayMarshalerType:
+		return zap.Array(field.Key, redactedArray{r: r, inner: field.Interface.(zapcore.ArrayMarshaler)}), true
+	case zapcore.ReflectType:
+		data, err := json.Marshal(field.Interface)
+		if err != nil {
+			return field, false
+		}
+		if redacted := r.redactString(string(data)); redacted != string(data) {
+			return zap.Reflect(field.Key, json.RawMessage(redacted)), true
+		}
+	}
+	return field, false
+}
+
+// fieldString renders the value of field the way the encoder would show it.
+func fieldString(field zapcore.Field) string {
+	switch field.Type {
+	case zapcore.StringType:
+		return field.String
+	case zapcore.ErrorType:
+		if err, ok := field.Interface.(error); ok && err != nil {
+			return err.Error()
+		}
+	case zapcore.StringerType:
+		if s, ok := field.Interface.(fmt.Stringer); ok && s != nil {
+			return s.String()
+		}
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	field.AddTo(enc)
+	return fmt.Sprint(enc.Fields[field.Key])
+}
+
+// redactedObject applies the rules to the values written by an
+// ObjectMarshaler.
+type redactedObject struct {
+	r     *redactor
+	inner zapcore.ObjectMarshaler
+}
+
+func (o redactedObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return o.inner.MarshalLogObject(&redactObjectEncoder{ObjectEncoder: enc, r: o.r})
+}
+
+type redactedArray struct {
+	r     *redactor
+	inner zapcore.ArrayMarshaler
+}
+
+func (a redactedArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	return a.inner.MarshalLogArray(&redactArrayEncoder{ArrayEncoder: enc, r: a.r})
+}
+
+type redactObjectEncoder struct {
+	zapcore.ObjectEncoder
+	r *redactor
+}
+
+func (e *redactObjectEncoder) AddString(key, value string) {
+	if strategy, ok := e.r.keyStrategy(key); ok {
+		e.ObjectEncoder.AddString(key, mask(value, strategy))
+		return
+	}
+	e.ObjectEncoder.AddString(key, e.r.redactString(value))
+}
+
+func (e *redactObjectEncoder) AddByteString(key string, value []byte) {
+	e.AddString(key, string(value))
+}
+
+func (e *redactObjectEncoder) AddReflected(key string, value any) error {
+	if e.maskKey(key, value) {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return e.ObjectEncoder.AddReflected(key, value)
+	}
+	if redacted := e.r.redactString(string(data)); redacted != string(data) {
+		return e.ObjectEncoder.AddReflected(key, json.RawMessage(redacted))
+	}
+	return e.ObjectEncoder.AddReflected(key, value)
+}
+
+// maskKey writes the masked value of key if a key rule covers it and reports
+// whether it did. Values of any type are masked, e.g. a card number logged as
+// an int.
+func (e *redactObjectEncoder) maskKey(key string, value any) bool {
+	strategy, ok := e.r.keyStrategy(key)
+	if ok {
+		e.ObjectEncoder.AddString(key, mask(fmt.Sprint(value), strategy))
+	}
+	return ok
+}
+
+func (e *redactObjectEncoder) AddBinary(key string, value []byte) {
+	if !e.maskKey(key, value) {
+		e.ObjectEncoder.AddBinary(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddBool(key string, value bool) {
+	if !e.maskKey(key, value) {
+		e.ObjectEncoder.AddBool(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddComplex128(key string, value complex128) {
+	if !e.maskKey(key, value) {
+		e.ObjectEncoder.AddComplex128(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddComplex64(key string, value complex64) {
+	if !e.maskKey(key, value) {
+		e.ObjectEncoder.AddComplex64(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddDuration(key string, value time.Duration) {
+	if !e.maskKey(key, value) {
+		e.ObjectEncoder.AddDuration(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddFloat64(key string, value float64) {
+	if !e.maskKey(key, value) {
+		e.ObjectEncoder.AddFloat64(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddFloat32(key string, value float32) {
+	if !e.maskKey(key, value) {
+		e.ObjectEncoder.AddFloat32(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddInt(key string, value int) {
+	if !e.maskKey(key, value) {
+		e.ObjectEncoder.AddInt(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddInt64(key string, value int64) {
+	if !e.maskKey(key, value) {
+		e.ObjectEncoder.AddInt64(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddInt32(key string, value int32) {
+	if !e.maskKey(key, value) {
+		e.ObjectEncoder.AddInt32(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddInt16(key string, value int16) {
+	if !e.maskKey(key, value) {
+		e.ObjectEncoder.AddInt16(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddInt8(key string, value int8) {
+	if !e.maskKey(key, value) {
+		e.ObjectEncoder.AddInt8(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddTime(key string, value time.Time) {
+	if !e.maskKey(key, value) {
+		e.ObjectEncoder.AddTime(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddUint(key string, value uint) {
+	if !e.maskKey(key, value) {
+		e.ObjectEncoder.AddUint(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddUint64(key string, value uint64) {
+	if !e.maskKey(key, value) {
+		e.ObjectEncoder.AddUint64(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddUint32(key string, value uint32) {
+	if !e.maskKey(key, value) {
+		e.ObjectEncoder.AddUint32(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddUint16(key string, value uint16) {
+	if !e.maskKey(key, value) {
+		e.ObjectEncoder.AddUint16(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddUint8(key string, value uint8) {
+	if !e.maskKey(key, value) {
+		e.ObjectEncoder.AddUint8(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddUintptr(key string, value uintptr) {
+	if !e.maskKey(key, value) {
+		e.ObjectEncoder.AddUintptr(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddObject(key string, marshaler zapcore.ObjectMarshaler) error {
+	if _, ok := e.r.keyStrategy(key); ok {
+		e.ObjectEncoder.AddString(key, redactedValue)
+		return nil
+	}
+	return e.ObjectEncoder.AddObject(key, redactedObject{r: e.r, inner: marshaler})
+}
+
+func (e *redactObjectEncoder) AddArray(key string, marshaler zapcore.ArrayMarshaler) error {
+	if _, ok := e.r.keyStrategy(key); ok {
+		e.ObjectEncoder.AddString(key, redactedValue)
+		return nil
+	}
+	return e.ObjectEncoder.AddArray(key, redactedArray{r: e.r, inner: marshaler})
+}
+
+type redactArrayEncoder struct {
+	zapcore.ArrayEncoder
+	r *redactor
+}
+
+func (e *redactArrayEncoder) AppendString(value string) {
+	e.ArrayEncoder.AppendString(e.r.redactString(value))
+}
+
+func (e *redactArrayEncoder) AppendByteString(value []byte) {
+	e.AppendString(string(value))
+}
+
+func (e *redactArrayEncoder) AppendReflected(value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return e.ArrayEncoder.AppendReflected(value)
+	}
+	if redacted := e.r.redactString(string(data)); redacted != string(data) {
+		return e.ArrayEncoder.AppendReflected(json.RawMessage(redacted))
+	}
+	return e.ArrayEncoder.AppendReflected(value)
+}
+
+func (e *redactArrayEncoder) AppendObject(marshaler zapcore.ObjectMarshaler) error {
+	return e.ArrayEncoder.AppendObject(redactedObject{r: e.r, inner: marshaler})
+}
+
+func (e *redactArrayEncoder) AppendArray(marshaler zapcore.ArrayMarshaler) error {
+	return e.ArrayEncoder.AppendArray(redactedArray{r: e.r, inner: marshaler})
+}
+
+// redactRules holds the redactor of a Logger so that Reload can replace
+// it. A nil redactor masks nothing.
+type redactRules struct {
+	atomic.Pointer[redactor]
+}
+
+// redactCore masks the message and fields of every entry before passing it
+// to the wrapped core.
+type redactCore struct {
+	zapcore.Core
+	rules *redactRules
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	if r := c.rules.Load(); r != nil {
+		fields = r.redactFields(fields)
+	}
+	return &redactCore{Core: c.Core.With(fields), rules: c.rules}
+}
+
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Core.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	r := c.rules.Load()
+	if r != nil {
+		ent.Message = r.redactString(ent.Message)
+	}
+	// 내부 core의 레벨 판단을 그대로 따르도록 다시 Check 한다.
+	if ce := c.Core.Check(ent, nil); ce != nil {
+		if r != nil {
+			fields = r.redactFields(fields)
+		}
+		ce.Write(fields...)
+	}
+	return nil
+}
+
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		ch := s[i]
+		if ch == ' ' || ch == '-' {
+			continue
+		}
+		if ch < '0' || ch > '9' {
+			return false
+		}
+		d := int(ch - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
@@ -0,0 +1,143 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// reloader reads the Config of a Logger again and applies the settings that
+// can change without rebuilding its cores.
+type reloader struct {
+	path      string
+	fromEnv   bool
+	levels    *levelState
+	sampler   *sampler
+	redaction *redactRules
+
+	mu sync.Mutex
+	// level and named are the values of the last read. They are applied only
+	// when they change so that runtime overrides survive unrelated reloads.
+	level zapcore.Level
+	named map[string]zapcore.Level
+	// modTime and size are the state of the file at the last poll.
+	modTime time.Time
+	size    int64
+}
+
+func newReloader(cfg Config, levels *levelState, s *sampler, redaction *redactRules) *reloader {
+	r := &reloader{
+		path:      cfg.configFile,
+		fromEnv:   cfg.fromEnv,
+		levels:    levels,
+		sampler:   s,
+		redaction: redaction,
+		level:     cfg.Level,
+		named:     cfg.NamedLevels,
+	}
+	r.changed()
+	return r
+}
+
+// Reload reads the config file of the global logger again. See
+// Logger.Reload.
+func Reload() error {
+	return L().Reload()
+}
+
+// Reload reads the file of a Logger created from ConfigFromFile or
+// ConfigFromEnv again, as a change of the file or SIGHUP does, and applies
+// its level, named levels, sampling and redaction. The other settings need a
+// new Logger. The level and named levels are only applied when they differ
+// from the previous read, so a level changed with SetLevel or LevelHandler
+// is kept until the file changes it. On error the current settings are
+// kept.
+func (l *Logger) Reload() error {
+	if l.reload == nil {
+		return errors.New("log: the logger has no config file to reload")
+	}
+	return l.reload.reload()
+}
+
+func (r *reloader) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var cfg Config
+	var err error
+	if r.fromEnv {
+		cfg, err = ConfigFromEnv()
+	} else {
+		cfg, err = ConfigFromFile(r.path)
+	}
+	if err != nil {
+		return err
+	}
+	if err := checkConfig(&cfg); err != nil {
+		return err
+	}
+
+	if cfg.Level != r.level {
+		r.levels.resetBase(cfg.Level)
+		r.level = cfg.Level
+	}
+	if !maps.Equal(cfg.NamedLevels, r.named) {
+		r.levels.resetNamed(cfg.NamedLevels)
+		r.named = cfg.NamedLevels
+	}
+	r.sampler.update(cfg.Sampling)
+	r.redaction.Store(newRedactor(cfg.Redaction))
+	return nil
+}
+
+// changed reports whether the file was modified since the last call.
+func (r *reloader) changed() bool {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		// 교체 중에 잠시 없는 파일은 다음 확인에서 다시 본다.
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if info.ModTime().Equal(r.modTime) && info.Size() == r.size {
+		return false
+	}
+	r.modTime, r.size = info.ModTime(), info.Size()
+	return true
+}
+
+// watch reloads the file whenever it changes, checking every interval, and
+// returns a function that stops it.
+func (r *reloader) watch(interval time.Duration) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if !r.changed() {
+					continue
+				}
+				if err := r.reload(); err != nil {
+					_, _ = fmt.Fprintf(os.Stderr, "log reload failed: %v\n", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
@@ -0,0 +1,148 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// newReloadLogger returns a Logger read from a YAML file with content and
+// the path of that file. Polling is disabled unless interval is positive.
+func newReloadLogger(t *testing.T, content string, interval time.Duration) (*Logger, string) {
+	t.Helper()
+	path := writeConfigFile(t, "log.yaml", content)
+	cfg, err := ConfigFromFile(path)
+	if err != nil {
+		t.Fatalf("ConfigFromFile returned error: %v", err)
+	}
+	cfg.Stdout = new(false)
+	cfg.ReloadInterval = interval
+	l, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	return l, path
+}
+
+func rewriteConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile returned error: %v", err)
+	}
+}
+
+func waitForLoggerLevel(t *testing.T, l *Logger, want zapcore.Level) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for l.Level() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected level %v, got %v", want, l.Level())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestReloadAppliesLevelSamplingAndRedaction(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	l, path := newReloadLogger(t, "level: info\nfile: "+logPath+"\n", -1)
+
+	l.Zap().Debug("hidden")
+	l.Zap().Info("before", zap.String("phone", "010-1234-5678"))
+
+	rewriteConfigFile(t, path, `
+level: debug
+file: `+logPath+`
+sampling: {initial: 1, thereafter: 0, reportInterval: -1s}
+redaction:
+  rules:
+    - keys: [phone]
+`)
+	if err := l.Reload(); err != nil {
+		t.Fatalf("Reload returned error: %v", err)
+	}
+	if l.Level() != zapcore.DebugLevel {
+		t.Fatalf("level was not reloaded: %v", l.Level())
+	}
+	l.Zap().Debug("visible")
+	for range 3 {
+		l.Zap().Info("repeated")
+	}
+	l.Zap().Info("after", zap.String("phone", "010-1234-5678"))
+	_ = l.Close()
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read the log file: %v", err)
+	}
+	got := string(data)
+	if strings.Contains(got, "hidden") || !strings.Contains(got, "visible") {
+		t.Fatalf("unexpected debug entries: %q", got)
+	}
+	if n := strings.Count(got, "repeated"); n != 1 {
+		t.Fatalf("sampling was not reloaded, %d repeated entries", n)
+	}
+	if strings.Count(got, "010-1234-5678") != 1 || !strings.Contains(got, redactedValue) {
+		t.Fatalf("redaction was not reloaded: %q", got)
+	}
+}
+
+func TestReloadKeepsRuntimeLevelUntilFileChangesIt(t *testing.T) {
+	l, path := newReloadLogger(t, "level: info\nnamedLevels: {grpc: warn}\n", -1)
+	l.SetLevel(zapcore.DebugLevel)
+	l.SetNamedLevel("db", zapcore.ErrorLevel)
+
+	if err := l.Reload(); err != nil {
+		t.Fatalf("Reload returned error: %v", err)
+	}
+	if l.Level() != zapcore.DebugLevel || l.NamedLevel("db") != zapcore.ErrorLevel {
+		t.Fatal("an unchanged file should keep runtime levels")
+	}
+
+	rewriteConfigFile(t, path, "level: warn\nnamedLevels: {grpc: error}\n")
+	if err := l.Reload(); err != nil {
+		t.Fatalf("Reload returned error: %v", err)
+	}
+	if l.Level() != zapcore.WarnLevel {
+		t.Fatalf("unexpected level: %v", l.Level())
+	}
+	if got := l.NamedLevels(); len(got) != 1 || got["grpc"] != zapcore.ErrorLevel {
+		t.Fatalf("unexpected named levels: %v", got)
+	}
+}
+
+func TestReloadKeepsSettingsOnInvalidFile(t *testing.T) {
+	l, path := newReloadLogger(t, "level: warn\n", -1)
+	rewriteConfigFile(t, path, "level: loud\n")
+	if err := l.Reload(); err == nil {
+		t.Fatal("expected error for an invalid file")
+	}
+	if l.Level() != zapcore.WarnLevel {
+		t.Fatalf("level changed on a failed reload: %v", l.Level())
+	}
+}
+
+func TestReloadWatchesConfigFile(t *testing.T) {
+	l, path := newReloadLogger(t, "level: info\n", 10*time.Millisecond)
+	rewriteConfigFile(t, path, "level: error\n")
+	waitForLoggerLevel(t, l, zapcore.ErrorLevel)
+}
+
+func TestReloadWithoutConfigFile(t *testing.T) {
+	l, err := New(Config{Stdout: new(false)})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	defer l.Close()
+	if err := l.Reload(); err == nil {
+		t.Fatal("expected error for a logger without a config file")
+	}
+	if _, ok := l.plain.Core().(*levelCore).Core.(*samplerCore); ok {
+		t.Fatal("a logger without a config file should not sample")
+	}
+}
@@ -0,0 +1,518 @@
+package log
+
+import (
+	"cmp"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotationInterval rotates the log file at fixed time boundaries.
+type RotationInterval int
+
+const (
+	// RotateNone rotates on size (and SIGHUP) only.
+	RotateNone RotationInterval = iota
+	// RotateDaily starts a new file at midnight.
+	RotateDaily
+	// RotateHourly starts a new file every hour.
+	RotateHourly
+)
+
+const (
+	megabyte   = 1024 * 1024
+	gzipSuffix = ".gz"
+)
+
+// fileWriter is the file output of a Logger: lumberjack or rotatingFile.
+type fileWriter interface {
+	io.Writer
+	Rotate() error
+	Close() error
+}
+
+// rotatingFile writes to date-stamped files such as app-2024-05-01.log (or
+// app-2024-05-01.1.log after a size or SIGHUP rotation within the same
+// period) and keeps a symlink at the configured path pointing to the current
+// one. Old files are compressed and removed by age, count and total size.
+type rotatingFile struct {
+	link     string
+	dir      string
+	prefix   string
+	ext      string
+	interval RotationInterval
+	maxSize  int64
+	maxAge   time.Duration
+	backups  int
+	maxTotal int64
+	compress bool
+	local    bool
+	mode     os.FileMode
+	now      func() time.Time
+
+	mu        sync.Mutex
+	file      *os.File
+	name      string
+	size      int64
+	periodEnd time.Time
+
+	millCh   chan struct{}
+	millDone chan struct{}
+}
+
+func newRotatingFile(path string, rotation RotationConfig, mode os.FileMode) *rotatingFile {
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	r := &rotatingFile{
+		link:     path,
+		dir:      filepath.Dir(path),
+		prefix:   strings.TrimSuffix(base, ext) + "-",
+		ext:      ext,
+		interval: rotation.Interval,
+		maxSize:  int64(rotation.MaxSize) * megabyte,
+		maxAge:   time.Duration(rotation.MaxAge) * 24 * time.Hour,
+		backups:  rotation.MaxBackups,
+		maxTotal: int64(rotation.MaxTotalSize) * megabyte,
+		compress: *rotation.Compress,
+		local:    *rotation.LocalTime,
+		mode:     mode,
+		now:      time.Now,
+		millCh:   make(chan struct{}, 1),
+		millDone: make(chan struct{}),
+	}
+	go r.millRun(r.millCh)
+	return r
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.currentTime()
+	switch {
+	case r.file == nil:
+		if err := r.open(now, false); err != nil {
+			return 0, err
+		}
+	case !r.periodEnd.IsZero() && !now.Before(r.periodEnd):
+		if err := r.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Rotate starts a new file, as SIGHUP does.
+func (r *rotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rotate(r.currentTime())
+}
+
+func (r *rotatingFile) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	return r.file.Sync()
+}
+
+// Close closes the current file and waits for pending compression and
+// cleanup.
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	err := r.closeFile()
+	ch := r.millCh
+	r.millCh = nil
+	r.mu.Unlock()
+
+	if ch != nil {
+		close(ch)
+		<-r.millDone
+	}
+	return err
+}
+
+func (r *rotatingFile) currentTime() time.Time {
+	if r.local {
+		return r.now()
+	}
+	return r.now().UTC()
+}
+
+func (r *rotatingFile) rotate(now time.Time) error {
+	if err := r.closeFile(); err != nil {
+		return err
+	}
+	return r.open(now, true)
+}
+
+func (r *rotatingFile) closeFile() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// open opens the file of the period of now. Without force it appends to the
+// latest file of the period if it still has room, e.g. after a restart.
+func (r *rotatingFile) open(now time.Time, force bool) error {
+	if err := r.adoptRegularFile(); err != nil {
+		return err
+	}
+
+	stamp := r.stamp(now)
+	index := r.lastIndex(stamp)
+	if index >= 0 && !force {
+		name := r.fileName(stamp, index)
+		if info, err := os.Stat(name); err == nil && info.Size() < r.maxSize {
+			return r.openFile(name, info.Size(), now)
+		}
+	}
+	return r.openFile(r.fileName(stamp, index+1), 0, now)
+}
+
+func (r *rotatingFile) openFile(name string, size int64, now time.Time) error {
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, r.mode)
+	if err != nil {
+		return err
+	}
+	if size == 0 {
+		// umask 영향을 받지 않도록 생성 직후 권한을 다시 지정한다.
+		if err := f.Chmod(r.mode); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+
+	r.file = f
+	r.name = name
+	r.size = size
+	r.periodEnd = r.nextBoundary(now)
+	r.updateLink()
+	// 첫 open에서도 정리해야 이전 프로세스가 남긴 파일이 압축되고 용량 제한에 포함된다.
+	if r.millCh != nil {
+		select {
+		case r.millCh <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// updateLink points the link at the current file. Failures (e.g. platforms
+// without symlinks) only disable the link.
+func (r *rotatingFile) updateLink() {
+	tmp := r.link + ".tmp"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(filepath.Base(r.name), tmp); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "log symlink failed: %v\n", err)
+		return
+	}
+	if err := os.Rename(tmp, r.link); err != nil {
+		_ = os.Remove(tmp)
+		_, _ = fmt.Fprintf(os.Stderr, "log symlink failed: %v\n", err)
+	}
+}
+
+// adoptRegularFile moves a plain file left at the link path (e.g. written by
+// lumberjack before) to a stamped name so that the link does not replace it.
+func (r *rotatingFile) adoptRegularFile() error {
+	info, err := os.Lstat(r.link)
+	if err != nil || !info.Mode().IsRegular() {
+		return nil
+	}
+	if info.Size() == 0 {
+		return os.Remove(r.link)
+	}
+	modTime := info.ModTime()
+	if !r.local {
+		modTime = modTime.UTC()
+	}
+	stamp := r.stamp(modTime)
+	return os.Rename(r.link, r.fileName(stamp, r.lastIndex(stamp)+1))
+}
+
+func (r *rotatingFile) stamp(t time.Time) string {
+	switch r.interval {
+	case RotateDaily:
+		return t.Format("2006-01-02")
+	case RotateHourly:
+		return t.Format("2006-01-02T15")
+	default:
+		return t.Format("2006-01-02T15-04-05")
+	}
+}
+
+func (r *rotatingFile) nextBoundary(t time.Time) time.Time {
+	switch r.interval {
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+	default:
+		return time.Time{}
+	}
+}
+
+// fileName returns the name of the index-th file of a period: the first has
+// no index.
+func (r *rotatingFile) fileName(stamp string, index int) string {
+	if index <= 0 {
+		return filepath.Join(r.dir, r.prefix+stamp+r.ext)
+	}
+	return filepath.Join(r.dir, r.prefix+stamp+"."+strconv.Itoa(index)+r.ext)
+}
+
+// lastIndex returns the highest index used by the files of stamp, or -1.
+// Every file is checked, as an archive in the middle may have been removed.
+func (r *rotatingFile) lastIndex(stamp string) int {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return -1
+	}
+
+	last := -1
+	for _, entry := range entries {
+		rest, ok := strings.CutPrefix(entry.Name(), r.prefix+stamp)
+		if !ok {
+			continue
+		}
+		rest = strings.TrimSuffix(rest, gzipSuffix)
+		if rest, ok = strings.CutSuffix(rest, r.ext); !ok {
+			continue
+		}
+		if rest == "" {
+			last = max(last, 0)
+			continue
+		}
+		if index, ok := strings.CutPrefix(rest, "."); ok {
+			if n, ok := parseArchiveIndex(index); ok {
+				last = max(last, n)
+			}
+		}
+	}
+	return last
+}
+
+// parseArchiveIndex parses the index of a file name, which is positive and
+// written without leading zeros.
+func parseArchiveIndex(s string) (int, bool) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 || strconv.Itoa(n) != s {
+		return 0, false
+	}
+	return n, true
+}
+
+func (r *rotatingFile) millRun(ch <-chan struct{}) {
+	defer close(r.millDone)
+	for range ch {
+		if err := r.mill(); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "log cleanup failed: %v\n", err)
+		}
+	}
+}
+
+type archive struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+// mill compresses the archives and removes them by age, count and total
+// size, oldest first.
+func (r *rotatingFile) mill() error {
+	r.mu.Lock()
+	current := r.name
+	r.mu.Unlock()
+
+	archives, currentSize, err := r.archives(current)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	if r.compress {
+		compressed := archives[:0]
+		for _, a := range archives {
+			if strings.HasSuffix(a.name, gzipSuffix) {
+				compressed = append(compressed, a)
+				continue
+			}
+			size, err := compressFile(a.name, r.mode)
+			if err != nil {
+				errs = append(errs, err)
+				compressed = append(compressed, a)
+				continue
+			}
+			removed, err := r.removeArchive(a.name)
+			if !removed {
+				// 그 사이 현재 파일이 되었으면 원본을 두고 압축본을 버린다.
+				_ = os.Remove(a.name + gzipSuffix)
+				if err != nil {
+					errs = append(errs, err)
+				}
+				continue
+			}
+			compressed = append(compressed, archive{name: a.name + gzipSuffix, size: size, modTime: a.modTime})
+		}
+		archives = compressed
+	}
+
+	// archives는 최신순으로 정렬되어 있다.
+	total := currentSize
+	var cutoff time.Time
+	if r.maxAge > 0 {
+		cutoff = r.currentTime().Add(-r.maxAge)
+	}
+	for i, a := range archives {
+		total += a.size
+		remove := (r.maxAge > 0 && a.modTime.Before(cutoff)) ||
+			(r.backups > 0 && i >= r.backups) ||
+			(r.maxTotal > 0 && total > r.maxTotal)
+		if !remove {
+			continue
+		}
+		removed, err := r.removeArchive(a.name)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if removed {
+			total -= a.size
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// removeArchive removes name unless a rotation has made it the current file
+// since mill listed it, and reports whether name is gone.
+func (r *rotatingFile) removeArchive(name string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if name == r.name {
+		return false, nil
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	return true, nil
+}
+
+// archives returns the rotated files, newest first, and the size of current.
+func (r *rotatingFile) archives(current string) ([]archive, int64, error) {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var archives []archive
+	var currentSize int64
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || !r.isArchive(name) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(r.dir, name)
+		if path == current {
+			currentSize = info.Size()
+			continue
+		}
+		archives = append(archives, archive{name: path, size: info.Size(), modTime: info.ModTime()})
+	}
+
+	slices.SortFunc(archives, func(a, b archive) int {
+		if c := b.modTime.Compare(a.modTime); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.name, a.name)
+	})
+	return archives, currentSize, nil
+}
+
+// archiveLayouts are the stamps of every interval, so that archives written
+// before the interval was changed are still found.
+var archiveLayouts = []string{"2006-01-02", "2006-01-02T15", "2006-01-02T15-04-05"}
+
+// isArchive reports whether name is prefix, a stamp, an optional index and
+// ext, optionally compressed. A sibling such as app-error.log of app.log is
+// not.
+func (r *rotatingFile) isArchive(name string) bool {
+	rest, ok := strings.CutPrefix(name, r.prefix)
+	if !ok {
+		return false
+	}
+	rest = strings.TrimSuffix(rest, gzipSuffix)
+	if rest, ok = strings.CutSuffix(rest, r.ext); !ok {
+		return false
+	}
+	stamp, index, indexed := strings.Cut(rest, ".")
+	if indexed {
+		if _, ok := parseArchiveIndex(index); !ok {
+			return false
+		}
+	}
+	for _, layout := range archiveLayouts {
+		if _, err := time.Parse(layout, stamp); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// compressFile gzips name to name.gz and returns its size. The caller removes
+// name.
+func compressFile(name string, mode os.FileMode) (int64, error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	dst, err := os.OpenFile(name+gzipSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return 0, err
+	}
+	gz := gzip.NewWriter(dst)
+	_, copyErr := io.Copy(gz, src)
+	if err := errors.Join(copyErr, gz.Close(), dst.Chmod(mode), dst.Close()); err != nil {
+		_ = os.Remove(name + gzipSuffix)
+		return 0, err
+	}
+	// 원본 수정 시각을 유지해야 보관 기간과 정렬이 어긋나지 않는다.
+	_ = os.Chtimes(name+gzipSuffix, info.ModTime(), info.ModTime())
+
+	stat, err := os.Stat(name + gzipSuffix)
+	if err != nil {
+		return 0, err
+	}
+	return stat.Size(), nil
+}
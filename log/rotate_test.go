@@ -0,0 +1,255 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newRotatingFileForTest(t *testing.T, rotation RotationConfig, now *time.Time) (*rotatingFile, string) {
+	t.Helper()
+	dir := t.TempDir()
+	if rotation.Compress == nil {
+		rotation.Compress = new(false)
+	}
+	rotation.LocalTime = new(false)
+	if rotation.MaxSize == 0 {
+		rotation.MaxSize = defaultMaxSize
+	}
+	r := newRotatingFile(filepath.Join(dir, "app.log"), rotation, 0o600)
+	r.now = func() time.Time { return *now }
+	t.Cleanup(func() { _ = r.Close() })
+	return r, dir
+}
+
+func dirEntries(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read dir: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestRotatingFileDailyWithCurrentLink(t *testing.T) {
+	now := time.Date(2024, 5, 1, 23, 59, 0, 0, time.UTC)
+	r, dir := newRotatingFileForTest(t, RotationConfig{Interval: RotateDaily}, &now)
+
+	_, _ = r.Write([]byte("first\n"))
+	now = now.Add(2 * time.Minute)
+	_, _ = r.Write([]byte("second\n"))
+
+	want := []string{"app-2024-05-01.log", "app-2024-05-02.log", "app.log"}
+	if got := dirEntries(t, dir); !slices.Equal(got, want) {
+		t.Fatalf("unexpected files: %v", got)
+	}
+	target, err := os.Readlink(filepath.Join(dir, "app.log"))
+	if err != nil || target != "app-2024-05-02.log" {
+		t.Fatalf("current link should point to the new file: %q (%v)", target, err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "app.log"))
+	if string(data) != "second\n" {
+		t.Fatalf("unexpected current content: %q", data)
+	}
+}
+
+func TestRotatingFileHourlySizeAndManualRotation(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	r, dir := newRotatingFileForTest(t, RotationConfig{Interval: RotateHourly}, &now)
+	r.maxSize = 10
+
+	_, _ = r.Write([]byte("0123456\n"))
+	_, _ = r.Write([]byte("0123456\n")) // size limit
+	if err := r.Rotate(); err != nil {
+		t.Fatalf("Rotate returned error: %v", err)
+	}
+	_, _ = r.Write([]byte("x\n"))
+
+	want := []string{"app-2024-05-01T10.1.log", "app-2024-05-01T10.2.log", "app-2024-05-01T10.log", "app.log"}
+	if got := dirEntries(t, dir); !slices.Equal(got, want) {
+		t.Fatalf("unexpected files: %v", got)
+	}
+}
+
+func TestRotatingFileAppendsAfterRestart(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	r, dir := newRotatingFileForTest(t, RotationConfig{Interval: RotateDaily}, &now)
+	_, _ = r.Write([]byte("before\n"))
+	_ = r.Close()
+
+	r2 := newRotatingFile(filepath.Join(dir, "app.log"), RotationConfig{Interval: RotateDaily, MaxSize: 1, Compress: new(false), LocalTime: new(false)}, 0o600)
+	r2.now = func() time.Time { return now }
+	defer r2.Close()
+	_, _ = r2.Write([]byte("after\n"))
+
+	data, _ := os.ReadFile(filepath.Join(dir, "app-2024-05-01.log"))
+	if string(data) != "before\nafter\n" {
+		t.Fatalf("restart should append to the file of the period: %q", data)
+	}
+}
+
+func TestRotatingFileSkipsIndexesAfterRemovedArchive(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	r, dir := newRotatingFileForTest(t, RotationConfig{Interval: RotateDaily}, &now)
+	// .1 archive was removed, so the indexes have a gap.
+	for _, name := range []string{"app-2024-05-01.log", "app-2024-05-01.2.log.gz"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("old\n"), 0o600); err != nil {
+			t.Fatalf("failed to write archive: %v", err)
+		}
+	}
+
+	if err := r.Rotate(); err != nil {
+		t.Fatalf("Rotate returned error: %v", err)
+	}
+	if got := filepath.Base(r.name); got != "app-2024-05-01.3.log" {
+		t.Fatalf("existing names should not be reused: %s", got)
+	}
+}
+
+func TestRotatingFileMillsLeftoverArchivesOnOpen(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	r, dir := newRotatingFileForTest(t, RotationConfig{Interval: RotateDaily, Compress: new(true)}, &now)
+	if err := os.WriteFile(filepath.Join(dir, "app-2024-04-30.log"), []byte("old\n"), 0o600); err != nil {
+		t.Fatalf("failed to write archive: %v", err)
+	}
+
+	_, _ = r.Write([]byte("new\n"))
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	got := dirEntries(t, dir)
+	if !slices.Contains(got, "app-2024-04-30.log.gz") || slices.Contains(got, "app-2024-04-30.log") {
+		t.Fatalf("archives of a previous process should be compressed: %v", got)
+	}
+	if !slices.Contains(got, "app-2024-05-01.log") {
+		t.Fatalf("the current file should not be compressed: %v", got)
+	}
+}
+
+func TestRotatingFileAdoptsRegularFile(t *testing.T) {
+	now := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	r, dir := newRotatingFileForTest(t, RotationConfig{Interval: RotateDaily}, &now)
+
+	old := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(old, []byte("legacy\n"), 0o600); err != nil {
+		t.Fatalf("failed to write legacy file: %v", err)
+	}
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	_ = os.Chtimes(old, modTime, modTime)
+
+	_, _ = r.Write([]byte("new\n"))
+
+	data, err := os.ReadFile(filepath.Join(dir, "app-2024-05-01.log"))
+	if err != nil || string(data) != "legacy\n" {
+		t.Fatalf("legacy file should be kept as an archive: %q (%v)", data, err)
+	}
+}
+
+func TestRotatingFileMillCompressesAndCapsTotalSize(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	r, dir := newRotatingFileForTest(t, RotationConfig{Compress: new(true)}, &now)
+	r.maxAge = 0
+
+	line := []byte(strings.Repeat("a", 1000) + "\n")
+	sample := filepath.Join(t.TempDir(), "sample.log")
+	if err := os.WriteFile(sample, line, 0o600); err != nil {
+		t.Fatalf("failed to write sample: %v", err)
+	}
+	gzSize, err := compressFile(sample, 0o600)
+	if err != nil {
+		t.Fatalf("compressFile returned error: %v", err)
+	}
+	r.maxTotal = gzSize * 2
+
+	// 정리는 Rotate가 깨우는 백그라운드 goroutine에서만 돌고, Close가 끝나기를 기다린다.
+	for range 3 {
+		_, _ = r.Write(line)
+		now = now.Add(time.Second)
+		if err := r.Rotate(); err != nil {
+			t.Fatalf("Rotate returned error: %v", err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	got := dirEntries(t, dir)
+	for _, name := range got {
+		if strings.HasSuffix(name, ".log") && name != "app.log" && name != filepath.Base(r.name) {
+			t.Fatalf("archives should be compressed: %v", got)
+		}
+	}
+	if slices.Contains(got, "app-2024-05-01T00-00-00.log.gz") ||
+		!slices.Contains(got, "app-2024-05-01T00-00-01.log.gz") ||
+		!slices.Contains(got, "app-2024-05-01T00-00-02.log.gz") {
+		t.Fatalf("oldest archives should be removed first: %v", got)
+	}
+}
+
+func TestRotatingFileMillKeepsSiblingFiles(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	r, dir := newRotatingFileForTest(t, RotationConfig{Interval: RotateDaily, MaxBackups: 1}, &now)
+
+	siblings := []string{"app-error.log", "app-2024-05-01-old.log", "app-2024-05-01.x.log"}
+	for _, name := range siblings {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("other\n"), 0o600); err != nil {
+			t.Fatalf("failed to write sibling: %v", err)
+		}
+	}
+	for range 3 {
+		_, _ = r.Write([]byte("line\n"))
+		if err := r.Rotate(); err != nil {
+			t.Fatalf("Rotate returned error: %v", err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	got := dirEntries(t, dir)
+	for _, name := range siblings {
+		if !slices.Contains(got, name) {
+			t.Fatalf("%s is not an archive and should be kept: %v", name, got)
+		}
+	}
+	if slices.Contains(got, "app-2024-05-01.log") || slices.Contains(got, "app-2024-05-01.1.log") ||
+		!slices.Contains(got, "app-2024-05-01.2.log") {
+		t.Fatalf("archives beyond MaxBackups should be removed: %v", got)
+	}
+}
+
+func TestNewWithIntervalUsesRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	l, err := New(Config{Stdout: new(false), FilePath: path, Rotation: RotationConfig{Interval: RotateDaily}})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	l.Zap().Info("hello")
+	if err := l.Rotate(); err != nil {
+		t.Fatalf("Rotate returned error: %v", err)
+	}
+	l.Zap().Info("after rotate")
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("FilePath should be a symlink: %v (%v)", info, err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "after rotate") || strings.Contains(string(data), `"hello"`) {
+		t.Fatalf("unexpected current content: %s", data)
+	}
+
+	if _, err := New(Config{Stdout: new(false), Rotation: RotationConfig{Interval: RotationInterval(9)}}); err == nil {
+		t.Fatal("expected error for unknown interval")
+	}
+}
@@ -0,0 +1,364 @@
+package log
+
+import (
+	"hash/fnv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultSamplingTick           = time.Second
+	defaultSamplingInitial        = 100
+	defaultSamplingThereafter     = 100
+	defaultSamplingReportInterval = time.Minute
+
+	// samplingLoggerName is the name of the logger that reports dropped
+	// entries. Its entries are never sampled.
+	samplingLoggerName = "log.sampling"
+	// maxSamplingKeys bounds the per-message counters of one tick. Once it is
+	// reached, new messages share a per-level counter.
+	maxSamplingKeys = 4096
+)
+
+// SamplingConfig limits how many entries with the same level and message are
+// written per tick. Entries at DPanic level and above are never dropped.
+type SamplingConfig struct {
+	// Tick is the interval the counters are reset after. Defaults to 1s.
+	Tick time.Duration
+	// Initial and Thereafter are the default rule: the first Initial entries
+	// of a level and message are written each tick, then every Thereafter-th.
+	// Both zero means 100 and 100.
+	Initial    int
+	Thereafter int
+	// Levels overrides the default rule per level.
+	Levels map[zapcore.Level]SamplingRule
+	// Messages overrides the rule for entries with exactly this message. It
+	// takes precedence over Levels.
+	Messages map[string]SamplingRule
+
+	// TraceRatio keeps every entry of a fraction of the traces and drops the
+	// debug and info entries of the others, so that a sampled trace is
+	// complete. The decision is a hash of the traceId and is the same in every
+	// service using this package. A sampling decision stored in the context
+	// with WithSampled takes precedence over the hash. 0 disables the hash,
+	// but the debug and info entries of a trace decided not sampled are still
+	// dropped.
+	TraceRatio float64
+
+	// ReportInterval is how often the number of dropped entries is logged as
+	// a warning. Defaults to 1m; negative disables the report.
+	ReportInterval time.Duration
+}
+
+// SamplingRule writes the first Initial entries per tick and then every
+// Thereafter-th. Thereafter <= 0 drops the rest; a negative Initial keeps
+// every entry.
+type SamplingRule struct {
+	Initial    int
+	Thereafter int
+}
+
+func checkSamplingConfig(cfg *SamplingConfig) {
+	if cfg.Tick <= 0 {
+		cfg.Tick = defaultSamplingTick
+	}
+	if cfg.Initial == 0 && cfg.Thereafter == 0 {
+		cfg.Initial = defaultSamplingInitial
+		cfg.Thereafter = defaultSamplingThereafter
+	}
+	if cfg.ReportInterval == 0 {
+		cfg.ReportInterval = defaultSamplingReportInterval
+	}
+}
+
+type samplingKey struct {
+	level   zapcore.Level
+	message string
+}
+
+// samplingRules is the part of a sampler that Reload replaces.
+type samplingRules struct {
+	tick           time.Duration
+	rule           SamplingRule
+	levels         map[zapcore.Level]SamplingRule
+	messages       map[string]SamplingRule
+	traceThreshold uint64
+}
+
+// sampler holds the counters of one Logger.
+type sampler struct {
+	// rules is nil while sampling is disabled by a reload.
+	rules atomic.Pointer[samplingRules]
+
+	mu          sync.Mutex
+	windowStart time.Time
+	counts      map[samplingKey]int
+
+	dropped [zapcore.FatalLevel - zapcore.DebugLevel + 1]atomic.Uint64
+}
+
+func newSampler(cfg *SamplingConfig) *sampler {
+	if cfg == nil {
+		return nil
+	}
+
+	s := &sampler{counts: make(map[samplingKey]int)}
+	s.update(cfg)
+	return s
+}
+
+// update replaces the rules of s with cfg. A nil cfg keeps every entry.
+func (s *sampler) update(cfg *SamplingConfig) {
+	if cfg == nil {
+		s.rules.Store(nil)
+		return
+	}
+
+	rules := &samplingRules{
+		tick:     cfg.Tick,
+		rule:     SamplingRule{Initial: cfg.Initial, Thereafter: cfg.Thereafter},
+		levels:   cfg.Levels,
+		messages: cfg.Messages,
+	}
+	if cfg.TraceRatio > 0 && cfg.TraceRatio < 1 {
+		rules.traceThreshold = traceRatioThreshold(cfg.TraceRatio)
+	}
+	s.rules.Store(rules)
+}
+
+func (r *samplingRules) ruleFor(ent zapcore.Entry) SamplingRule {
+	if rule, ok := r.messages[ent.Message]; ok {
+		return rule
+	}
+	if rule, ok := r.levels[ent.Level]; ok {
+		return rule
+	}
+	return r.rule
+}
+
+// allow counts ent and reports whether it is within its rule.
+func (s *sampler) allow(ent zapcore.Entry) bool {
+	rules := s.rules.Load()
+	if rules == nil {
+		return true
+	}
+	rule := rules.ruleFor(ent)
+	if rule.Initial < 0 {
+		return true
+	}
+
+	now := ent.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	s.mu.Lock()
+	if now.Sub(s.windowStart) >= rules.tick || now.Before(s.windowStart) {
+		s.windowStart = now
+		clear(s.counts)
+	}
+	key := samplingKey{level: ent.Level, message: ent.Message}
+	if _, ok := s.counts[key]; !ok && len(s.counts) >= maxSamplingKeys {
+		key.message = ""
+	}
+	s.counts[key]++
+	n := s.counts[key]
+	s.mu.Unlock()
+
+	if n <= rule.Initial {
+		return true
+	}
+	return rule.Thereafter > 0 && (n-rule.Initial)%rule.Thereafter == 0
+}
+
+// traceSampled reports whether the entries of traceID are kept. It is only
+// meaningful when trace sampling is enabled.
+func (s *sampler) traceSampled(traceID string) bool {
+	rules := s.rules.Load()
+	if rules == nil {
+		return true
+	}
+	return traceHash(traceID) < rules.traceThreshold
+}
+
+// TraceRatioSampled reports whether traceID is among the given ratio of
+// traces, with the hash TraceRatio uses. Ratio sampling of spans relies on
+// it so that logs and spans keep the same traces.
+func TraceRatioSampled(traceID string, ratio float64) bool {
+	if ratio >= 1 {
+		return true
+	}
+	if ratio <= 0 {
+		return false
+	}
+	return traceHash(traceID) < traceRatioThreshold(ratio)
+}
+
+func traceRatioThreshold(ratio float64) uint64 {
+	return uint64(ratio * (1 << 32))
+}
+
+// traceHash maps traceID to [0, 2^32).
+func traceHash(traceID string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(traceID))
+	return h.Sum64() & 0xffffffff
+}
+
+func (s *sampler) drop(lvl zapcore.Level) {
+	if idx := int(lvl - zapcore.DebugLevel); idx >= 0 && idx < len(s.dropped) {
+		s.dropped[idx].Add(1)
+	}
+}
+
+// report logs and resets the dropped counters.
+func (s *sampler) report(logger *zap.Logger) {
+	var total uint64
+	counts := make(map[zapcore.Level]uint64)
+	for i := range s.dropped {
+		if n := s.dropped[i].Swap(0); n > 0 {
+			counts[zapcore.DebugLevel+zapcore.Level(i)] = n
+			total += n
+		}
+	}
+	if total == 0 {
+		return
+	}
+
+	logger.Warn("log entries dropped by sampling",
+		zap.Uint64("dropped", total),
+		zap.Object("levels", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			for lvl := zapcore.DebugLevel; lvl <= zapcore.FatalLevel; lvl++ {
+				if n, ok := counts[lvl]; ok {
+					enc.AddUint64(lvl.String(), n)
+				}
+			}
+			return nil
+		})),
+	)
+}
+
+// startReporter reports the dropped entries every interval and returns a
+// function that stops it after a final report.
+func (s *sampler) startReporter(logger *zap.Logger, interval time.Duration) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.report(logger)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+		s.report(logger)
+	}
+}
+
+// samplerCore drops the entries rejected by the sampler. The decision is made
+// in Write because trace sampling needs the traceId field.
+type samplerCore struct {
+	zapcore.Core
+	s       *sampler
+	traceID string
+	// sampled is the decision found by With, if any.
+	sampled *bool
+}
+
+func newSamplerCore(core zapcore.Core, s *sampler) zapcore.Core {
+	if s == nil {
+		return core
+	}
+	return &samplerCore{Core: core, s: s}
+}
+
+func (c *samplerCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &samplerCore{Core: c.Core.With(fields), s: c.s, traceID: c.traceID, sampled: c.sampled}
+	if id := traceIDField(fields); id != "" {
+		clone.traceID = id
+	}
+	if sampled, ok := sampledField(fields); ok {
+		clone.sampled = &sampled
+	}
+	return clone
+}
+
+func (c *samplerCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Core.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *samplerCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if !c.keep(ent, fields) {
+		c.s.drop(ent.Level)
+		return nil
+	}
+	if ce := c.Core.Check(ent, nil); ce != nil {
+		ce.Write(fields...)
+	}
+	return nil
+}
+
+func (c *samplerCore) keep(ent zapcore.Entry, fields []zapcore.Field) bool {
+	if ent.Level >= zapcore.DPanicLevel || ent.LoggerName == samplingLoggerName {
+		return true
+	}
+
+	rules := c.s.rules.Load()
+	if rules == nil {
+		return true
+	}
+	sampled, decided := sampledField(fields)
+	if !decided && c.sampled != nil {
+		sampled, decided = *c.sampled, true
+	}
+	if !decided && rules.traceThreshold > 0 {
+		traceID := traceIDField(fields)
+		if traceID == "" {
+			traceID = c.traceID
+		}
+		if traceID != "" {
+			sampled, decided = c.s.traceSampled(traceID), true
+		}
+	}
+	// 샘플링된 trace는 trace 샘플링을 켰을 때만 요청 단위로 온전히 남긴다.
+	// 그렇지 않으면 모든 요청이 샘플링되어 메시지별 규칙이 무의미해진다.
+	if decided && sampled && rules.traceThreshold > 0 {
+		return true
+	}
+	if decided && !sampled && ent.Level < zapcore.WarnLevel {
+		return false
+	}
+	return c.s.allow(ent)
+}
+
+// traceIDField returns the traceId of fields, or "" when there is none.
+// FromContext writes Unknown for a context without a trace, which is not a
+// trace to sample by.
+func traceIDField(fields []zapcore.Field) string {
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i].Key == traceFieldName && fields[i].Type == zapcore.StringType {
+			if id := strings.TrimSpace(fields[i].String); id != Unknown {
+				return id
+			}
+			return ""
+		}
+	}
+	return ""
+}
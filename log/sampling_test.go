@@ -0,0 +1,254 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newSampledLoggerForTest(cfg SamplingConfig) (*zap.Logger, *sampler, *observer.ObservedLogs) {
+	checkSamplingConfig(&cfg)
+	core, logs := observer.New(zapcore.DebugLevel)
+	s := newSampler(&cfg)
+	return zap.New(newSamplerCore(core, s)), s, logs
+}
+
+func TestSamplingFirstNThenEveryM(t *testing.T) {
+	logger, s, logs := newSampledLoggerForTest(SamplingConfig{Tick: time.Hour, Initial: 3, Thereafter: 5})
+
+	for range 20 {
+		logger.Error("db down")
+	}
+	logger.Error("other message")
+
+	// 1,2,3 + 8,13,18 + other message
+	if got := logs.FilterMessage("db down").Len(); got != 6 {
+		t.Fatalf("unexpected kept entries: %d", got)
+	}
+	if got := logs.FilterMessage("other message").Len(); got != 1 {
+		t.Fatalf("messages should be counted separately: %d", got)
+	}
+	if got := s.dropped[zapcore.ErrorLevel-zapcore.DebugLevel].Load(); got != 14 {
+		t.Fatalf("unexpected dropped count: %d", got)
+	}
+}
+
+func TestSamplingResetsEveryTick(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	cfg := SamplingConfig{Tick: time.Second, Initial: 1, Thereafter: -1}
+	checkSamplingConfig(&cfg)
+	c := newSamplerCore(core, newSampler(&cfg))
+
+	start := time.Now()
+	for _, offset := range []time.Duration{0, 100 * time.Millisecond, 1100 * time.Millisecond} {
+		ent := zapcore.Entry{Level: zapcore.InfoLevel, Message: "tick", Time: start.Add(offset)}
+		if ce := c.Check(ent, nil); ce != nil {
+			ce.Write()
+		}
+	}
+	if got := logs.Len(); got != 2 {
+		t.Fatalf("expected one entry per tick, got: %d", got)
+	}
+}
+
+func TestSamplingLevelAndMessageRules(t *testing.T) {
+	logger, _, logs := newSampledLoggerForTest(SamplingConfig{
+		Tick:       time.Hour,
+		Initial:    1,
+		Thereafter: 0,
+		Levels:     map[zapcore.Level]SamplingRule{zapcore.WarnLevel: {Initial: -1}},
+		Messages:   map[string]SamplingRule{"heartbeat": {Initial: 2}},
+	})
+
+	for range 5 {
+		logger.Info("request")
+		logger.Warn("slow")
+		logger.Info("heartbeat")
+	}
+
+	if got := logs.FilterMessage("request").Len(); got != 1 {
+		t.Fatalf("default rule not applied: %d", got)
+	}
+	if got := logs.FilterMessage("slow").Len(); got != 5 {
+		t.Fatalf("level rule not applied: %d", got)
+	}
+	if got := logs.FilterMessage("heartbeat").Len(); got != 2 {
+		t.Fatalf("message rule not applied: %d", got)
+	}
+}
+
+func TestSamplingLimitsDistinctMessages(t *testing.T) {
+	logger, s, _ := newSampledLoggerForTest(SamplingConfig{Tick: time.Hour, Initial: 1})
+
+	for i := range maxSamplingKeys + 10 {
+		logger.Info(fmt.Sprintf("user %d not found", i))
+	}
+	if got := len(s.counts); got > maxSamplingKeys+1 {
+		t.Fatalf("counters should be bounded, got: %d", got)
+	}
+}
+
+func TestTraceSamplingKeepsWholeTraces(t *testing.T) {
+	logger, s, logs := newSampledLoggerForTest(SamplingConfig{Tick: time.Hour, Initial: 1, TraceRatio: 0.5})
+
+	var sampled, unsampled string
+	for i := 0; sampled == "" || unsampled == ""; i++ {
+		id := fmt.Sprintf("trace-%d", i)
+		if s.traceSampled(id) {
+			sampled = id
+		} else {
+			unsampled = id
+		}
+	}
+
+	for range 3 {
+		logger.Debug("step", zap.String(traceFieldName, sampled))
+		logger.With(zap.String(traceFieldName, unsampled)).Info("step")
+	}
+	logger.Error("failed", zap.String(traceFieldName, unsampled))
+
+	kept := map[string]int{}
+	for _, entry := range logs.All() {
+		kept[entry.ContextMap()[traceFieldName].(string)]++
+	}
+	if kept[sampled] != 3 {
+		t.Fatalf("sampled trace should be complete, got: %d", kept[sampled])
+	}
+	if kept[unsampled] != 1 || logs.FilterMessage("failed").Len() != 1 {
+		t.Fatalf("only warnings of unsampled traces should be kept, got: %v", kept)
+	}
+	if s.traceSampled(sampled) != newSampler(&SamplingConfig{TraceRatio: 0.5}).traceSampled(sampled) {
+		t.Fatal("trace decision should be deterministic")
+	}
+}
+
+func TestTraceSamplingKeepsEntriesWithoutTrace(t *testing.T) {
+	// "unknown"을 traceId로 해시하면 0.5 비율에서 버려진다.
+	if TraceRatioSampled(Unknown, 0.5) {
+		t.Fatal("the test needs a ratio that would drop the Unknown trace")
+	}
+	logger, _, logs := newSampledLoggerForTest(SamplingConfig{Tick: time.Hour, Initial: 10, TraceRatio: 0.5})
+
+	logger.Info("no trace", FromContext(context.Background())...)
+	logger.With(FromContext(context.Background())...).Debug("no trace either")
+	logger.Info("no fields")
+
+	if got := logs.Len(); got != 3 {
+		t.Fatalf("entries without a trace should only follow the per-message rules, got: %d", got)
+	}
+}
+
+func TestTraceSamplingFollowsContextDecision(t *testing.T) {
+	logger, s, logs := newSampledLoggerForTest(SamplingConfig{Tick: time.Hour, Initial: 1, TraceRatio: 0.5})
+
+	var sampled, unsampled string
+	for i := 0; sampled == "" || unsampled == ""; i++ {
+		id := fmt.Sprintf("trace-%d", i)
+		if s.traceSampled(id) {
+			sampled = id
+		} else {
+			unsampled = id
+		}
+	}
+
+	// context의 결정이 traceId 해시보다 우선한다.
+	keep := WithSampled(WithTraceID(context.Background(), unsampled), true)
+	drop := WithSampled(WithTraceID(context.Background(), sampled), false)
+	for range 3 {
+		logger.Debug("step", FromContext(keep)...)
+		logger.With(FromContext(drop)...).Info("step")
+	}
+	logger.Warn("slow", FromContext(drop)...)
+
+	kept := map[string]int{}
+	for _, entry := range logs.All() {
+		kept[entry.ContextMap()[traceFieldName].(string)]++
+	}
+	if kept[unsampled] != 3 {
+		t.Fatalf("a sampled decision should keep the whole trace, got: %d", kept[unsampled])
+	}
+	if kept[sampled] != 1 || logs.FilterMessage("slow").Len() != 1 {
+		t.Fatalf("only warnings of a trace that is not sampled should be kept, got: %v", kept)
+	}
+}
+
+func TestSamplingFollowsUnsampledDecisionWithoutTraceRatio(t *testing.T) {
+	logger, _, logs := newSampledLoggerForTest(SamplingConfig{Tick: time.Hour, Initial: 10})
+
+	keep := WithSampled(WithTraceID(context.Background(), "trace-1"), true)
+	drop := WithSampled(WithTraceID(context.Background(), "trace-2"), false)
+	logger.Info("step", FromContext(keep)...)
+	logger.Info("step", FromContext(drop)...)
+	logger.Warn("slow", FromContext(drop)...)
+
+	if logs.FilterMessage("step").Len() != 1 || logs.FilterMessage("slow").Len() != 1 {
+		t.Fatalf("only warnings of a trace that is not sampled should be kept: %v", logs.All())
+	}
+	if got := logs.FilterMessage("step").All()[0].ContextMap()[traceFieldName]; got != "trace-1" {
+		t.Fatalf("the sampled trace should be kept, got: %v", got)
+	}
+}
+
+func TestTraceRatioSampled(t *testing.T) {
+	s := newSampler(&SamplingConfig{TraceRatio: 0.3})
+	for i := range 100 {
+		id := fmt.Sprintf("trace-%d", i)
+		if TraceRatioSampled(id, 0.3) != s.traceSampled(id) {
+			t.Fatalf("TraceRatioSampled should match TraceRatio for %s", id)
+		}
+	}
+	if TraceRatioSampled("t", 0) || !TraceRatioSampled("t", 1) {
+		t.Fatal("ratios 0 and 1 should never and always sample")
+	}
+}
+
+func TestSamplingReportsDroppedEntries(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	cfg := SamplingConfig{Tick: time.Hour, Initial: 1, ReportInterval: time.Hour}
+	checkSamplingConfig(&cfg)
+	s := newSampler(&cfg)
+	logger := zap.New(newSamplerCore(core, s))
+	stop := s.startReporter(logger.Named(samplingLoggerName), cfg.ReportInterval)
+
+	for range 4 {
+		logger.Info("spam")
+		logger.Error("spam")
+	}
+	stop()
+
+	reports := logs.FilterMessage("log entries dropped by sampling").All()
+	if len(reports) != 1 {
+		t.Fatalf("expected one report, got: %d", len(reports))
+	}
+	fields := reports[0].ContextMap()
+	if fields["dropped"] != uint64(6) {
+		t.Fatalf("unexpected dropped count: %v", fields)
+	}
+	levels := fields["levels"].(map[string]any)
+	if levels["info"] != uint64(3) || levels["error"] != uint64(3) {
+		t.Fatalf("unexpected level counts: %v", levels)
+	}
+}
+
+func TestNewWithSamplingNeverDropsPanics(t *testing.T) {
+	l, err := New(Config{Stdout: new(false), Sampling: &SamplingConfig{Initial: 1, Thereafter: -1}})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+
+	if _, ok := l.plain.Core().(*levelCore).Core.(*samplerCore); !ok {
+		t.Fatalf("samplerCore should be installed, got: %T", l.plain.Core().(*levelCore).Core)
+	}
+	c := &samplerCore{s: newSampler(&SamplingConfig{Initial: 1})}
+	for range 3 {
+		if !c.keep(zapcore.Entry{Level: zapcore.DPanicLevel, Message: "boom"}, nil) {
+			t.Fatal("dpanic entries should never be dropped")
+		}
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package log
+
+import "os"
+
+// SIGUSR1/SIGUSR2 are not available on this platform.
+var levelSignals []os.Signal
+
+func (s *levelState) handleSignal(os.Signal) {}
+
+// reraise exits the process because signals cannot be sent to it here.
+func reraise(os.Signal) {
+	exitProcess(1)
+}
@@ -0,0 +1,34 @@
+//go:build unix
+
+package log
+
+import (
+	"os"
+	"syscall"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// levelSignals are SIGUSR1 (switch to debug) and SIGUSR2 (restore the
+// configured level).
+var levelSignals = []os.Signal{syscall.SIGUSR1, syscall.SIGUSR2}
+
+// reraise sends sig to the process again after the listener is stopped so
+// that its default action (or another handler of the application) runs.
+func reraise(sig os.Signal) {
+	if s, ok := sig.(syscall.Signal); ok {
+		if err := syscall.Kill(syscall.Getpid(), s); err == nil {
+			return
+		}
+	}
+	exitProcess(1)
+}
+
+func (s *levelState) handleSignal(sig os.Signal) {
+	switch sig {
+	case syscall.SIGUSR1:
+		s.setLevelFor(zapcore.DebugLevel, s.defaultRevertAfter())
+	case syscall.SIGUSR2:
+		s.setLevelFor(s.baseLevel(), 0)
+	}
+}
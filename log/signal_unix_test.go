@@ -0,0 +1,61 @@
+//go:build unix
+
+package log
+
+import (
+	"os"
+	"syscall"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLevelSignalsToggleDebug(t *testing.T) {
+	prev := zap.L()
+	defer zap.ReplaceGlobals(prev)
+
+	resetInitStateForTest()
+	if err := InitWithConfig(Config{Stdout: new(false), LevelSignals: true}); err != nil {
+		t.Fatalf("InitWithConfig returned error: %v", err)
+	}
+	t.Cleanup(func() { _ = Close() })
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatalf("failed to send SIGUSR1: %v", err)
+	}
+	waitForLevel(t, zapcore.DebugLevel)
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR2); err != nil {
+		t.Fatalf("failed to send SIGUSR2: %v", err)
+	}
+	waitForLevel(t, zapcore.InfoLevel)
+}
+
+func TestExitSignalClosesLogger(t *testing.T) {
+	// SIGWINCH는 기본 동작이 무시이므로 다시 보내도 테스트 프로세스가 종료되지 않는다.
+	l, err := New(Config{Stdout: new(false), CloseOnExit: true, ExitSignals: []os.Signal{syscall.SIGWINCH}})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGWINCH); err != nil {
+		t.Fatalf("failed to send SIGWINCH: %v", err)
+	}
+	waitFor(t, func() bool {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return l.closed
+	})
+}
+
+func TestSIGHUPReloadsConfigFile(t *testing.T) {
+	l, path := newReloadLogger(t, "level: info\n", -1)
+	rewriteConfigFile(t, path, "level: debug\n")
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("failed to send SIGHUP: %v", err)
+	}
+	waitForLoggerLevel(t, l, zapcore.DebugLevel)
+}
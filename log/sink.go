@@ -0,0 +1,136 @@
+package log
+
+import (
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SinkConfig is an extra output that receives only the entries within a level
+// range, e.g. an error-only file next to the main one:
+//
+//	Sinks: []log.SinkConfig{{FilePath: "/var/log/app/error.log", MinLevel: zapcore.WarnLevel}}
+//
+// Entries reach a sink only after they pass Config.Level (and the named
+// levels), so MinLevel below Config.Level has no effect.
+type SinkConfig struct {
+	// MinLevel is the lowest level written. The zero value is
+	// zapcore.InfoLevel.
+	MinLevel zapcore.Level
+	// MaxLevel is the highest level written. nil means no upper bound.
+	MaxLevel *zapcore.Level
+
+	// Exactly one of FilePath, Stdout, Stderr, Syslog, GELF and Fluent must
+	// be set.
+	FilePath string
+	Stdout   bool
+	Stderr   bool
+	Syslog   *SyslogConfig
+	GELF     *GELFConfig
+	Fluent   *FluentConfig
+
+	// Encoding overrides Config.Encoding (EncodingDev for Stdout and Stderr
+	// with Config.Development). Network sinks use the format of their
+	// protocol.
+	Encoding string
+	// Rotation controls the rotation of FilePath with the same defaults as
+	// Config.Rotation. SIGHUP rotates every sink file.
+	Rotation RotationConfig
+	// FileMode defaults to Config.FileMode.
+	FileMode os.FileMode
+}
+
+func checkSinkConfig(cfg *Config, i int, sink *SinkConfig) error {
+	targets := 0
+	for _, set := range []bool{sink.FilePath != "", sink.Stdout, sink.Stderr, sink.Syslog != nil, sink.GELF != nil, sink.Fluent != nil} {
+		if set {
+			targets++
+		}
+	}
+	if targets != 1 {
+		return fmt.Errorf("log: sink %d must set exactly one of FilePath, Stdout, Stderr, Syslog, GELF and Fluent", i)
+	}
+	if sink.MaxLevel != nil && *sink.MaxLevel < sink.MinLevel {
+		return fmt.Errorf("log: sink %d has MaxLevel below MinLevel", i)
+	}
+	if sink.FilePath != "" && sink.FilePath == cfg.FilePath {
+		return fmt.Errorf("log: sink %d writes to FilePath %q", i, sink.FilePath)
+	}
+
+	switch {
+	case sink.Encoding != "":
+	case sink.Stdout || sink.Stderr:
+		sink.Encoding = cfg.consoleEncoding()
+	default:
+		sink.Encoding = cfg.Encoding
+	}
+	if err := checkEncoding(sink.Encoding); err != nil {
+		return err
+	}
+	if sink.FileMode == 0 {
+		sink.FileMode = cfg.FileMode
+	}
+
+	// 호출자의 설정을 바꾸지 않도록 복사한 뒤 기본값을 채운다.
+	var err error
+	switch {
+	case sink.Syslog != nil:
+		syslog := *sink.Syslog
+		err = checkSyslogConfig(cfg, &syslog)
+		sink.Syslog = &syslog
+	case sink.GELF != nil:
+		gelf := *sink.GELF
+		err = checkGELFConfig(cfg, &gelf)
+		sink.GELF = &gelf
+	case sink.Fluent != nil:
+		fluent := *sink.Fluent
+		err = checkFluentConfig(cfg, &fluent)
+		sink.Fluent = &fluent
+	}
+	if err != nil {
+		return err
+	}
+	return checkRotationConfig(&sink.Rotation)
+}
+
+// enabled reports whether lvl is within the range of the sink.
+func (s SinkConfig) enabled(lvl zapcore.Level) bool {
+	return lvl >= s.MinLevel && (s.MaxLevel == nil || lvl <= *s.MaxLevel)
+}
+
+// networkCore returns the core and writer of a Syslog, GELF or Fluent sink,
+// or nil for the other sinks.
+func (s SinkConfig) networkCore(cfg Config) (zapcore.Core, *netWriter, error) {
+	var enc zapcore.Encoder
+	var network NetworkConfig
+	var split func([]byte) [][]byte
+	switch {
+	case s.Syslog != nil:
+		enc, network = newSyslogEncoder(*s.Syslog), s.Syslog.NetworkConfig
+	case s.GELF != nil:
+		enc, network = newGELFEncoder(*s.GELF), s.GELF.NetworkConfig
+		if !network.stream() {
+			split = gelfChunks
+		}
+	case s.Fluent != nil:
+		enc, network = newFluentEncoder(*s.Fluent), s.Fluent.NetworkConfig
+	default:
+		return nil, nil, nil
+	}
+
+	w, err := newNetWriter(network, split, s.FileMode, cfg.DirMode)
+	if err != nil {
+		return nil, nil, err
+	}
+	return zapcore.NewCore(enc, w, zap.LevelEnablerFunc(s.enabled)), w, nil
+}
+
+// fileConfig returns the Config used to open the file of the sink.
+func (s SinkConfig) fileConfig(cfg Config) Config {
+	cfg.FilePath = s.FilePath
+	cfg.Rotation = s.Rotation
+	cfg.FileMode = s.FileMode
+	return cfg
+}
@@ -0,0 +1,108 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSinksSplitLevelsAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+	appPath := filepath.Join(dir, "app.log")
+	errorPath := filepath.Join(dir, "errors", "error.log")
+	debugPath := filepath.Join(dir, "debug.log")
+	l, err := New(Config{
+		Level:    zapcore.DebugLevel,
+		Stdout:   new(false),
+		FilePath: appPath,
+		Sinks: []SinkConfig{
+			{FilePath: errorPath, MinLevel: zapcore.WarnLevel, Encoding: EncodingConsole},
+			{FilePath: debugPath, MinLevel: zapcore.DebugLevel, MaxLevel: new(zapcore.DebugLevel)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	l.Zap().Debug("debug line")
+	l.Zap().Info("info line")
+	l.Zap().Error("error line")
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	read := func(path string) string {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", path, err)
+		}
+		return string(data)
+	}
+	if got := read(appPath); strings.Count(got, "\n") != 3 {
+		t.Fatalf("main file should have every entry: %q", got)
+	}
+	errorLog := read(errorPath)
+	if strings.Contains(errorLog, "info line") || !strings.Contains(errorLog, "error line") || strings.HasPrefix(errorLog, "{") {
+		t.Fatalf("unexpected error sink content: %q", errorLog)
+	}
+	debugLog := read(debugPath)
+	if !strings.Contains(debugLog, "debug line") || strings.Contains(debugLog, "info line") {
+		t.Fatalf("unexpected debug sink content: %q", debugLog)
+	}
+	if info, err := os.Stat(errorPath); err != nil || info.Mode().Perm() != defaultFileMode {
+		t.Fatalf("sink file should use Config.FileMode: %v (%v)", info, err)
+	}
+}
+
+func TestSinksFollowGlobalLevel(t *testing.T) {
+	errorPath := filepath.Join(t.TempDir(), "error.log")
+	l, err := New(Config{
+		Level:  zapcore.ErrorLevel,
+		Stdout: new(false),
+		Sinks:  []SinkConfig{{FilePath: errorPath, MinLevel: zapcore.WarnLevel}},
+	})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	l.Zap().Warn("warn line")
+	l.Zap().Error("error line")
+	_ = l.Close()
+
+	data, _ := os.ReadFile(errorPath)
+	if strings.Contains(string(data), "warn line") || !strings.Contains(string(data), "error line") {
+		t.Fatalf("sink should not bypass Config.Level: %q", data)
+	}
+}
+
+func TestCheckConfigRejectsInvalidSinks(t *testing.T) {
+	tests := map[string][]SinkConfig{
+		"no target":      {{MinLevel: zapcore.WarnLevel}},
+		"two targets":    {{Stderr: true, FilePath: "error.log"}},
+		"inverted range": {{Stderr: true, MinLevel: zapcore.ErrorLevel, MaxLevel: new(zapcore.InfoLevel)}},
+		"main file":      {{FilePath: "app.log"}},
+		"same file":      {{FilePath: "error.log"}, {FilePath: "error.log"}},
+		"encoding":       {{Stderr: true, Encoding: "xml"}},
+		"rotation":       {{FilePath: "error.log", Rotation: RotationConfig{Interval: RotationInterval(9)}}},
+	}
+	for name, sinks := range tests {
+		cfg := Config{FilePath: "app.log", Sinks: sinks}
+		if err := checkConfig(&cfg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	sinks := []SinkConfig{{Stderr: true, MinLevel: zapcore.ErrorLevel}}
+	cfg := Config{Encoding: EncodingConsole, FileMode: 0o640, Sinks: sinks}
+	if err := checkConfig(&cfg); err != nil {
+		t.Fatalf("checkConfig returned error: %v", err)
+	}
+	got := cfg.Sinks[0]
+	if got.Encoding != EncodingConsole || got.FileMode != 0o640 || got.Rotation.MaxSize != defaultMaxSize {
+		t.Fatalf("sink defaults were not applied: %+v", got)
+	}
+	if sinks[0].Encoding != "" {
+		t.Fatal("checkConfig should not modify the caller's sinks")
+	}
+}
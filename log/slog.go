@@ -0,0 +1,189 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SlogHandler is a slog.Handler that writes through the cores of a zap
+// logger. Records logged with a context (slog.InfoContext etc.) carry the
+// fields returned by FromContext.
+type SlogHandler struct {
+	// core and name are empty for a handler of the global logger, which is
+	// looked up on every record.
+	core zapcore.Core
+	name string
+	// fields holds the attrs added with WithAttrs, with a zap.Namespace for
+	// every group that has attrs.
+	fields []zap.Field
+	// groups are opened by WithGroup but have no attrs yet. They are emitted
+	// only when attrs follow so that empty groups are omitted.
+	groups []string
+}
+
+// NewSlogHandler returns a slog.Handler backed by logger's core. A nil logger
+// uses the global logger current at each record, so the handler follows a
+// later Init or ReplaceGlobals.
+func NewSlogHandler(logger *zap.Logger) *SlogHandler {
+	if logger == nil {
+		return &SlogHandler{}
+	}
+	return &SlogHandler{core: logger.Core(), name: logger.Name()}
+}
+
+// target returns the core and logger name that records are written to.
+func (h *SlogHandler) target() (zapcore.Core, string) {
+	if h.core != nil {
+		return h.core, h.name
+	}
+	l := rootLogger()
+	return l.Core(), l.Name()
+}
+
+func (h *SlogHandler) Enabled(_ context.Context, lvl slog.Level) bool {
+	core, _ := h.target()
+	return core.Enabled(slogToZapLevel(lvl))
+}
+
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	core, name := h.target()
+	ent := zapcore.Entry{
+		LoggerName: name,
+		Level:      slogToZapLevel(record.Level),
+		Time:       record.Time,
+		Message:    record.Message,
+	}
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		ent.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+	}
+
+	ce := core.Check(ent, nil)
+	if ce == nil {
+		return nil
+	}
+
+	// trace 필드는 group 밖(최상위)에 기록되어야 하므로 가장 먼저 둔다.
+	fields := FromContext(ctx)
+	fields = append(fields, h.fields...)
+	if record.NumAttrs() > 0 {
+		var attrs []zap.Field
+		record.Attrs(func(attr slog.Attr) bool {
+			attrs = appendAttr(attrs, attr)
+			return true
+		})
+		if len(attrs) > 0 {
+			fields = appendGroups(fields, h.groups)
+			fields = append(fields, attrs...)
+		}
+	}
+
+	ce.Write(fields...)
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var converted []zap.Field
+	for _, attr := range attrs {
+		converted = appendAttr(converted, attr)
+	}
+	if len(converted) == 0 {
+		return h
+	}
+
+	clone := *h
+	clone.fields = appendGroups(append([]zap.Field(nil), h.fields...), h.groups)
+	clone.fields = append(clone.fields, converted...)
+	clone.groups = nil
+	return &clone
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	clone := *h
+	clone.groups = append(append([]string(nil), h.groups...), name)
+	return &clone
+}
+
+func appendGroups(fields []zap.Field, groups []string) []zap.Field {
+	for _, group := range groups {
+		fields = append(fields, zap.Namespace(group))
+	}
+	return fields
+}
+
+func appendAttr(fields []zap.Field, attr slog.Attr) []zap.Field {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	value := attr.Value
+	switch value.Kind() {
+	case slog.KindGroup:
+		group := value.Group()
+		if len(group) == 0 {
+			return fields
+		}
+		if attr.Key == "" {
+			// key가 없는 group은 상위 레벨에 인라인한다.
+			for _, member := range group {
+				fields = appendAttr(fields, member)
+			}
+			return fields
+		}
+		return append(fields, zap.Object(attr.Key, slogGroup(group)))
+	case slog.KindString:
+		return append(fields, zap.String(attr.Key, value.String()))
+	case slog.KindInt64:
+		return append(fields, zap.Int64(attr.Key, value.Int64()))
+	case slog.KindUint64:
+		return append(fields, zap.Uint64(attr.Key, value.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, zap.Float64(attr.Key, value.Float64()))
+	case slog.KindBool:
+		return append(fields, zap.Bool(attr.Key, value.Bool()))
+	case slog.KindDuration:
+		return append(fields, zap.Duration(attr.Key, value.Duration()))
+	case slog.KindTime:
+		return append(fields, zap.Time(attr.Key, value.Time()))
+	default:
+		if err, ok := value.Any().(error); ok {
+			return append(fields, NamedErr(attr.Key, err))
+		}
+		return append(fields, zap.Any(attr.Key, value.Any()))
+	}
+}
+
+type slogGroup []slog.Attr
+
+func (g slogGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	var fields []zap.Field
+	for _, attr := range g {
+		fields = appendAttr(fields, attr)
+	}
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	return nil
+}
+
+func slogToZapLevel(lvl slog.Level) zapcore.Level {
+	switch {
+	case lvl >= slog.LevelError:
+		return zapcore.ErrorLevel
+	case lvl >= slog.LevelWarn:
+		return zapcore.WarnLevel
+	case lvl >= slog.LevelInfo:
+		return zapcore.InfoLevel
+	default:
+		return zapcore.DebugLevel
+	}
+}
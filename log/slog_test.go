@@ -0,0 +1,134 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/slogtest"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSlogHandlerConformance(t *testing.T) {
+	var buf bytes.Buffer
+	core := zapcore.NewCore(newEncoder(EncodingJSON), zapcore.AddSync(&buf), zapcore.DebugLevel)
+	handler := NewSlogHandler(zap.New(core))
+
+	err := slogtest.TestHandler(handler, func() []map[string]any {
+		var results []map[string]any
+		for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+			var m map[string]any
+			if err := json.Unmarshal(line, &m); err != nil {
+				t.Fatalf("failed to decode line %q: %v", line, err)
+			}
+			// trace 필드는 slogtest가 기대하지 않으므로 제외한다.
+			delete(m, traceFieldName)
+			delete(m, spanIDFieldName)
+			delete(m, pSpanIDFieldName)
+			results = append(results, m)
+		}
+		return results
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSlogHandlerAddsTraceFieldsFromContext(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := slog.New(NewSlogHandler(zap.New(core))).WithGroup("req").With("id", 7)
+
+	ctx := WithTraceID(context.Background(), "t-1")
+	ctx = WithSpanID(ctx, "s-1")
+	ctx = WithFields(ctx, zap.String("tenant", "acme"))
+	logger.InfoContext(ctx, "hello", "path", "/x")
+	logger.DebugContext(ctx, "filtered")
+
+	if logs.Len() != 1 {
+		t.Fatalf("expected one entry, got: %d", logs.Len())
+	}
+	entry := logs.All()[0]
+	fields := entry.ContextMap()
+	if fields[traceFieldName] != "t-1" || fields[spanIDFieldName] != "s-1" || fields["tenant"] != "acme" {
+		t.Fatalf("unexpected top-level fields: %v", fields)
+	}
+	group, ok := fields["req"].(map[string]any)
+	if !ok || group["id"] != int64(7) || group["path"] != "/x" {
+		t.Fatalf("unexpected group: %#v", fields["req"])
+	}
+	if got := filepath.Base(entry.Caller.File); got != "slog_test.go" {
+		t.Fatalf("unexpected caller: %s", entry.Caller.String())
+	}
+}
+
+func TestSlogHandlerLevelMapping(t *testing.T) {
+	tests := map[slog.Level]zapcore.Level{
+		slog.LevelDebug - 4: zapcore.DebugLevel,
+		slog.LevelDebug:     zapcore.DebugLevel,
+		slog.LevelInfo:      zapcore.InfoLevel,
+		slog.LevelWarn:      zapcore.WarnLevel,
+		slog.LevelError:     zapcore.ErrorLevel,
+		slog.LevelError + 4: zapcore.ErrorLevel,
+	}
+	for in, want := range tests {
+		if got := slogToZapLevel(in); got != want {
+			t.Fatalf("slogToZapLevel(%v) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestInitWithConfigInstallsSlogDefault(t *testing.T) {
+	prev := zap.L()
+	prevSlog := slog.Default()
+	defer func() {
+		zap.ReplaceGlobals(prev)
+		slog.SetDefault(prevSlog)
+	}()
+
+	resetInitStateForTest()
+	if err := InitWithConfig(Config{Stdout: new(false), SlogDefault: true}); err != nil {
+		t.Fatalf("InitWithConfig returned error: %v", err)
+	}
+	t.Cleanup(func() { _ = Close() })
+
+	if _, ok := slog.Default().Handler().(*SlogHandler); !ok {
+		t.Fatalf("unexpected default handler: %T", slog.Default().Handler())
+	}
+}
+
+func TestSlogDefaultFollowsReplacedLogger(t *testing.T) {
+	prev := zap.L()
+	prevSlog := slog.Default()
+	defer func() {
+		zap.ReplaceGlobals(prev)
+		slog.SetDefault(prevSlog)
+	}()
+
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.log"), filepath.Join(dir, "second.log")
+	resetInitStateForTest()
+	if err := InitWithConfig(Config{Stdout: new(false), FilePath: first, SlogDefault: true}); err != nil {
+		t.Fatalf("InitWithConfig returned error: %v", err)
+	}
+	t.Cleanup(func() { _ = Close() })
+	if err := InitWithConfig(Config{Stdout: new(false), FilePath: second}); err != nil {
+		t.Fatalf("InitWithConfig returned error: %v", err)
+	}
+
+	slog.Info("after replace")
+	_ = Sync()
+
+	if data, _ := os.ReadFile(first); strings.Contains(string(data), "after replace") {
+		t.Fatalf("slog should not write through the closed logger: %s", data)
+	}
+	if data, _ := os.ReadFile(second); !strings.Contains(string(data), "after replace") {
+		t.Fatalf("slog should write through the current logger: %s", data)
+	}
+}
@@ -0,0 +1,104 @@
+package log
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// badKey is the field key used for a key that is not a string or has no
+// value, following the log/slog convention.
+const badKey = "!BADKEY"
+
+// The helpers below call zap.L() directly instead of sharing an internal
+// function so that the caller skip configured by Init stays correct.
+
+func Debug(ctx context.Context, msg string, fields ...zap.Field) {
+	zap.L().Debug(msg, withContext(ctx, fields)...)
+}
+
+func Info(ctx context.Context, msg string, fields ...zap.Field) {
+	zap.L().Info(msg, withContext(ctx, fields)...)
+}
+
+func Warn(ctx context.Context, msg string, fields ...zap.Field) {
+	zap.L().Warn(msg, withContext(ctx, fields)...)
+}
+
+func Error(ctx context.Context, msg string, fields ...zap.Field) {
+	zap.L().Error(msg, withContext(ctx, fields)...)
+}
+
+// Fatal logs at FatalLevel and then calls os.Exit(1).
+func Fatal(ctx context.Context, msg string, fields ...zap.Field) {
+	zap.L().Fatal(msg, withContext(ctx, fields)...)
+}
+
+// Panic logs at PanicLevel and then panics with msg.
+func Panic(ctx context.Context, msg string, fields ...zap.Field) {
+	zap.L().Panic(msg, withContext(ctx, fields)...)
+}
+
+// Debugw logs msg with alternating keys and values, e.g.
+// Debugw(ctx, "cache miss", "key", key, "size", n). zap.Field values are
+// accepted in place of a key/value pair.
+func Debugw(ctx context.Context, msg string, keysAndValues ...any) {
+	zap.L().Debug(msg, withContext(ctx, kvFields(keysAndValues))...)
+}
+
+func Infow(ctx context.Context, msg string, keysAndValues ...any) {
+	zap.L().Info(msg, withContext(ctx, kvFields(keysAndValues))...)
+}
+
+func Warnw(ctx context.Context, msg string, keysAndValues ...any) {
+	zap.L().Warn(msg, withContext(ctx, kvFields(keysAndValues))...)
+}
+
+func Errorw(ctx context.Context, msg string, keysAndValues ...any) {
+	zap.L().Error(msg, withContext(ctx, kvFields(keysAndValues))...)
+}
+
+// Fatalw logs at FatalLevel and then calls os.Exit(1).
+func Fatalw(ctx context.Context, msg string, keysAndValues ...any) {
+	zap.L().Fatal(msg, withContext(ctx, kvFields(keysAndValues))...)
+}
+
+// Panicw logs at PanicLevel and then panics with msg.
+func Panicw(ctx context.Context, msg string, keysAndValues ...any) {
+	zap.L().Panic(msg, withContext(ctx, kvFields(keysAndValues))...)
+}
+
+func withContext(ctx context.Context, fields []zap.Field) []zap.Field {
+	return append(FromContext(ctx), fields...)
+}
+
+func kvFields(keysAndValues []any) []zap.Field {
+	if len(keysAndValues) == 0 {
+		return nil
+	}
+
+	fields := make([]zap.Field, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); {
+		switch key := keysAndValues[i].(type) {
+		case zap.Field:
+			fields = append(fields, key)
+			i++
+		case string:
+			if i+1 >= len(keysAndValues) {
+				fields = append(fields, zap.Any(badKey, key))
+				i++
+				continue
+			}
+			if err, ok := keysAndValues[i+1].(error); ok {
+				fields = append(fields, NamedErr(key, err))
+			} else {
+				fields = append(fields, zap.Any(key, keysAndValues[i+1]))
+			}
+			i += 2
+		default:
+			fields = append(fields, zap.Any(badKey, key))
+			i++
+		}
+	}
+	return fields
+}
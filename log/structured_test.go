@@ -0,0 +1,140 @@
+package log
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestStructuredHelpersWriteFieldsAndContext(t *testing.T) {
+	logs := installCallerLoggerForTest(t)
+
+	ctx := WithTraceID(context.Background(), "t-1")
+	ctx = WithFields(ctx, zap.String("tenant", "acme"))
+
+	Debug(ctx, "debug", zap.Int("n", 1))
+	Info(ctx, "info", zap.String("k", "v"))
+	Warn(ctx, "warn")
+	Error(ctx, "error", zap.Bool("ok", false))
+	Debugw(ctx, "debugw", "n", 1)
+	Infow(ctx, "infow", "k", "v", zap.String("typed", "yes"))
+	Warnw(ctx, "warnw")
+	Errorw(ctx, "errorw", "ok", false)
+
+	entries := logs.All()
+	if len(entries) != 8 {
+		t.Fatalf("unexpected log count: %d", len(entries))
+	}
+	for _, entry := range entries {
+		fields := entry.ContextMap()
+		if fields[traceFieldName] != "t-1" || fields["tenant"] != "acme" {
+			t.Fatalf("%s: missing context fields: %v", entry.Message, fields)
+		}
+	}
+	if got := entries[1].ContextMap()["k"]; got != "v" {
+		t.Fatalf("unexpected info field: %#v", got)
+	}
+	infow := entries[5].ContextMap()
+	if infow["k"] != "v" || infow["typed"] != "yes" {
+		t.Fatalf("unexpected infow fields: %v", infow)
+	}
+	if got := entries[4].ContextMap()["n"]; got != int64(1) {
+		t.Fatalf("unexpected debugw field: %#v", got)
+	}
+}
+
+func TestHelpersReportCallerOfWrapper(t *testing.T) {
+	logs := installCallerLoggerForTest(t)
+	ctx := context.Background()
+
+	Infof(ctx, "f")
+	Info(ctx, "plain")
+	Infow(ctx, "w", "k", "v")
+
+	for _, entry := range logs.All() {
+		if got := filepath.Base(entry.Caller.File); got != "structured_test.go" {
+			t.Fatalf("%s: unexpected caller: %s", entry.Message, entry.Caller.String())
+		}
+	}
+}
+
+func TestPanicHelpersPanicAfterLogging(t *testing.T) {
+	logs := installCallerLoggerForTest(t)
+	ctx := context.Background()
+
+	for name, fn := range map[string]func(){
+		"Panic":  func() { Panic(ctx, "boom", zap.Int("n", 1)) },
+		"Panicw": func() { Panicw(ctx, "boom", "n", 1) },
+		"Panicf": func() { Panicf(ctx, "boom %d", 1) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%s should panic", name)
+				}
+			}()
+			fn()
+		}()
+	}
+
+	if got := logs.FilterLevelExact(zapcore.PanicLevel).Len(); got != 3 {
+		t.Fatalf("unexpected panic log count: %d", got)
+	}
+}
+
+func TestFatalHelpersRunFatalHook(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	prev := zap.L()
+	zap.ReplaceGlobals(zap.New(core, zap.WithFatalHook(zapcore.WriteThenPanic)))
+	t.Cleanup(func() { zap.ReplaceGlobals(prev) })
+	ctx := context.Background()
+
+	for _, fn := range []func(){
+		func() { Fatal(ctx, "fatal") },
+		func() { Fatalw(ctx, "fatalw", "k", "v") },
+		func() { Fatalf(ctx, "fatal %s", "f") },
+	} {
+		func() {
+			defer func() { _ = recover() }()
+			fn()
+		}()
+	}
+
+	if got := logs.FilterLevelExact(zapcore.FatalLevel).Len(); got != 3 {
+		t.Fatalf("unexpected fatal log count: %d", got)
+	}
+}
+
+func TestKVFieldsHandlesBadKeys(t *testing.T) {
+	fields := kvFields([]any{"a", 1, 42, "b", zap.String("c", "3"), "dangling"})
+
+	var keys []string
+	for _, f := range fields {
+		keys = append(keys, f.Key)
+	}
+	want := []string{"a", badKey, "b", badKey}
+	if len(keys) != len(want) {
+		t.Fatalf("unexpected keys: %v", keys)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Fatalf("unexpected keys: %v", keys)
+		}
+	}
+}
+
+// installCallerLoggerForTest installs an observed global logger with the
+// same caller skip as Init.
+func installCallerLoggerForTest(t *testing.T) *observer.ObservedLogs {
+	t.Helper()
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	prev := zap.L()
+	zap.ReplaceGlobals(zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1)))
+	t.Cleanup(func() { zap.ReplaceGlobals(prev) })
+	return logs
+}
@@ -0,0 +1,91 @@
+package log
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSyslogSinkOverUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket returned error: %v", err)
+	}
+	defer pc.Close()
+
+	syslog := &SyslogConfig{NetworkConfig: testNetworkConfig(t, "udp", pc.LocalAddr().String()), Facility: 16, Hostname: "web-1", AppName: "order-api"}
+	l := newNetworkSinkLogger(t, SinkConfig{Syslog: syslog, MinLevel: zapcore.WarnLevel})
+	l.Zap().Info("not shipped")
+	l.Zap().Named("payment").Warn("charge slow", zap.String("orderId", "o-1"))
+
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 64*1024)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("ReadFrom returned error: %v", err)
+	}
+	msg := string(buf[:n])
+
+	// local0(16)*8 + warning(4)
+	if !strings.HasPrefix(msg, "<132>1 ") {
+		t.Fatalf("unexpected PRI/VERSION: %q", msg)
+	}
+	header := " web-1 order-api " + strconv.Itoa(os.Getpid()) + " - - "
+	i := strings.Index(msg, header)
+	if i < 0 {
+		t.Fatalf("unexpected header: %q", msg)
+	}
+	if _, err := time.Parse(time.RFC3339Nano, msg[len("<132>1 "):i]); err != nil {
+		t.Fatalf("invalid timestamp: %q", msg)
+	}
+
+	var record map[string]any
+	if err := json.Unmarshal([]byte(msg[i+len(header):]), &record); err != nil {
+		t.Fatalf("MSG should be JSON: %v", err)
+	}
+	if record["msg"] != "charge slow" || record["level"] != "warn" || record["logger"] != "payment" || record["orderId"] != "o-1" {
+		t.Fatalf("unexpected record: %v", record)
+	}
+}
+
+func TestSyslogEncoderFramesStreams(t *testing.T) {
+	enc := newSyslogEncoder(SyslogConfig{NetworkConfig: NetworkConfig{Network: "tcp"}, Facility: 1, Hostname: "-", AppName: "app"})
+	with := enc.Clone()
+	with.AddString("service", "order")
+
+	buf, err := with.EncodeEntry(zapcore.Entry{Level: zapcore.ErrorLevel, Time: time.Now(), Message: "failed"}, nil)
+	if err != nil {
+		t.Fatalf("EncodeEntry returned error: %v", err)
+	}
+	size, msg, _ := strings.Cut(buf.String(), " ")
+	if n, _ := strconv.Atoi(size); n != len(msg) {
+		t.Fatalf("octet count %s does not match %d bytes", size, len(msg))
+	}
+	if !strings.HasPrefix(msg, "<11>1 ") || !strings.Contains(msg, `"service":"order"`) {
+		t.Fatalf("unexpected message: %q", msg)
+	}
+
+	buf, _ = enc.EncodeEntry(zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now(), Message: "plain"}, nil)
+	if strings.Contains(buf.String(), "service") {
+		t.Fatal("Clone should not share fields with the original")
+	}
+}
+
+func TestSyslogToken(t *testing.T) {
+	if got := syslogToken("my host\n", 255); got != "my_host_" {
+		t.Fatalf("unexpected token: %q", got)
+	}
+	if got := syslogToken("", 48); got != "-" {
+		t.Fatalf("unexpected token: %q", got)
+	}
+	if got := syslogToken(strings.Repeat("a", 60), 48); len(got) != 48 {
+		t.Fatalf("token should be truncated: %q", got)
+	}
+}
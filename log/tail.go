@@ -0,0 +1,181 @@
+package log
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultTailBufferSize = 1000
+
+type tailBufferKeyType struct{}
+
+// TailBufferKey is the context key of the TailBuffer added by WithTailBuffer.
+var TailBufferKey tailBufferKeyType
+
+// activeTailBuffers counts the buffers that are neither flushed nor
+// discarded. While it is non-zero the level cores let debug and info entries
+// through to Write, where the buffer is known.
+var activeTailBuffers atomic.Int64
+
+// TailBuffer holds the debug and info entries of one request so that they are
+// written only when the request fails. Entries of other levels are written
+// immediately.
+type TailBuffer struct {
+	mu      sync.Mutex
+	entries []tailEntry
+	max     int
+	dropped int
+	done    bool
+}
+
+type tailEntry struct {
+	core   zapcore.Core
+	ent    zapcore.Entry
+	fields []zapcore.Field
+}
+
+// WithTailBuffer returns a copy of ctx carrying a new TailBuffer that holds
+// up to size entries (oldest first out). size <= 0 uses 1000. The caller must
+// call Flush or Discard when the request ends.
+func WithTailBuffer(ctx context.Context, size int) (context.Context, *TailBuffer) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if size <= 0 {
+		size = defaultTailBufferSize
+	}
+	b := &TailBuffer{max: size}
+	activeTailBuffers.Add(1)
+	return context.WithValue(ctx, TailBufferKey, b), b
+}
+
+// GetTailBuffer returns the TailBuffer of ctx, or nil.
+func GetTailBuffer(ctx context.Context) *TailBuffer {
+	if ctx == nil {
+		return nil
+	}
+	b, _ := ctx.Value(TailBufferKey).(*TailBuffer)
+	return b
+}
+
+// Flush writes the buffered entries regardless of the current level. Entries
+// logged afterwards are written as if there were no buffer.
+func (b *TailBuffer) Flush() {
+	for _, e := range b.finish() {
+		if ce := e.core.Check(e.ent, nil); ce != nil {
+			ce.Write(e.fields...)
+		}
+	}
+}
+
+// Discard drops the buffered entries.
+func (b *TailBuffer) Discard() {
+	b.finish()
+}
+
+// Len returns the number of buffered entries.
+func (b *TailBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.entries)
+}
+
+// Dropped returns the number of entries dropped because the buffer was full.
+func (b *TailBuffer) Dropped() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped
+}
+
+func (b *TailBuffer) finish() []tailEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.done {
+		return nil
+	}
+	b.done = true
+	activeTailBuffers.Add(-1)
+	entries := b.entries
+	b.entries = nil
+	return entries
+}
+
+// add buffers an entry and reports false if the buffer is already finished.
+func (b *TailBuffer) add(e tailEntry) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.done {
+		return false
+	}
+	if len(b.entries) == b.max {
+		b.entries[0] = tailEntry{}
+		b.entries = b.entries[1:]
+		b.dropped++
+	}
+	b.entries = append(b.entries, e)
+	return true
+}
+
+// field marks the entries logged with the context of b. It is a SkipType
+// field, so encoders ignore it.
+func (b *TailBuffer) field() zap.Field {
+	return zap.Field{Type: zapcore.SkipType, Interface: b}
+}
+
+func tailBufferField(fields []zapcore.Field) *TailBuffer {
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i].Type != zapcore.SkipType {
+			continue
+		}
+		if b, ok := fields[i].Interface.(*TailBuffer); ok {
+			return b
+		}
+	}
+	return nil
+}
+
+// routeCore decides in Write, where the fields are known, what happens to an
+// entry that may belong to a TailBuffer or a debug context. enabled reports
+// whether the entry passed the level check.
+type routeCore struct {
+	core    zapcore.Core
+	tail    *TailBuffer
+	debug   bool
+	enabled bool
+}
+
+// routeCore is only added to a CheckedEntry by levelCore, so it needs nothing
+// but Write.
+func (c *routeCore) Enabled(zapcore.Level) bool { return true }
+
+func (c *routeCore) With([]zapcore.Field) zapcore.Core { return c }
+
+func (c *routeCore) Check(_ zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce
+}
+
+func (c *routeCore) Sync() error { return nil }
+
+func (c *routeCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ent.Level < zapcore.WarnLevel {
+		b := tailBufferField(fields)
+		if b == nil {
+			b = c.tail
+		}
+		// 필드 slice는 호출 이후 재사용될 수 있으므로 복사해서 보관한다.
+		if b != nil && b.add(tailEntry{core: c.core, ent: ent, fields: append([]zapcore.Field(nil), fields...)}) {
+			return nil
+		}
+	}
+	if !c.enabled && !c.debug && !hasDebugLogField(fields) {
+		return nil
+	}
+	if ce := c.core.Check(ent, nil); ce != nil {
+		ce.Write(fields...)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestTailBufferFlushWritesHeldEntries(t *testing.T) {
+	logs := installLevelCoreForTest(t, zapcore.InfoLevel)
+	ctx, buf := WithTailBuffer(context.Background(), 0)
+
+	Debug(ctx, "step 1")
+	Infof(ctx, "step 2")
+	Warn(ctx, "slow")
+	Debug(context.Background(), "unrelated")
+
+	if logs.Len() != 1 || logs.All()[0].Message != "slow" {
+		t.Fatalf("only warn should be written before Flush, got: %v", logs.All())
+	}
+	if buf.Len() != 2 {
+		t.Fatalf("unexpected buffered entries: %d", buf.Len())
+	}
+
+	buf.Flush()
+	if got := logs.Len(); got != 3 {
+		t.Fatalf("expected buffered entries after Flush, got: %d", got)
+	}
+	if logs.All()[1].Message != "step 1" || logs.All()[1].Level != zapcore.DebugLevel {
+		t.Fatalf("debug entry should be flushed regardless of level: %v", logs.All()[1])
+	}
+
+	Info(ctx, "after flush")
+	Debug(ctx, "debug after flush")
+	if logs.FilterMessage("after flush").Len() != 1 || logs.FilterMessage("debug after flush").Len() != 0 {
+		t.Fatal("entries after Flush should follow the level")
+	}
+}
+
+func TestTailBufferDiscardDropsEntries(t *testing.T) {
+	logs := installLevelCoreForTest(t, zapcore.InfoLevel)
+	ctx, buf := WithTailBuffer(context.Background(), 0)
+	active := activeTailBuffers.Load()
+
+	Named("payment").With(FromContext(ctx)...).Info("charged")
+	Info(ctx, "done")
+	buf.Discard()
+	buf.Flush()
+
+	if logs.Len() != 0 {
+		t.Fatalf("discarded entries should not be written: %v", logs.All())
+	}
+	if activeTailBuffers.Load() != active-1 {
+		t.Fatal("finished buffers should not be active")
+	}
+	if activeTailBuffers.Load() == 0 && L().Zap().Core().Enabled(zapcore.DebugLevel) {
+		t.Fatal("debug should be disabled without active buffers")
+	}
+}
+
+func TestTailBufferDropsOldestWhenFull(t *testing.T) {
+	logs := installLevelCoreForTest(t, zapcore.InfoLevel)
+	ctx, buf := WithTailBuffer(context.Background(), 2)
+
+	for _, msg := range []string{"a", "b", "c"} {
+		Info(ctx, msg)
+	}
+	buf.Flush()
+
+	if buf.Dropped() != 1 || logs.Len() != 2 || logs.All()[0].Message != "b" {
+		t.Fatalf("unexpected flushed entries: %v (dropped %d)", logs.All(), buf.Dropped())
+	}
+}
+
+func TestTailBufferFieldIsNotEncoded(t *testing.T) {
+	ctx, buf := WithTailBuffer(context.Background(), 0)
+	defer buf.Discard()
+
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range FromContext(ctx) {
+		field.AddTo(enc)
+	}
+	if len(enc.Fields) != 3 {
+		t.Fatalf("unexpected encoded fields: %v", enc.Fields)
+	}
+	if GetTailBuffer(ctx) != buf || GetTailBuffer(context.Background()) != nil {
+		t.Fatal("GetTailBuffer should return the buffer of ctx")
+	}
+}
+
+func TestWithTailBufferNilContext(t *testing.T) {
+	ctx, buf := WithTailBuffer(nil, 1) //nolint:staticcheck // intentional nil context test
+	defer buf.Discard()
+
+	if ctx == nil || GetTailBuffer(ctx) != buf {
+		t.Fatal("a nil context should be replaced with context.Background")
+	}
+}
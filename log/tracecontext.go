@@ -0,0 +1,80 @@
+package log
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SampledHeader carries the sampling decision next to the kit trace headers:
+// "1" for a sampled trace and "0" otherwise.
+const SampledHeader = "X-Sampled"
+
+type sampledKeyType struct{}
+type traceStateKeyType struct{}
+
+var SampledKey sampledKeyType
+var TraceStateKey traceStateKeyType
+
+// WithSampled records the sampling decision of the trace, as received from
+// the caller or made by a sampler. Entries logged with ctx carry it, so that
+// trace sampling of SamplingConfig follows it.
+func WithSampled(ctx context.Context, sampled bool) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, SampledKey, sampled)
+}
+
+// GetSampled returns the sampled flag stored by WithSampled. ok is false
+// when the caller did not decide.
+func GetSampled(ctx context.Context) (sampled, ok bool) {
+	if ctx == nil {
+		return false, false
+	}
+
+	sampled, ok = ctx.Value(SampledKey).(bool)
+	return sampled, ok
+}
+
+// WithTraceState stores the W3C tracestate to pass on to downstream calls.
+func WithTraceState(ctx context.Context, state string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, TraceStateKey, state)
+}
+
+// GetTraceState returns the tracestate stored by WithTraceState, or "".
+func GetTraceState(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	state, _ := ctx.Value(TraceStateKey).(string)
+	return state
+}
+
+// sampledMarker is the decision of WithSampled, carried by a field that is
+// not encoded.
+type sampledMarker bool
+
+func sampledLogField(sampled bool) zap.Field {
+	return zap.Field{Type: zapcore.SkipType, Interface: sampledMarker(sampled)}
+}
+
+// sampledField returns the last decision found in fields.
+func sampledField(fields []zapcore.Field) (sampled, ok bool) {
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i].Type != zapcore.SkipType {
+			continue
+		}
+		if m, ok := fields[i].Interface.(sampledMarker); ok {
+			return bool(m), true
+		}
+	}
+	return false, false
+}
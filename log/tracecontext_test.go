@@ -0,0 +1,24 @@
+package log
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSampledAndTraceState(t *testing.T) {
+	if _, ok := GetSampled(context.Background()); ok {
+		t.Fatal("no decision should be reported without WithSampled")
+	}
+	if GetTraceState(nil) != "" { //nolint:staticcheck // intentional nil context test
+		t.Fatal("a nil context should have no tracestate")
+	}
+
+	ctx := WithSampled(nil, false) //nolint:staticcheck // intentional nil context test
+	ctx = WithTraceState(ctx, "vendor=abc")
+	if sampled, ok := GetSampled(ctx); !ok || sampled {
+		t.Fatalf("unexpected sampled flag: %v (%v)", sampled, ok)
+	}
+	if GetTraceState(ctx) != "vendor=abc" {
+		t.Fatalf("unexpected tracestate: %q", GetTraceState(ctx))
+	}
+}
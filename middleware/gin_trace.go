@@ -1,12 +1,20 @@
 package middleware
 
 import (
+	"fmt"
+	"net/http"
 	"strings"
 
 	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+	"github.com/NamhaeSusan/my-go-kit/propagation"
+	"github.com/NamhaeSusan/my-go-kit/trace"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
+// loggerName is the kitlog.Named logger used by the middlewares.
+const loggerName = "middleware"
+
 const (
 	TraceIDContextKey = "traceId"
 	SpanIDContextKey  = "spanId"
@@ -18,6 +26,26 @@ type TraceIDConfig struct {
 	SpanHeaderName    string
 	PSpanHeaderName   string
 	SetResponseHeader *bool
+	// Propagator reads the trace of the request. nil uses
+	// propagation.Default, or the kit headers named above followed by W3C
+	// when a name differs from the kit headers. HeaderName, SpanHeaderName and PSpanHeaderName
+	// are still used for the response headers.
+	Propagator propagation.Propagator
+	// DebugTrust turns on debug logging for a single request when the
+	// DebugHeaderName header comes from a caller it trusts. nil ignores the
+	// header. TrustedCIDRs are checked against the address of the direct
+	// peer, not X-Forwarded-For, which any client can set; behind a proxy use
+	// the token.
+	DebugTrust *kitlog.DebugTrust
+	// DebugHeaderName defaults to kitlog.DebugHeader.
+	DebugHeaderName string
+	// TailBuffer holds the debug and info logs written with the request
+	// context and writes them only if the request fails with a 5xx status or
+	// c.Errors. They are dropped otherwise.
+	TailBuffer bool
+	// TailBufferSize is the number of entries held per request. Defaults to
+	// 1000.
+	TailBufferSize int
 }
 
 func GinTraceID() gin.HandlerFunc {
@@ -45,33 +73,59 @@ func GinTraceIDWithConfig(cfg TraceIDConfig) gin.HandlerFunc {
 		pSpanHeader = kitlog.PSpanHeader
 	}
 
+	debugHeader := cfg.DebugHeaderName
+	if debugHeader == "" {
+		debugHeader = kitlog.DebugHeader
+	}
+
 	setResponseHeader := true
 	if cfg.SetResponseHeader != nil {
 		setResponseHeader = *cfg.SetResponseHeader
 	}
 
-	return func(c *gin.Context) {
-		traceID := strings.TrimSpace(c.GetHeader(traceHeader))
-		if traceID == "" {
-			traceID = kitlog.NewTraceID()
+	propagator := cfg.Propagator
+	if propagator == nil && (traceHeader != kitlog.TraceHeader || spanHeader != kitlog.SpanHeader || pSpanHeader != kitlog.PSpanHeader) {
+		propagator = propagation.Composite{
+			propagation.Legacy{TraceHeader: traceHeader, SpanHeader: spanHeader, PSpanHeader: pSpanHeader},
+			propagation.W3C{},
 		}
+	}
 
-		spanID := strings.TrimSpace(c.GetHeader(spanHeader))
-		if spanID == "" {
-			spanID = kitlog.NewSpanID()
+	return func(c *gin.Context) {
+		// nil이면 ExtractContext가 요청 시점의 Default를 쓴다.
+		ctx, generated := propagation.ExtractContext(c.Request.Context(), propagator, propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := trace.Continue(ctx, serverSpanName(c),
+			trace.WithKind(trace.SpanKindServer),
+			trace.WithAttributes(httpServerAttributes(c)...),
+		)
+		defer finishSpan(c, span)
+
+		traceID := kitlog.GetTraceID(ctx)
+		spanID := kitlog.GetSpanID(ctx)
+		pSpanID := kitlog.GetPSpanID(ctx)
+
+		if value := c.GetHeader(debugHeader); cfg.DebugTrust.Allowed(value, traceID, c.RemoteIP()) {
+			var release func()
+			ctx, release = kitlog.WithDebugLog(ctx, strings.TrimSpace(value))
+			defer release()
 		}
 
-		pSpanID := strings.TrimSpace(c.GetHeader(pSpanHeader))
-		if pSpanID == "" {
-			pSpanID = kitlog.Unknown
+		if cfg.TailBuffer {
+			var buf *kitlog.TailBuffer
+			ctx, buf = kitlog.WithTailBuffer(ctx, cfg.TailBufferSize)
+			defer finishTailBuffer(c, buf)
 		}
 
-		ctx := kitlog.WithTraceID(c.Request.Context(), traceID)
-		ctx = kitlog.WithSpanID(ctx, spanID)
-		ctx = kitlog.WithPSpanID(ctx, pSpanID)
-
 		c.Request = c.Request.WithContext(ctx)
 
+		if generated {
+			kitlog.Named(loggerName).Debug("trace id generated", append(
+				kitlog.FromContext(ctx),
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+			)...)
+		}
+
 		c.Set(TraceIDContextKey, traceID)
 		c.Set(SpanIDContextKey, spanID)
 		c.Set(PSpanIDContextKey, pSpanID)
@@ -85,3 +139,57 @@ func GinTraceIDWithConfig(cfg TraceIDConfig) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+func serverSpanName(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return c.Request.Method + " " + route
+	}
+	return c.Request.Method
+}
+
+func httpServerAttributes(c *gin.Context) []zap.Field {
+	fields := []zap.Field{
+		zap.String("http.request.method", c.Request.Method),
+		zap.String("url.path", c.Request.URL.Path),
+		zap.String("client.address", c.ClientIP()),
+	}
+	if route := c.FullPath(); route != "" {
+		fields = append(fields, zap.String("http.route", route))
+	}
+	return fields
+}
+
+// finishSpan ends the server span of the request. A 5xx status, c.Errors or
+// a panic passing through the middleware fail the span.
+func finishSpan(c *gin.Context, span *trace.Span) {
+	defer span.End()
+	if r := recover(); r != nil {
+		span.SetStatus(trace.StatusError, fmt.Sprint(r))
+		panic(r)
+	}
+
+	status := c.Writer.Status()
+	span.SetAttributes(zap.Int("http.response.status_code", status))
+	if err := c.Errors.Last(); err != nil {
+		span.RecordError(err.Err)
+	}
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(trace.StatusError, http.StatusText(status))
+	} else if len(c.Errors) > 0 {
+		span.SetStatus(trace.StatusError, c.Errors.Last().Error())
+	}
+}
+
+// finishTailBuffer flushes buf if the request failed. A panic passing through
+// the middleware counts as a failure.
+func finishTailBuffer(c *gin.Context, buf *kitlog.TailBuffer) {
+	if r := recover(); r != nil {
+		buf.Flush()
+		panic(r)
+	}
+	if c.Writer.Status() >= http.StatusInternalServerError || len(c.Errors) > 0 {
+		buf.Flush()
+		return
+	}
+	buf.Discard()
+}
@@ -3,12 +3,21 @@ package middleware
 import (
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+	"github.com/NamhaeSusan/my-go-kit/propagation"
+	"github.com/NamhaeSusan/my-go-kit/trace"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestGinTraceID_UsesIncomingHeaders(t *testing.T) {
@@ -140,6 +149,235 @@ func TestGinTraceIDWithConfig_DefaultsWhenHeaderNamesEmpty(t *testing.T) {
 	}
 }
 
+func TestGinTraceIDWithConfig_TraceParent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const traceID, spanID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	traceParent := "00-" + traceID + "-" + spanID + "-01"
+
+	for _, tc := range []struct {
+		name       string
+		propagator propagation.Propagator
+		legacy     bool
+		wantTrace  string
+	}{
+		{name: "traceparent only", wantTrace: traceID},
+		{name: "kit wins", legacy: true, wantTrace: "legacy-trace"},
+		{name: "w3c wins", propagator: propagation.Composite{propagation.W3C{}, propagation.Legacy{}}, legacy: true, wantTrace: traceID},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var sampled, ok bool
+			router := gin.New()
+			router.Use(GinTraceIDWithConfig(TraceIDConfig{Propagator: tc.propagator}))
+			router.GET("/", func(c *gin.Context) {
+				sampled, ok = kitlog.GetSampled(c.Request.Context())
+				c.JSON(http.StatusOK, responseBody{
+					CtxTrace: kitlog.GetTraceID(c.Request.Context()),
+					CtxPSpan: kitlog.GetPSpanID(c.Request.Context()),
+				})
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(propagation.TraceParentHeader, traceParent)
+			if tc.legacy {
+				req.Header.Set(kitlog.TraceHeader, "legacy-trace")
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			body := decodeBody(t, rec)
+			if body.CtxTrace != tc.wantTrace {
+				t.Fatalf("unexpected trace: %q", body.CtxTrace)
+			}
+			if tc.wantTrace == traceID && (body.CtxPSpan != spanID || !ok || !sampled) {
+				t.Fatalf("traceparent was not applied: pspan=%q sampled=%v (%v)", body.CtxPSpan, sampled, ok)
+			}
+			if got := rec.Header().Get(kitlog.TraceHeader); got != tc.wantTrace {
+				t.Fatalf("unexpected trace response header: %q", got)
+			}
+		})
+	}
+}
+
+func TestGinTraceID_LogsGeneratedTraceThroughNamedLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	core, logs := observer.New(zapcore.DebugLevel)
+	prev := zap.L()
+	zap.ReplaceGlobals(zap.New(core))
+	t.Cleanup(func() { zap.ReplaceGlobals(prev) })
+
+	router := buildTestRouter(GinTraceID())
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(kitlog.TraceHeader, "incoming-trace")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	if logs.Len() != 0 {
+		t.Fatalf("incoming trace should not be logged, got: %d", logs.Len())
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if logs.Len() != 1 {
+		t.Fatalf("expected one log entry, got: %d", logs.Len())
+	}
+	entry := logs.All()[0]
+	if entry.LoggerName != loggerName {
+		t.Fatalf("unexpected logger name: %q", entry.LoggerName)
+	}
+	if got := entry.ContextMap()["traceId"]; got != decodeBody(t, rec).CtxTrace {
+		t.Fatalf("unexpected traceId: %#v", got)
+	}
+}
+
+func TestGinTraceIDWithConfig_TailBufferFlushesOnlyFailedRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := filepath.Join(t.TempDir(), "app.log")
+	l, err := kitlog.New(kitlog.Config{Stdout: new(false), FilePath: path})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	restore := kitlog.ReplaceGlobals(l)
+	t.Cleanup(func() {
+		restore()
+		_ = l.Close()
+	})
+
+	router := gin.New()
+	router.Use(GinTraceIDWithConfig(TraceIDConfig{TailBuffer: true}))
+	router.GET("/:status", func(c *gin.Context) {
+		kitlog.Debugf(c.Request.Context(), "debug %s", c.Param("status"))
+		switch c.Param("status") {
+		case "error":
+			_ = c.Error(errors.New("validation failed"))
+			c.Status(http.StatusBadRequest)
+		case "500":
+			c.Status(http.StatusInternalServerError)
+		default:
+			c.Status(http.StatusOK)
+		}
+	})
+
+	for _, status := range []string{"200", "500", "error"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/"+status, nil))
+	}
+	_ = l.Sync()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	logged := string(data)
+	if strings.Contains(logged, "debug 200") {
+		t.Fatalf("successful request should not be logged: %s", logged)
+	}
+	if !strings.Contains(logged, "debug 500") || !strings.Contains(logged, "debug error") {
+		t.Fatalf("failed requests should be flushed: %s", logged)
+	}
+}
+
+func TestGinTraceIDWithConfig_DebugHeaderFromTrustedCaller(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	trust, err := kitlog.NewDebugTrust(kitlog.DebugTrustConfig{Secret: "s3cret", TrustedCIDRs: []string{"10.0.0.0/8"}})
+	if err != nil {
+		t.Fatalf("NewDebugTrust returned error: %v", err)
+	}
+
+	var debug string
+	router := gin.New()
+	router.Use(GinTraceIDWithConfig(TraceIDConfig{DebugTrust: trust}))
+	router.GET("/", func(c *gin.Context) {
+		debug = kitlog.GetDebugLog(c.Request.Context())
+	})
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		value        string
+		want         string
+	}{
+		{name: "trusted network", remoteAddr: "10.0.0.1:5000", value: "1", want: "1"},
+		{name: "untrusted network", remoteAddr: "192.0.2.1:5000", value: "1", want: ""},
+		{name: "spoofed forwarded address", remoteAddr: "192.0.2.1:5000", forwardedFor: "10.0.0.1", value: "1", want: ""},
+		{name: "token", remoteAddr: "192.0.2.1:5000", value: kitlog.DebugToken("s3cret", "trace-1"), want: kitlog.DebugToken("s3cret", "trace-1")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			req.Header.Set(kitlog.TraceHeader, "trace-1")
+			req.Header.Set(kitlog.DebugHeader, tt.value)
+			router.ServeHTTP(httptest.NewRecorder(), req)
+			if debug != tt.want {
+				t.Fatalf("unexpected debug flag: %q", debug)
+			}
+		})
+	}
+}
+
+func TestGinTraceID_RecordsServerSpan(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var span *trace.Span
+	router := gin.New()
+	router.Use(GinTraceID())
+	router.GET("/orders/:id", func(c *gin.Context) {
+		span = trace.SpanFromContext(c.Request.Context())
+		if span.Ended() {
+			t.Error("the span should run during the handler")
+		}
+		_ = c.Error(errors.New("db down"))
+		c.Status(http.StatusServiceUnavailable)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/1", nil)
+	req.Header.Set(kitlog.TraceHeader, "incoming-trace")
+	req.Header.Set(kitlog.SpanHeader, "incoming-span")
+	req.Header.Set(kitlog.PSpanHeader, "incoming-pspan")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if span.TraceID() != "incoming-trace" || span.SpanID() != "incoming-span" || span.ParentSpanID() != "incoming-pspan" {
+		t.Fatalf("the span should adopt the incoming IDs: %s/%s/%s", span.TraceID(), span.SpanID(), span.ParentSpanID())
+	}
+	if span.Name() != "GET /orders/:id" || span.Kind() != trace.SpanKindServer || !span.Ended() {
+		t.Fatalf("unexpected span: %s %s ended=%v", span.Name(), span.Kind(), span.Ended())
+	}
+	if got := span.Status(); got.Code != trace.StatusError {
+		t.Fatalf("a 5xx response should fail the span: %+v", got)
+	}
+	if events := span.Events(); len(events) != 1 || events[0].Name != "exception" {
+		t.Fatalf("c.Errors should be recorded: %v", events)
+	}
+	attrs := map[string]zap.Field{}
+	for _, f := range span.Attributes() {
+		attrs[f.Key] = f
+	}
+	if attrs["http.route"].String != "/orders/:id" || attrs["url.path"].String != "/orders/1" ||
+		attrs["http.response.status_code"].Integer != http.StatusServiceUnavailable {
+		t.Fatalf("unexpected attributes: %v", span.Attributes())
+	}
+}
+
+func TestGinTraceID_FailsSpanOnPanic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var span *trace.Span
+	router := gin.New()
+	router.Use(gin.CustomRecovery(func(c *gin.Context, _ any) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	router.Use(GinTraceID())
+	router.GET("/", func(c *gin.Context) {
+		span = trace.SpanFromContext(c.Request.Context())
+		panic("boom")
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !span.Ended() || span.Status().Code != trace.StatusError || span.Status().Description != "boom" {
+		t.Fatalf("a panic should fail the span: ended=%v %+v", span.Ended(), span.Status())
+	}
+}
+
 type responseBody struct {
 	CtxTrace string `json:"ctxTrace"`
 	GinTrace string `json:"ginTrace"`
@@ -198,3 +436,54 @@ func decodeBody(t *testing.T, rec *httptest.ResponseRecorder) responseBody {
 	}
 	return body
 }
+
+func TestGinTraceID_SamplesByRouteAndCaller(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rules, err := trace.RuleBased([]trace.SamplingRule{
+		{Name: "GET /healthz", Sampler: trace.NeverSample()},
+	}, trace.AlwaysSample())
+	if err != nil {
+		t.Fatalf("RuleBased returned error: %v", err)
+	}
+	trace.SetSampler(trace.ParentBased(rules))
+	t.Cleanup(func() { trace.SetSampler(nil) })
+
+	var span *trace.Span
+	var sampled, decided bool
+	handler := func(c *gin.Context) {
+		span = trace.SpanFromContext(c.Request.Context())
+		sampled, decided = kitlog.GetSampled(c.Request.Context())
+	}
+	router := gin.New()
+	router.Use(GinTraceID())
+	router.GET("/healthz", handler)
+	router.GET("/orders/:id", handler)
+
+	tests := []struct {
+		name   string
+		path   string
+		header http.Header
+		want   bool
+	}{
+		{name: "route rule", path: "/healthz", want: false},
+		{name: "fallback", path: "/orders/1", want: true},
+		{name: "kit header", path: "/orders/1", header: http.Header{kitlog.TraceHeader: {"t"}, kitlog.SampledHeader: {"0"}}, want: false},
+		{name: "traceparent", path: "/orders/1", header: http.Header{
+			propagation.TraceParentHeader: {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+		}, want: false},
+		{name: "caller overrides rule", path: "/healthz", header: http.Header{kitlog.TraceHeader: {"t"}, kitlog.SampledHeader: {"1"}}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v[0])
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			if span.IsRecording() != tt.want || !decided || sampled != tt.want {
+				t.Fatalf("unexpected decision: recording=%v sampled=%v decided=%v", span.IsRecording(), sampled, decided)
+			}
+		})
+	}
+}
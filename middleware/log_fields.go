@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AddLogFields attaches fields to the request context so that every log
+// written with c.Request.Context() by later handlers includes them.
+func AddLogFields(c *gin.Context, fields ...zap.Field) {
+	if len(fields) == 0 {
+		return
+	}
+	c.Request = c.Request.WithContext(kitlog.WithFields(c.Request.Context(), fields...))
+}
+
+// GinLogFields returns a middleware that attaches the fields returned by fn
+// (e.g. user ID, tenant, route) to the request context.
+func GinLogFields(fn func(c *gin.Context) []zap.Field) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if fn != nil {
+			AddLogFields(c, fn(c)...)
+		}
+		c.Next()
+	}
+}
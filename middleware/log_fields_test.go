@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestGinLogFields_HandlersInheritFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	core, logs := observer.New(zapcore.InfoLevel)
+	prev := zap.L()
+	zap.ReplaceGlobals(zap.New(core))
+	t.Cleanup(func() { zap.ReplaceGlobals(prev) })
+
+	router := gin.New()
+	router.Use(GinTraceID())
+	router.Use(GinLogFields(func(c *gin.Context) []zap.Field {
+		return []zap.Field{zap.String("route", c.FullPath())}
+	}))
+	router.Use(func(c *gin.Context) {
+		AddLogFields(c, zap.String("userId", c.GetHeader("X-User-Id")))
+		c.Next()
+	})
+	router.GET("/orders/:id", func(c *gin.Context) {
+		kitlog.Infof(c.Request.Context(), "handled")
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/1", nil)
+	req.Header.Set("X-User-Id", "u-1")
+	req.Header.Set(kitlog.TraceHeader, "trace-fields")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if logs.Len() != 1 {
+		t.Fatalf("expected one log entry, got: %d", logs.Len())
+	}
+	fields := logs.All()[0].ContextMap()
+	if fields["route"] != "/orders/:id" || fields["userId"] != "u-1" || fields["traceId"] != "trace-fields" {
+		t.Fatalf("unexpected fields: %v", fields)
+	}
+}
+
+func TestGinLogFields_NilFunc(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(GinLogFields(nil))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status: %d", rec.Code)
+	}
+}
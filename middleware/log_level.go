@@ -0,0 +1,12 @@
+package middleware
+
+import (
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+	"github.com/gin-gonic/gin"
+)
+
+// GinLogLevelHandler exposes kitlog.LevelHandler as a Gin handler. Register
+// it for GET and PUT (or POST) on an internal route, e.g. /debug/log/level.
+func GinLogLevelHandler() gin.HandlerFunc {
+	return gin.WrapH(kitlog.LevelHandler())
+}
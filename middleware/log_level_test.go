@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestGinLogLevelHandler_ReadsAndChangesLevel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	prev := kitlog.Level()
+	t.Cleanup(func() { kitlog.SetLevel(prev) })
+	kitlog.SetLevel(zapcore.InfoLevel)
+
+	router := gin.New()
+	router.GET("/log/level", GinLogLevelHandler())
+	router.PUT("/log/level", GinLogLevelHandler())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/log/level", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"level":"info"`) {
+		t.Fatalf("unexpected response: %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/log/level", strings.NewReader(`{"level":"error"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", rec.Code)
+	}
+	if got := kitlog.Level(); got != zapcore.ErrorLevel {
+		t.Fatalf("unexpected level: %v", got)
+	}
+}
@@ -0,0 +1,155 @@
+package propagation
+
+import (
+	"context"
+	"strings"
+
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+)
+
+const (
+	// B3Header is the single-header form of Zipkin B3:
+	// {TraceId}-{SpanId}-{SamplingState}-{ParentSpanId}.
+	B3Header = "b3"
+
+	// The multi-header form of Zipkin B3.
+	B3TraceIDHeader      = "X-B3-TraceId"
+	B3SpanIDHeader       = "X-B3-SpanId"
+	B3ParentSpanIDHeader = "X-B3-ParentSpanId"
+	B3SampledHeader      = "X-B3-Sampled"
+	B3FlagsHeader        = "X-B3-Flags"
+)
+
+// B3 propagates the trace in the Zipkin B3 headers. Like Legacy, the caller
+// picks the span ID of the callee and sends its own span as the parent.
+//
+// Extract reads both forms and prefers the single header. Inject writes the
+// multi-header form, or the single header when SingleHeader is set. B3 trace
+// IDs are 16 or 32 hex characters; other trace IDs are not written.
+type B3 struct {
+	SingleHeader bool
+}
+
+func (b B3) Inject(ctx context.Context, carrier Carrier) {
+	o := outgoingFrom(ctx)
+	traceID := strings.ToLower(o.traceID)
+	if !validB3TraceID(traceID) {
+		return
+	}
+	parent := strings.ToLower(o.parentID)
+	if !validSpanID(parent) {
+		parent = ""
+	}
+	sampling := "0"
+	if sampled(ctx) {
+		sampling = "1"
+	}
+
+	if b.SingleHeader {
+		value := traceID + "-" + o.spanID + "-" + sampling
+		if parent != "" {
+			value += "-" + parent
+		}
+		carrier.Set(B3Header, value)
+		return
+	}
+
+	carrier.Set(B3TraceIDHeader, traceID)
+	carrier.Set(B3SpanIDHeader, o.spanID)
+	if parent != "" {
+		carrier.Set(B3ParentSpanIDHeader, parent)
+	}
+	carrier.Set(B3SampledHeader, sampling)
+}
+
+func (b B3) Extract(ctx context.Context, carrier Carrier) (context.Context, bool) {
+	if value := strings.TrimSpace(carrier.Get(B3Header)); value != "" {
+		return extractB3Single(ctx, value)
+	}
+
+	traceID := strings.ToLower(strings.TrimSpace(carrier.Get(B3TraceIDHeader)))
+	spanID := strings.ToLower(strings.TrimSpace(carrier.Get(B3SpanIDHeader)))
+	if !validB3TraceID(traceID) || !validSpanID(spanID) {
+		return ctx, false
+	}
+	parent := strings.ToLower(strings.TrimSpace(carrier.Get(B3ParentSpanIDHeader)))
+	if parent != "" && !validSpanID(parent) {
+		return ctx, false
+	}
+
+	ctx = withB3(ctx, traceID, spanID, parent)
+	if strings.TrimSpace(carrier.Get(B3FlagsHeader)) == "1" {
+		// debug flag는 sampled를 뜻한다.
+		return kitlog.WithSampled(ctx, true), true
+	}
+	if s, ok := parseB3Sampled(carrier.Get(B3SampledHeader)); ok {
+		ctx = kitlog.WithSampled(ctx, s)
+	}
+	return ctx, true
+}
+
+func (b B3) Fields() []string {
+	if b.SingleHeader {
+		return []string{B3Header}
+	}
+	return []string{B3TraceIDHeader, B3SpanIDHeader, B3ParentSpanIDHeader, B3SampledHeader, B3FlagsHeader}
+}
+
+// extractB3Single reads the single header. A value with only the sampling
+// state carries no trace and is ignored.
+func extractB3Single(ctx context.Context, value string) (context.Context, bool) {
+	parts := strings.Split(strings.ToLower(value), "-")
+	if len(parts) < 2 || len(parts) > 4 {
+		return ctx, false
+	}
+	traceID, spanID := parts[0], parts[1]
+	if !validB3TraceID(traceID) || !validSpanID(spanID) {
+		return ctx, false
+	}
+	var parent string
+	if len(parts) == 4 {
+		parent = parts[3]
+		if !validSpanID(parent) {
+			return ctx, false
+		}
+	}
+	var s, hasSampled bool
+	if len(parts) >= 3 {
+		if s, hasSampled = parseB3Sampled(parts[2]); !hasSampled {
+			return ctx, false
+		}
+	}
+
+	ctx = withB3(ctx, traceID, spanID, parent)
+	if hasSampled {
+		ctx = kitlog.WithSampled(ctx, s)
+	}
+	return ctx, true
+}
+
+func withB3(ctx context.Context, traceID, spanID, parent string) context.Context {
+	ctx = kitlog.WithTraceID(ctx, traceID)
+	ctx = kitlog.WithSpanID(ctx, spanID)
+	if parent != "" {
+		ctx = kitlog.WithPSpanID(ctx, parent)
+	}
+	return ctx
+}
+
+func parseB3Sampled(value string) (sampled, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "true", "d":
+		return true, true
+	case "0", "false":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+func validB3TraceID(id string) bool {
+	if len(id) == 16 {
+		return isLowerHex(id) && strings.Trim(id, "0") != ""
+	}
+	return validTraceID(id)
+}
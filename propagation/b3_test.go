@@ -0,0 +1,143 @@
+package propagation
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+)
+
+const testB3ParentID = "1111111111111111"
+
+func TestB3InjectMultiHeader(t *testing.T) {
+	ctx := kitlog.WithTraceID(context.Background(), testW3CTraceID)
+	ctx = kitlog.WithSpanID(ctx, testW3CSpanID)
+
+	h := http.Header{}
+	B3{}.Inject(ctx, HeaderCarrier(h))
+	if h.Get(B3TraceIDHeader) != testW3CTraceID || h.Get(B3ParentSpanIDHeader) != testW3CSpanID || h.Get(B3SampledHeader) != "1" {
+		t.Fatalf("unexpected headers: %v", h)
+	}
+	if span := h.Get(B3SpanIDHeader); !validSpanID(span) || span == testW3CSpanID {
+		t.Fatalf("the callee should get a new span: %q", span)
+	}
+
+	h = http.Header{}
+	B3{}.Inject(kitlog.WithTraceID(context.Background(), "legacy-trace"), HeaderCarrier(h))
+	if len(h) != 0 {
+		t.Fatalf("a non-hex trace ID cannot be sent as B3: %v", h)
+	}
+}
+
+func TestB3InjectSingleHeader(t *testing.T) {
+	ctx := kitlog.WithTraceID(context.Background(), testW3CTraceID)
+	ctx = kitlog.WithSpanID(ctx, testW3CSpanID)
+	ctx = kitlog.WithSampled(ctx, false)
+
+	carrier := MapCarrier{}
+	B3{SingleHeader: true}.Inject(ctx, carrier)
+	got, ok := B3{}.Extract(context.Background(), carrier)
+	if !ok || len(carrier) != 1 {
+		t.Fatalf("unexpected b3 header: %v", carrier)
+	}
+	if kitlog.GetTraceID(got) != testW3CTraceID || kitlog.GetPSpanID(got) != testW3CSpanID || !validSpanID(kitlog.GetSpanID(got)) {
+		t.Fatalf("unexpected round trip of %q", carrier[B3Header])
+	}
+	if sampled, ok := kitlog.GetSampled(got); !ok || sampled {
+		t.Fatalf("unexpected sampled flag: %v (%v)", sampled, ok)
+	}
+}
+
+func TestB3Extract(t *testing.T) {
+	const traceID64 = "a3ce929d0e0e4736"
+	for _, tc := range []struct {
+		name        string
+		carrier     MapCarrier
+		ok          bool
+		wantTrace   string
+		wantPSpan   string
+		wantSampled string
+	}{
+		{
+			name:        "single",
+			carrier:     MapCarrier{B3Header: testW3CTraceID + "-" + testW3CSpanID + "-1-" + testB3ParentID},
+			ok:          true,
+			wantTrace:   testW3CTraceID,
+			wantPSpan:   testB3ParentID,
+			wantSampled: "true",
+		},
+		{
+			name:      "single without sampling",
+			carrier:   MapCarrier{B3Header: traceID64 + "-" + testW3CSpanID},
+			ok:        true,
+			wantTrace: traceID64,
+			wantPSpan: kitlog.Unknown,
+		},
+		{
+			name: "single wins over multi",
+			carrier: MapCarrier{
+				B3Header:        testW3CTraceID + "-" + testW3CSpanID + "-d",
+				B3TraceIDHeader: traceID64,
+				B3SpanIDHeader:  testW3CSpanID,
+			},
+			ok:          true,
+			wantTrace:   testW3CTraceID,
+			wantPSpan:   kitlog.Unknown,
+			wantSampled: "true",
+		},
+		{
+			name: "multi",
+			carrier: MapCarrier{
+				B3TraceIDHeader:      traceID64,
+				B3SpanIDHeader:       testW3CSpanID,
+				B3ParentSpanIDHeader: testB3ParentID,
+				B3SampledHeader:      "0",
+			},
+			ok:          true,
+			wantTrace:   traceID64,
+			wantPSpan:   testB3ParentID,
+			wantSampled: "false",
+		},
+		{
+			name: "multi debug",
+			carrier: MapCarrier{
+				B3TraceIDHeader: traceID64,
+				B3SpanIDHeader:  testW3CSpanID,
+				B3SampledHeader: "0",
+				B3FlagsHeader:   "1",
+			},
+			ok:          true,
+			wantTrace:   traceID64,
+			wantPSpan:   kitlog.Unknown,
+			wantSampled: "true",
+		},
+		{name: "sampling only", carrier: MapCarrier{B3Header: "0"}},
+		{name: "bad sampling", carrier: MapCarrier{B3Header: testW3CTraceID + "-" + testW3CSpanID + "-x"}},
+		{name: "bad parent", carrier: MapCarrier{B3TraceIDHeader: traceID64, B3SpanIDHeader: testW3CSpanID, B3ParentSpanIDHeader: "parent"}},
+		{name: "missing span", carrier: MapCarrier{B3TraceIDHeader: traceID64}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, ok := B3{}.Extract(context.Background(), tc.carrier)
+			if ok != tc.ok {
+				t.Fatalf("unexpected ok: %v", ok)
+			}
+			if !ok {
+				if kitlog.GetTraceID(ctx) != kitlog.Unknown {
+					t.Fatalf("ctx should be unchanged: %s", kitlog.GetTraceID(ctx))
+				}
+				return
+			}
+			if kitlog.GetTraceID(ctx) != tc.wantTrace || kitlog.GetSpanID(ctx) != testW3CSpanID || kitlog.GetPSpanID(ctx) != tc.wantPSpan {
+				t.Fatalf("unexpected trace: %s/%s/%s", kitlog.GetTraceID(ctx), kitlog.GetSpanID(ctx), kitlog.GetPSpanID(ctx))
+			}
+			sampled, hasSampled := kitlog.GetSampled(ctx)
+			switch {
+			case tc.wantSampled == "" && hasSampled,
+				tc.wantSampled == "true" && (!hasSampled || !sampled),
+				tc.wantSampled == "false" && (!hasSampled || sampled):
+				t.Fatalf("unexpected sampled flag: %v (%v), want %s", sampled, hasSampled, tc.wantSampled)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package propagation
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+)
+
+// Composite combines propagators, typically to move between header formats
+// without breaking the services that still use the old one.
+//
+// Inject writes every format for the same new span. Extract uses the first
+// member that finds a trace; the members after it only fill the span,
+// pSpanId, sampled flag and tracestate it did not carry, and only when they
+// see the same trace. A request with different traces in two formats
+// therefore follows the earlier member.
+type Composite []Propagator
+
+func (c Composite) Inject(ctx context.Context, carrier Carrier) {
+	ctx = context.WithValue(ctx, outgoingKey, outgoingFrom(ctx))
+	for _, p := range c {
+		p.Inject(ctx, carrier)
+	}
+}
+
+func (c Composite) Extract(ctx context.Context, carrier Carrier) (context.Context, bool) {
+	var found context.Context
+	for _, p := range c {
+		if found == nil {
+			if out, ok := p.Extract(clearTrace(ctx), carrier); ok {
+				found = out
+			}
+			continue
+		}
+
+		other, ok := p.Extract(context.Background(), carrier)
+		if !ok || !strings.EqualFold(kitlog.GetTraceID(other), kitlog.GetTraceID(found)) {
+			continue
+		}
+		found = fillTrace(found, other)
+	}
+
+	if found == nil {
+		return ctx, false
+	}
+	return found, true
+}
+
+func (c Composite) Fields() []string {
+	var fields []string
+	for _, p := range c {
+		for _, field := range p.Fields() {
+			if !slices.ContainsFunc(fields, func(f string) bool { return strings.EqualFold(f, field) }) {
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields
+}
+
+// fillTrace copies the values of other that ctx is missing.
+func fillTrace(ctx, other context.Context) context.Context {
+	if kitlog.GetSpanID(ctx) == kitlog.Unknown {
+		if spanID := kitlog.GetSpanID(other); spanID != kitlog.Unknown {
+			ctx = kitlog.WithSpanID(ctx, spanID)
+		}
+	}
+	if kitlog.GetPSpanID(ctx) == kitlog.Unknown {
+		if pSpanID := kitlog.GetPSpanID(other); pSpanID != kitlog.Unknown {
+			ctx = kitlog.WithPSpanID(ctx, pSpanID)
+		}
+	}
+	if _, ok := kitlog.GetSampled(ctx); !ok {
+		if s, ok := kitlog.GetSampled(other); ok {
+			ctx = kitlog.WithSampled(ctx, s)
+		}
+	}
+	if kitlog.GetTraceState(ctx) == "" {
+		if state := kitlog.GetTraceState(other); state != "" {
+			ctx = kitlog.WithTraceState(ctx, state)
+		}
+	}
+	return ctx
+}
@@ -0,0 +1,81 @@
+package propagation
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+)
+
+func TestCompositeExtractFollowsOrder(t *testing.T) {
+	carrier := MapCarrier{
+		kitlog.TraceHeader: "legacy-trace",
+		kitlog.SpanHeader:  "legacy-span",
+		kitlog.PSpanHeader: "legacy-pspan",
+		TraceParentHeader:  testTraceParent,
+		TraceStateHeader:   "vendor=abc",
+	}
+
+	ctx, _ := ExtractContext(context.Background(), Composite{Legacy{}, W3C{}}, carrier)
+	if kitlog.GetTraceID(ctx) != "legacy-trace" || kitlog.GetSpanID(ctx) != "legacy-span" || kitlog.GetPSpanID(ctx) != "legacy-pspan" {
+		t.Fatalf("kit headers should win: %s/%s/%s", kitlog.GetTraceID(ctx), kitlog.GetSpanID(ctx), kitlog.GetPSpanID(ctx))
+	}
+	if _, ok := kitlog.GetSampled(ctx); ok || kitlog.GetTraceState(ctx) != "" {
+		t.Fatal("the flags of another trace should be ignored")
+	}
+
+	ctx, _ = ExtractContext(context.Background(), Composite{W3C{}, Legacy{}}, carrier)
+	if kitlog.GetTraceID(ctx) != testW3CTraceID || kitlog.GetPSpanID(ctx) != testW3CSpanID || kitlog.GetSpanID(ctx) == "legacy-span" {
+		t.Fatalf("traceparent should win: %s/%s/%s", kitlog.GetTraceID(ctx), kitlog.GetSpanID(ctx), kitlog.GetPSpanID(ctx))
+	}
+
+	// 같은 trace면 kit 헤더가 정한 span과 traceparent의 flag를 함께 쓴다.
+	carrier[kitlog.TraceHeader], carrier[kitlog.SpanHeader] = testW3CTraceID, "1111111111111111"
+	delete(carrier, kitlog.PSpanHeader)
+	ctx, _ = ExtractContext(context.Background(), Composite{Legacy{}, W3C{}}, carrier)
+	if sampled, ok := kitlog.GetSampled(ctx); !ok || !sampled || kitlog.GetSpanID(ctx) != "1111111111111111" {
+		t.Fatalf("unexpected merge: span=%s sampled=%v", kitlog.GetSpanID(ctx), sampled)
+	}
+	if kitlog.GetPSpanID(ctx) != testW3CSpanID || kitlog.GetTraceState(ctx) != "vendor=abc" {
+		t.Fatalf("missing values should come from traceparent: %s %q", kitlog.GetPSpanID(ctx), kitlog.GetTraceState(ctx))
+	}
+}
+
+func TestCompositeExtractWithoutTrace(t *testing.T) {
+	parent := kitlog.WithTraceID(context.Background(), "outer-trace")
+	ctx, ok := Composite{Legacy{}, W3C{}}.Extract(parent, MapCarrier{})
+	if ok || ctx != parent {
+		t.Fatal("the context should be unchanged without a trace")
+	}
+}
+
+func TestCompositeInjectSharesSpan(t *testing.T) {
+	ctx := kitlog.WithTraceID(context.Background(), testW3CTraceID)
+	ctx = kitlog.WithSpanID(ctx, testW3CSpanID)
+
+	carrier := MapCarrier{}
+	Composite{Legacy{}, W3C{}, B3{}}.Inject(ctx, carrier)
+	if carrier[kitlog.SpanHeader] != carrier[B3SpanIDHeader] {
+		t.Fatalf("every format should send the same span: %v", carrier)
+	}
+	if carrier[kitlog.PSpanHeader] != testW3CSpanID || carrier[TraceParentHeader] != testTraceParent {
+		t.Fatalf("unexpected headers: %v", carrier)
+	}
+
+	// trace가 없으면 모든 형식이 같은 새 trace를 보낸다.
+	carrier = MapCarrier{}
+	Composite{Legacy{}, W3C{}}.Inject(context.Background(), carrier)
+	p, ok := ParseTraceParent(carrier[TraceParentHeader])
+	if !ok || p.TraceID != carrier[kitlog.TraceHeader] || p.SpanID != carrier[kitlog.SpanHeader] {
+		t.Fatalf("unexpected headers for a new trace: %v", carrier)
+	}
+}
+
+func TestCompositeFields(t *testing.T) {
+	got := Composite{Legacy{}, W3C{}, Legacy{}}.Fields()
+	want := []string{kitlog.TraceHeader, kitlog.SpanHeader, kitlog.PSpanHeader, kitlog.SampledHeader, TraceParentHeader, TraceStateHeader}
+	if !slices.Equal(got, want) {
+		t.Fatalf("unexpected fields: %v", got)
+	}
+}
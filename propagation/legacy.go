@@ -0,0 +1,87 @@
+package propagation
+
+import (
+	"context"
+	"strings"
+
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+)
+
+// Legacy propagates the trace in the kit headers kitlog.TraceHeader,
+// kitlog.SpanHeader and kitlog.PSpanHeader. The caller picks the span ID of
+// the callee and sends its own span as the pSpanId. A sampling decision
+// stored in ctx is sent in kitlog.SampledHeader. Empty names use the kit
+// headers.
+type Legacy struct {
+	TraceHeader   string
+	SpanHeader    string
+	PSpanHeader   string
+	SampledHeader string
+}
+
+func (l Legacy) headers() (trace, span, pSpan, sampled string) {
+	trace, span, pSpan, sampled = l.TraceHeader, l.SpanHeader, l.PSpanHeader, l.SampledHeader
+	if trace == "" {
+		trace = kitlog.TraceHeader
+	}
+	if span == "" {
+		span = kitlog.SpanHeader
+	}
+	if pSpan == "" {
+		pSpan = kitlog.PSpanHeader
+	}
+	if sampled == "" {
+		sampled = kitlog.SampledHeader
+	}
+	return trace, span, pSpan, sampled
+}
+
+func (l Legacy) Inject(ctx context.Context, carrier Carrier) {
+	traceHeader, spanHeader, pSpanHeader, sampledHeader := l.headers()
+	o := outgoingFrom(ctx)
+
+	// 현재 span이 다음 서비스의 pSpanId가 되고 다음 spanId는 새로 만든다.
+	parent := o.parentID
+	if parent == "" {
+		parent = kitlog.Unknown
+	}
+	carrier.Set(traceHeader, o.traceID)
+	carrier.Set(spanHeader, o.spanID)
+	carrier.Set(pSpanHeader, parent)
+	// 결정이 없으면 보내지 않고 다음 서비스가 정하게 둔다.
+	if s, ok := kitlog.GetSampled(ctx); ok {
+		value := "0"
+		if s {
+			value = "1"
+		}
+		carrier.Set(sampledHeader, value)
+	}
+}
+
+func (l Legacy) Extract(ctx context.Context, carrier Carrier) (context.Context, bool) {
+	traceHeader, spanHeader, pSpanHeader, sampledHeader := l.headers()
+	traceID := strings.TrimSpace(carrier.Get(traceHeader))
+	if traceID == "" {
+		return ctx, false
+	}
+
+	ctx = kitlog.WithTraceID(ctx, traceID)
+	if spanID := strings.TrimSpace(carrier.Get(spanHeader)); spanID != "" {
+		ctx = kitlog.WithSpanID(ctx, spanID)
+	}
+	if pSpanID := strings.TrimSpace(carrier.Get(pSpanHeader)); pSpanID != "" {
+		ctx = kitlog.WithPSpanID(ctx, pSpanID)
+	}
+	switch strings.TrimSpace(carrier.Get(sampledHeader)) {
+	case "1":
+		ctx = kitlog.WithSampled(ctx, true)
+	case "0":
+		ctx = kitlog.WithSampled(ctx, false)
+	}
+	return ctx, true
+}
+
+func (l Legacy) Fields() []string {
+	traceHeader, spanHeader, pSpanHeader, sampledHeader := l.headers()
+	return []string{traceHeader, spanHeader, pSpanHeader, sampledHeader}
+}
@@ -0,0 +1,68 @@
+package propagation
+
+import (
+	"context"
+	"testing"
+
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+)
+
+func TestLegacyRoundTrip(t *testing.T) {
+	ctx := kitlog.WithTraceID(context.Background(), "trace-ctx-1")
+	ctx = kitlog.WithSpanID(ctx, "span-ctx-1")
+
+	carrier := MapCarrier{}
+	Legacy{}.Inject(ctx, carrier)
+	if carrier[kitlog.TraceHeader] != "trace-ctx-1" || carrier[kitlog.PSpanHeader] != "span-ctx-1" {
+		t.Fatalf("unexpected headers: %v", carrier)
+	}
+	if span := carrier[kitlog.SpanHeader]; span == "" || span == "span-ctx-1" {
+		t.Fatalf("the callee should get a new span: %q", span)
+	}
+
+	got, ok := Legacy{}.Extract(context.Background(), carrier)
+	if !ok || kitlog.GetTraceID(got) != "trace-ctx-1" || kitlog.GetSpanID(got) != carrier[kitlog.SpanHeader] || kitlog.GetPSpanID(got) != "span-ctx-1" {
+		t.Fatalf("unexpected extracted trace: %s/%s/%s", kitlog.GetTraceID(got), kitlog.GetSpanID(got), kitlog.GetPSpanID(got))
+	}
+}
+
+func TestLegacyCustomHeaders(t *testing.T) {
+	p := Legacy{TraceHeader: "X-Request-Id"}
+	if fields := p.Fields(); fields[0] != "X-Request-Id" || fields[1] != kitlog.SpanHeader || fields[2] != kitlog.PSpanHeader {
+		t.Fatalf("unexpected fields: %v", fields)
+	}
+
+	carrier := MapCarrier{kitlog.TraceHeader: "ignored", "X-Request-Id": " custom "}
+	ctx, ok := p.Extract(context.Background(), carrier)
+	if !ok || kitlog.GetTraceID(ctx) != "custom" || kitlog.GetSpanID(ctx) != kitlog.Unknown {
+		t.Fatalf("unexpected extracted trace: %s/%s", kitlog.GetTraceID(ctx), kitlog.GetSpanID(ctx))
+	}
+
+	if _, ok := p.Extract(context.Background(), MapCarrier{"X-Request-Id": "  "}); ok {
+		t.Fatal("an empty trace header should not be extracted")
+	}
+}
+
+func TestLegacySampledHeader(t *testing.T) {
+	ctx := kitlog.WithTraceID(context.Background(), "trace-ctx-1")
+
+	carrier := MapCarrier{}
+	Legacy{}.Inject(ctx, carrier)
+	if _, ok := carrier[kitlog.SampledHeader]; ok {
+		t.Fatalf("no decision should be sent without one: %v", carrier)
+	}
+
+	for _, sampled := range []bool{true, false} {
+		carrier := MapCarrier{}
+		Legacy{}.Inject(kitlog.WithSampled(ctx, sampled), carrier)
+		got, _ := Legacy{}.Extract(context.Background(), carrier)
+		if s, ok := kitlog.GetSampled(got); !ok || s != sampled {
+			t.Fatalf("the decision should round-trip: %v -> %v/%v (%v)", sampled, s, ok, carrier)
+		}
+	}
+
+	got, _ := Legacy{}.Extract(context.Background(), MapCarrier{kitlog.TraceHeader: "t", kitlog.SampledHeader: "yes"})
+	if _, ok := kitlog.GetSampled(got); ok {
+		t.Fatal("an invalid decision should be ignored")
+	}
+}
@@ -0,0 +1,191 @@
+// Package propagation reads the trace of an incoming request from its headers
+// and writes the trace of the current context to an outgoing one. A single
+// Propagator drives the Gin middleware, httpclient and the gRPC interceptors.
+package propagation
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+)
+
+// Carrier holds the headers of a request, such as http.Header or gRPC
+// metadata.
+type Carrier interface {
+	// Get returns the first value of key, or "".
+	Get(key string) string
+	// Set replaces the values of key.
+	Set(key, value string)
+}
+
+// ValuesCarrier is a Carrier that can hold several values for a key.
+// Propagators join them for headers that may be split, such as tracestate.
+type ValuesCarrier interface {
+	Carrier
+	Values(key string) []string
+}
+
+// HeaderCarrier adapts http.Header to Carrier.
+type HeaderCarrier http.Header
+
+func (h HeaderCarrier) Get(key string) string {
+	return http.Header(h).Get(key)
+}
+
+func (h HeaderCarrier) Set(key, value string) {
+	http.Header(h).Set(key, value)
+}
+
+func (h HeaderCarrier) Values(key string) []string {
+	return http.Header(h).Values(key)
+}
+
+// MapCarrier is a Carrier backed by a map. Keys are case-sensitive.
+type MapCarrier map[string]string
+
+func (m MapCarrier) Get(key string) string {
+	return m[key]
+}
+
+func (m MapCarrier) Set(key, value string) {
+	m[key] = value
+}
+
+// Propagator writes the trace of a context to a carrier and reads it back.
+type Propagator interface {
+	// Inject writes the trace of ctx for a downstream call. The callee gets
+	// a new span whose parent is the span of ctx.
+	Inject(ctx context.Context, carrier Carrier)
+	// Extract stores the trace found in carrier in ctx. ok is false when the
+	// carrier has none; ctx is then returned unchanged. Only the values
+	// present in the carrier are stored.
+	Extract(ctx context.Context, carrier Carrier) (_ context.Context, ok bool)
+	// Fields returns the keys Inject may set, so that stale values can be
+	// removed from a reused carrier.
+	Fields() []string
+}
+
+var defaultPropagator atomic.Pointer[Propagator]
+
+func init() {
+	SetDefault(Composite{Legacy{}, W3C{}})
+}
+
+// Default returns the Propagator used by the integrations that are not given
+// one. It reads the kit headers before traceparent and writes both.
+func Default() Propagator {
+	return *defaultPropagator.Load()
+}
+
+// SetDefault replaces the Propagator returned by Default. A nil p is ignored.
+func SetDefault(p Propagator) {
+	if p == nil {
+		return
+	}
+	defaultPropagator.Store(&p)
+}
+
+// ExtractContext stores the trace of an incoming request in ctx, as the
+// server integrations do. A trace already stored in ctx is replaced. Missing
+// IDs are generated, the pSpanId falls back to kitlog.Unknown, and generated
+// reports a new trace ID.
+func ExtractContext(ctx context.Context, p Propagator, carrier Carrier) (_ context.Context, generated bool) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if p == nil {
+		p = Default()
+	}
+
+	ctx, _ = p.Extract(clearTrace(ctx), carrier)
+	generated = kitlog.GetTraceID(ctx) == kitlog.Unknown
+	if generated {
+		ctx = kitlog.WithTraceID(ctx, kitlog.NewTraceID())
+	}
+	if kitlog.GetSpanID(ctx) == kitlog.Unknown {
+		ctx = kitlog.WithSpanID(ctx, kitlog.NewSpanID())
+	}
+	if kitlog.GetPSpanID(ctx) == kitlog.Unknown {
+		ctx = kitlog.WithPSpanID(ctx, kitlog.Unknown)
+	}
+	return ctx, generated
+}
+
+// clearTrace hides the trace stored in ctx so that a Propagator only finds
+// what it extracts.
+func clearTrace(ctx context.Context) context.Context {
+	ctx = kitlog.WithTraceID(ctx, "")
+	ctx = kitlog.WithSpanID(ctx, "")
+	ctx = kitlog.WithPSpanID(ctx, "")
+	// nil은 bool이 아니므로 GetSampled가 ok=false를 돌려준다.
+	ctx = context.WithValue(ctx, kitlog.SampledKey, nil)
+	return kitlog.WithTraceState(ctx, "")
+}
+
+type outgoingKeyType struct{}
+
+var outgoingKey outgoingKeyType
+
+// outgoing is the trace sent to a downstream call.
+type outgoing struct {
+	traceID string
+	// spanID is the new span of the callee and parentID the span of ctx, or
+	// "" without one.
+	spanID   string
+	parentID string
+}
+
+// outgoingFrom returns the trace to send from ctx. Within Composite.Inject
+// every member gets the same one, so the callee sees a single new span.
+func outgoingFrom(ctx context.Context) outgoing {
+	if o, ok := ctx.Value(outgoingKey).(outgoing); ok {
+		return o
+	}
+
+	o := outgoing{
+		traceID: kitlog.GetTraceID(ctx),
+		spanID:  kitlog.NewSpanID(),
+	}
+	if o.traceID == kitlog.Unknown {
+		o.traceID = kitlog.NewTraceID()
+	}
+	if parent := kitlog.GetSpanID(ctx); parent != kitlog.Unknown {
+		o.parentID = parent
+	}
+	return o
+}
+
+// sampled returns the sampled flag of ctx, true when no one decided.
+func sampled(ctx context.Context) bool {
+	s, ok := kitlog.GetSampled(ctx)
+	return s || !ok
+}
+
+// joinedValues returns the values of key joined with ",".
+func joinedValues(carrier Carrier, key string) string {
+	if vc, ok := carrier.(ValuesCarrier); ok {
+		return strings.Join(vc.Values(key), ",")
+	}
+	return carrier.Get(key)
+}
+
+func validTraceID(id string) bool {
+	return len(id) == 32 && isLowerHex(id) && strings.Trim(id, "0") != ""
+}
+
+func validSpanID(id string) bool {
+	return len(id) == 16 && isLowerHex(id) && strings.Trim(id, "0") != ""
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
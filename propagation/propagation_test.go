@@ -0,0 +1,73 @@
+package propagation
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+)
+
+func TestExtractContextReplacesTrace(t *testing.T) {
+	ctx := kitlog.WithTraceID(context.Background(), "stale-trace")
+	ctx = kitlog.WithSpanID(ctx, "stale-span")
+	ctx = kitlog.WithPSpanID(ctx, "stale-pspan")
+	ctx = kitlog.WithSampled(ctx, false)
+	ctx = kitlog.WithTraceState(ctx, "stale=1")
+
+	got, generated := ExtractContext(ctx, Legacy{}, MapCarrier{kitlog.TraceHeader: "incoming-trace"})
+	if generated || kitlog.GetTraceID(got) != "incoming-trace" {
+		t.Fatalf("unexpected trace: %s (%v)", kitlog.GetTraceID(got), generated)
+	}
+	if span := kitlog.GetSpanID(got); span == "stale-span" || !validSpanID(span) {
+		t.Fatalf("the span should be generated: %q", span)
+	}
+	if kitlog.GetPSpanID(got) != kitlog.Unknown {
+		t.Fatalf("pspan should fall back to unknown: %q", kitlog.GetPSpanID(got))
+	}
+	if _, ok := kitlog.GetSampled(got); ok || kitlog.GetTraceState(got) != "" {
+		t.Fatal("the flags of the previous trace should be dropped")
+	}
+
+	got, generated = ExtractContext(ctx, Legacy{}, MapCarrier{})
+	if !generated || !validTraceID(kitlog.GetTraceID(got)) {
+		t.Fatalf("a trace should be generated: %s (%v)", kitlog.GetTraceID(got), generated)
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	prev := Default()
+	t.Cleanup(func() { SetDefault(prev) })
+
+	if _, ok := prev.(Composite); !ok {
+		t.Fatalf("unexpected initial default: %T", prev)
+	}
+
+	SetDefault(B3{})
+	SetDefault(nil)
+	if _, ok := Default().(B3); !ok {
+		t.Fatalf("unexpected default: %T", Default())
+	}
+
+	ctx, _ := ExtractContext(context.Background(), nil, MapCarrier{
+		B3TraceIDHeader: testW3CTraceID,
+		B3SpanIDHeader:  testW3CSpanID,
+	})
+	if kitlog.GetTraceID(ctx) != testW3CTraceID || kitlog.GetSpanID(ctx) != testW3CSpanID {
+		t.Fatalf("nil should use the default: %s/%s", kitlog.GetTraceID(ctx), kitlog.GetSpanID(ctx))
+	}
+}
+
+func TestHeaderCarrier(t *testing.T) {
+	h := http.Header{}
+	c := HeaderCarrier(h)
+	c.Set("x-trace-id", "a")
+	c.Set(kitlog.TraceHeader, "b")
+	h.Add(TraceStateHeader, "c")
+	if c.Get(kitlog.TraceHeader) != "b" || len(h) != 2 {
+		t.Fatalf("keys should be canonical: %v", h)
+	}
+	if got := joinedValues(c, TraceStateHeader); got != "c" {
+		t.Fatalf("unexpected values: %q", got)
+	}
+}
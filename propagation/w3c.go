@@ -0,0 +1,115 @@
+package propagation
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+)
+
+const (
+	// TraceParentHeader and TraceStateHeader are the W3C Trace Context
+	// headers.
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+
+	traceParentVersion = "00"
+	flagSampled        = 0x01
+	// maxTraceStateLength is the length above which a tracestate may be
+	// dropped by W3C Trace Context.
+	maxTraceStateLength = 512
+)
+
+// W3C propagates the trace in the W3C Trace Context headers traceparent and
+// tracestate.
+//
+// On Extract the parent-id of traceparent becomes the pSpanId; the span of
+// this service is left to ExtractContext to generate. The sampled flag and
+// tracestate are kept for downstream calls. Inject only writes a trace ID
+// that is valid for W3C; the parent-id is the span of ctx, or the new span
+// of the callee without one, and the trace is sampled unless ctx says
+// otherwise.
+type W3C struct{}
+
+func (W3C) Inject(ctx context.Context, carrier Carrier) {
+	o := outgoingFrom(ctx)
+	traceID := strings.ToLower(o.traceID)
+	if !validTraceID(traceID) {
+		return
+	}
+	parent := strings.ToLower(o.parentID)
+	if !validSpanID(parent) {
+		parent = o.spanID
+	}
+
+	tp := TraceParent{TraceID: traceID, SpanID: parent}
+	if sampled(ctx) {
+		tp.Flags = flagSampled
+	}
+	carrier.Set(TraceParentHeader, tp.String())
+	if state := kitlog.GetTraceState(ctx); state != "" {
+		carrier.Set(TraceStateHeader, state)
+	}
+}
+
+func (W3C) Extract(ctx context.Context, carrier Carrier) (context.Context, bool) {
+	tp, ok := ParseTraceParent(carrier.Get(TraceParentHeader))
+	if !ok {
+		return ctx, false
+	}
+
+	ctx = kitlog.WithTraceID(ctx, tp.TraceID)
+	ctx = kitlog.WithPSpanID(ctx, tp.SpanID)
+	ctx = kitlog.WithSampled(ctx, tp.Sampled())
+	if state := strings.TrimSpace(joinedValues(carrier, TraceStateHeader)); state != "" && len(state) <= maxTraceStateLength {
+		ctx = kitlog.WithTraceState(ctx, state)
+	}
+	return ctx, true
+}
+
+func (W3C) Fields() []string {
+	return []string{TraceParentHeader, TraceStateHeader}
+}
+
+// TraceParent is a W3C traceparent header: 00-<trace-id>-<parent-id>-<flags>.
+type TraceParent struct {
+	// TraceID is 32 lowercase hex characters.
+	TraceID string
+	// SpanID is the parent-id, the span of the caller: 16 lowercase hex
+	// characters.
+	SpanID string
+	Flags  byte
+}
+
+// ParseTraceParent parses a traceparent header. Versions above 00 are read
+// as 00, as W3C Trace Context requires; ok is false for an invalid value.
+func ParseTraceParent(value string) (p TraceParent, ok bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return TraceParent{}, false
+	}
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	if len(version) != 2 || !isLowerHex(version) || version == "ff" {
+		return TraceParent{}, false
+	}
+	if version == traceParentVersion && len(parts) != 4 {
+		return TraceParent{}, false
+	}
+	if !validTraceID(traceID) || !validSpanID(spanID) || len(flags) != 2 || !isLowerHex(flags) {
+		return TraceParent{}, false
+	}
+
+	b, _ := hex.DecodeString(flags)
+	return TraceParent{TraceID: traceID, SpanID: spanID, Flags: b[0]}, true
+}
+
+// Sampled reports whether the caller may have recorded the trace.
+func (p TraceParent) Sampled() bool {
+	return p.Flags&flagSampled != 0
+}
+
+// String formats p as a version 00 traceparent.
+func (p TraceParent) String() string {
+	return traceParentVersion + "-" + p.TraceID + "-" + p.SpanID + "-" + hex.EncodeToString([]byte{p.Flags})
+}
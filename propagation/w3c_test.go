@@ -0,0 +1,108 @@
+package propagation
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+)
+
+const (
+	testW3CTraceID  = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testW3CSpanID   = "00f067aa0ba902b7"
+	testTraceParent = "00-" + testW3CTraceID + "-" + testW3CSpanID + "-01"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	p, ok := ParseTraceParent(" " + testTraceParent + " ")
+	if !ok || p.TraceID != testW3CTraceID || p.SpanID != testW3CSpanID || !p.Sampled() {
+		t.Fatalf("unexpected traceparent: %+v (%v)", p, ok)
+	}
+	if p.String() != testTraceParent {
+		t.Fatalf("unexpected string: %q", p.String())
+	}
+	// 상위 버전은 뒤에 붙은 필드를 무시하고 00으로 읽는다.
+	if p, ok := ParseTraceParent("01-" + testW3CTraceID + "-" + testW3CSpanID + "-00-extra"); !ok || p.Sampled() {
+		t.Fatalf("a future version should be accepted: %+v (%v)", p, ok)
+	}
+
+	for _, value := range []string{
+		"",
+		"00-" + testW3CTraceID + "-" + testW3CSpanID,
+		"00-" + testW3CTraceID + "-" + testW3CSpanID + "-01-extra",
+		"ff-" + testW3CTraceID + "-" + testW3CSpanID + "-01",
+		"00-" + strings.ToUpper(testW3CTraceID) + "-" + testW3CSpanID + "-01",
+		"00-" + strings.Repeat("0", 32) + "-" + testW3CSpanID + "-01",
+		"00-" + testW3CTraceID + "-" + strings.Repeat("0", 16) + "-01",
+		"00-" + testW3CTraceID + "-" + testW3CSpanID + "-1",
+	} {
+		if _, ok := ParseTraceParent(value); ok {
+			t.Errorf("expected %q to be invalid", value)
+		}
+	}
+}
+
+func TestW3CExtract(t *testing.T) {
+	h := http.Header{}
+	h.Set(TraceParentHeader, "00-"+testW3CTraceID+"-"+testW3CSpanID+"-00")
+	h.Add(TraceStateHeader, "vendor=abc")
+	h.Add(TraceStateHeader, "other=def")
+
+	ctx, generated := ExtractContext(context.Background(), W3C{}, HeaderCarrier(h))
+	if generated {
+		t.Fatal("the trace of traceparent should be used")
+	}
+	if kitlog.GetTraceID(ctx) != testW3CTraceID || kitlog.GetPSpanID(ctx) != testW3CSpanID {
+		t.Fatalf("unexpected trace: %s/%s", kitlog.GetTraceID(ctx), kitlog.GetPSpanID(ctx))
+	}
+	if span := kitlog.GetSpanID(ctx); span == testW3CSpanID || !validSpanID(span) {
+		t.Fatalf("the service should get its own span: %q", span)
+	}
+	if sampled, ok := kitlog.GetSampled(ctx); !ok || sampled {
+		t.Fatalf("unexpected sampled flag: %v (%v)", sampled, ok)
+	}
+	if kitlog.GetTraceState(ctx) != "vendor=abc,other=def" {
+		t.Fatalf("unexpected tracestate: %q", kitlog.GetTraceState(ctx))
+	}
+}
+
+func TestW3CExtractIgnoresInvalidTraceParent(t *testing.T) {
+	carrier := MapCarrier{TraceParentHeader: "garbage", TraceStateHeader: "vendor=abc"}
+	if _, ok := (W3C{}).Extract(context.Background(), carrier); ok {
+		t.Fatal("an invalid traceparent should not be extracted")
+	}
+
+	ctx, generated := ExtractContext(context.Background(), W3C{}, carrier)
+	if !generated || kitlog.GetPSpanID(ctx) != kitlog.Unknown || kitlog.GetTraceState(ctx) != "" {
+		t.Fatalf("an invalid traceparent should be ignored: %s %q", kitlog.GetPSpanID(ctx), kitlog.GetTraceState(ctx))
+	}
+}
+
+func TestW3CInject(t *testing.T) {
+	ctx := kitlog.WithTraceID(context.Background(), testW3CTraceID)
+	ctx = kitlog.WithSpanID(ctx, testW3CSpanID)
+	ctx = kitlog.WithSampled(ctx, false)
+	ctx = kitlog.WithTraceState(ctx, "vendor=abc")
+
+	carrier := MapCarrier{}
+	W3C{}.Inject(ctx, carrier)
+	if carrier[TraceParentHeader] != "00-"+testW3CTraceID+"-"+testW3CSpanID+"-00" || carrier[TraceStateHeader] != "vendor=abc" {
+		t.Fatalf("unexpected W3C headers: %v", carrier)
+	}
+
+	// 새 trace는 sampled이며 현재 span이 없으면 새 span이 parent-id가 된다.
+	carrier = MapCarrier{}
+	W3C{}.Inject(context.Background(), carrier)
+	p, ok := ParseTraceParent(carrier[TraceParentHeader])
+	if !ok || !p.Sampled() || p.SpanID == kitlog.Unknown {
+		t.Fatalf("unexpected headers for a new trace: %v", carrier)
+	}
+
+	carrier = MapCarrier{}
+	W3C{}.Inject(kitlog.WithTraceID(context.Background(), "legacy-trace"), carrier)
+	if len(carrier) != 0 {
+		t.Fatalf("a non-W3C trace ID cannot be sent as traceparent: %v", carrier)
+	}
+}
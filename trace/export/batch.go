@@ -0,0 +1,226 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+	"github.com/NamhaeSusan/my-go-kit/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultQueueSize     = 2048
+	defaultBatchSize     = 512
+	defaultFlushInterval = 5 * time.Second
+	defaultExportTimeout = 30 * time.Second
+)
+
+// BatchConfig configures a BatchProcessor.
+type BatchConfig struct {
+	// QueueSize is the number of ended spans waiting for export. Spans that
+	// do not fit are dropped. Defaults to 2048.
+	QueueSize int
+	// BatchSize is the maximum number of spans per Export. Defaults to 512.
+	BatchSize int
+	// FlushInterval exports a partial batch after this long. Defaults to 5s.
+	FlushInterval time.Duration
+	// ExportTimeout bounds each Export. Defaults to 30s.
+	ExportTimeout time.Duration
+}
+
+func checkBatchConfig(cfg *BatchConfig) {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.BatchSize > cfg.QueueSize {
+		cfg.BatchSize = cfg.QueueSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultFlushInterval
+	}
+	if cfg.ExportTimeout <= 0 {
+		cfg.ExportTimeout = defaultExportTimeout
+	}
+}
+
+// BatchStats counts the spans a BatchProcessor has handled.
+type BatchStats struct {
+	// Exported spans were accepted by the exporter.
+	Exported uint64
+	// Dropped spans did not fit in the queue or ended after Shutdown.
+	Dropped uint64
+	// Failed spans were part of a batch the exporter returned an error for.
+	Failed uint64
+}
+
+// BatchProcessor is a trace.Processor that queues ended spans and exports
+// them in batches from a background goroutine, so that End never waits for
+// the network. Register it with trace.RegisterProcessor and call Shutdown
+// before the program exits.
+type BatchProcessor struct {
+	exporter Exporter
+	cfg      BatchConfig
+
+	queue   chan *trace.Span
+	flushes chan chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+
+	shutdownOnce sync.Once
+	// mu makes the closed check and the enqueue of OnEnd atomic with respect
+	// to Shutdown, so that every span is either drained or counted.
+	mu     sync.RWMutex
+	closed bool
+
+	exported atomic.Uint64
+	dropped  atomic.Uint64
+	failed   atomic.Uint64
+}
+
+// NewBatchProcessor starts a BatchProcessor that exports to exporter.
+func NewBatchProcessor(exporter Exporter, cfg BatchConfig) *BatchProcessor {
+	checkBatchConfig(&cfg)
+
+	p := &BatchProcessor{
+		exporter: exporter,
+		cfg:      cfg,
+		queue:    make(chan *trace.Span, cfg.QueueSize),
+		flushes:  make(chan chan struct{}),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// OnEnd queues s, or drops it when the queue is full.
+func (p *BatchProcessor) OnEnd(s *trace.Span) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		p.dropped.Add(1)
+		return
+	}
+	select {
+	case p.queue <- s:
+	default:
+		p.dropped.Add(1)
+	}
+}
+
+// Stats returns the counters of p.
+func (p *BatchProcessor) Stats() BatchStats {
+	return BatchStats{
+		Exported: p.exported.Load(),
+		Dropped:  p.dropped.Load(),
+		Failed:   p.failed.Load(),
+	}
+}
+
+// ForceFlush exports the queued spans and waits until done or ctx ends.
+func (p *BatchProcessor) ForceFlush(ctx context.Context) error {
+	done := make(chan struct{})
+	select {
+	case p.flushes <- done:
+	case <-p.stopped:
+		return errors.New("export: batch processor is shut down")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown exports the queued spans, stops p and shuts the exporter down.
+// Spans that end afterwards are dropped. Only the first call has an effect.
+func (p *BatchProcessor) Shutdown(ctx context.Context) error {
+	var err error
+	p.shutdownOnce.Do(func() {
+		p.mu.Lock()
+		p.closed = true
+		p.mu.Unlock()
+		close(p.done)
+		select {
+		case <-p.stopped:
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		}
+		err = p.exporter.Shutdown(ctx)
+	})
+	return err
+}
+
+func (p *BatchProcessor) run() {
+	defer close(p.stopped)
+
+	ticker := time.NewTicker(p.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*trace.Span, 0, p.cfg.BatchSize)
+	for {
+		select {
+		case s := <-p.queue:
+			batch = append(batch, s)
+			if len(batch) >= p.cfg.BatchSize {
+				batch = p.export(batch)
+				ticker.Reset(p.cfg.FlushInterval)
+			}
+		case <-ticker.C:
+			batch = p.export(batch)
+		case done := <-p.flushes:
+			batch = p.drain(batch)
+			close(done)
+		case <-p.done:
+			p.drain(batch)
+			return
+		}
+	}
+}
+
+// drain exports batch and every span queued so far.
+func (p *BatchProcessor) drain(batch []*trace.Span) []*trace.Span {
+	for {
+		select {
+		case s := <-p.queue:
+			batch = append(batch, s)
+			if len(batch) >= p.cfg.BatchSize {
+				batch = p.export(batch)
+			}
+		default:
+			return p.export(batch)
+		}
+	}
+}
+
+// export sends batch and returns it emptied for reuse.
+func (p *BatchProcessor) export(batch []*trace.Span) []*trace.Span {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.cfg.ExportTimeout)
+	err := p.exporter.Export(ctx, batch)
+	cancel()
+	if err != nil {
+		p.failed.Add(uint64(len(batch)))
+		kitlog.Named(loggerName).Warn("span export failed", zap.Int("spans", len(batch)), kitlog.Err(err))
+	} else {
+		p.exported.Add(uint64(len(batch)))
+	}
+
+	clear(batch)
+	return batch[:0]
+}
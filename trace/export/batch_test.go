@@ -0,0 +1,205 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NamhaeSusan/my-go-kit/trace"
+)
+
+// batchRecorder is an Exporter that records the size of every batch.
+type batchRecorder struct {
+	mu       sync.Mutex
+	batches  []int
+	spans    []*trace.Span
+	err      error
+	shutdown bool
+	// started and release, when set, block Export until release is closed.
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *batchRecorder) Export(_ context.Context, spans []*trace.Span) error {
+	if r.started != nil {
+		r.started <- struct{}{}
+		<-r.release
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, len(spans))
+	r.spans = append(r.spans, spans...)
+	return r.err
+}
+
+func (r *batchRecorder) Shutdown(context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.shutdown = true
+	return nil
+}
+
+func (r *batchRecorder) sizes() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int(nil), r.batches...)
+}
+
+func endSpans(p trace.Processor, n int) {
+	for range n {
+		_, span := trace.Start(context.Background(), "work")
+		span.End()
+		p.OnEnd(span)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBatchProcessorExportsFullBatches(t *testing.T) {
+	exp := &batchRecorder{}
+	p := NewBatchProcessor(exp, BatchConfig{QueueSize: 10, BatchSize: 2, FlushInterval: time.Hour})
+	defer p.Shutdown(context.Background())
+
+	endSpans(p, 5)
+	waitFor(t, func() bool { return len(exp.sizes()) == 2 })
+	if got := exp.sizes(); got[0] != 2 || got[1] != 2 {
+		t.Fatalf("unexpected batches: %v", got)
+	}
+
+	if err := p.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("ForceFlush returned error: %v", err)
+	}
+	if got := exp.sizes(); len(got) != 3 || got[2] != 1 {
+		t.Fatalf("ForceFlush should export the partial batch: %v", got)
+	}
+	if stats := p.Stats(); stats.Exported != 5 || stats.Dropped != 0 || stats.Failed != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestBatchProcessorFlushesOnInterval(t *testing.T) {
+	exp := &batchRecorder{}
+	p := NewBatchProcessor(exp, BatchConfig{FlushInterval: 10 * time.Millisecond})
+	defer p.Shutdown(context.Background())
+
+	endSpans(p, 1)
+	waitFor(t, func() bool { return len(exp.sizes()) == 1 })
+}
+
+func TestBatchProcessorDropsWhenQueueIsFull(t *testing.T) {
+	exp := &batchRecorder{started: make(chan struct{}), release: make(chan struct{})}
+	p := NewBatchProcessor(exp, BatchConfig{QueueSize: 1, BatchSize: 1, FlushInterval: time.Hour})
+
+	endSpans(p, 1)
+	<-exp.started // 첫 span은 export 중이고 큐는 비어 있다.
+	endSpans(p, 2)
+	if stats := p.Stats(); stats.Dropped != 1 {
+		t.Fatalf("the span that does not fit should be dropped: %+v", stats)
+	}
+
+	go func() {
+		for range exp.started {
+		}
+	}()
+	close(exp.release)
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown returned error: %v", err)
+	}
+	close(exp.started)
+	if stats := p.Stats(); stats.Exported != 2 || stats.Dropped != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestBatchProcessorCountsFailedExports(t *testing.T) {
+	exp := &batchRecorder{err: errors.New("collector down")}
+	p := NewBatchProcessor(exp, BatchConfig{FlushInterval: time.Hour})
+	defer p.Shutdown(context.Background())
+
+	endSpans(p, 3)
+	if err := p.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("ForceFlush returned error: %v", err)
+	}
+	if stats := p.Stats(); stats.Failed != 3 || stats.Exported != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestBatchProcessorShutdown(t *testing.T) {
+	exp := &batchRecorder{}
+	p := NewBatchProcessor(exp, BatchConfig{FlushInterval: time.Hour})
+
+	endSpans(p, 2)
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown returned error: %v", err)
+	}
+	if got := exp.sizes(); len(got) != 1 || got[0] != 2 || !exp.shutdown {
+		t.Fatalf("Shutdown should export the queue and shut the exporter down: %v", got)
+	}
+
+	endSpans(p, 1)
+	if stats := p.Stats(); stats.Dropped != 1 {
+		t.Fatalf("spans after Shutdown should be dropped: %+v", stats)
+	}
+	if err := p.ForceFlush(context.Background()); err == nil {
+		t.Fatal("ForceFlush after Shutdown should fail")
+	}
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("a second Shutdown should do nothing: %v", err)
+	}
+}
+
+func TestBatchProcessorCountsSpansEndingDuringShutdown(t *testing.T) {
+	for range 50 {
+		exp := &batchRecorder{}
+		p := NewBatchProcessor(exp, BatchConfig{FlushInterval: time.Hour})
+
+		const workers, perWorker = 4, 50
+		var wg sync.WaitGroup
+		for range workers {
+			wg.Go(func() { endSpans(p, perWorker) })
+		}
+		if err := p.Shutdown(context.Background()); err != nil {
+			t.Fatalf("Shutdown returned error: %v", err)
+		}
+		wg.Wait()
+
+		exp.mu.Lock()
+		exported := len(exp.spans)
+		exp.mu.Unlock()
+		if stats := p.Stats(); exported+int(stats.Dropped) != workers*perWorker {
+			t.Fatalf("every span should be exported or counted: exported %d, %+v", exported, stats)
+		}
+	}
+}
+
+func TestBatchProcessorAsRegisteredProcessor(t *testing.T) {
+	exp := &batchRecorder{}
+	p := NewBatchProcessor(exp, BatchConfig{})
+	unregister := trace.RegisterProcessor(p)
+	defer unregister()
+
+	_, span := trace.Start(context.Background(), "registered")
+	span.End()
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown returned error: %v", err)
+	}
+
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+	if len(exp.spans) != 1 || exp.spans[0] != span {
+		t.Fatalf("the ended span should be exported: %v", exp.spans)
+	}
+}
@@ -0,0 +1,24 @@
+// Package export ships ended spans: a BatchProcessor queues them and hands
+// them in batches to an Exporter such as the OTLP/HTTP exporter or, in tests,
+// the InMemoryExporter.
+package export
+
+import (
+	"context"
+
+	"github.com/NamhaeSusan/my-go-kit/trace"
+)
+
+// loggerName is the kitlog.Named logger used for export failures.
+const loggerName = "trace"
+
+// Exporter sends ended spans to a backend.
+type Exporter interface {
+	// Export sends spans. It is never called concurrently by a
+	// BatchProcessor and should give up when ctx is done. The slice is
+	// reused after Export returns; the spans are not.
+	Export(ctx context.Context, spans []*trace.Span) error
+	// Shutdown releases the resources of the exporter. Export must not be
+	// called afterwards.
+	Shutdown(ctx context.Context) error
+}
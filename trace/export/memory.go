@@ -0,0 +1,68 @@
+package export
+
+import (
+	"context"
+	"sync"
+
+	"github.com/NamhaeSusan/my-go-kit/trace"
+)
+
+// InMemoryExporter keeps spans in memory so that tests can assert on them.
+// It is an Exporter and also a trace.Processor, so it can be registered
+// directly to see each span as soon as it ends:
+//
+//	spans := export.NewInMemoryExporter()
+//	defer trace.RegisterProcessor(spans)()
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []*trace.Span
+}
+
+// NewInMemoryExporter returns an empty InMemoryExporter.
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+func (e *InMemoryExporter) Export(_ context.Context, spans []*trace.Span) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+// OnEnd records s as a trace.Processor.
+func (e *InMemoryExporter) OnEnd(s *trace.Span) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, s)
+}
+
+// Shutdown does nothing; the spans stay available.
+func (e *InMemoryExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// Spans returns the recorded spans in the order they were received.
+func (e *InMemoryExporter) Spans() []*trace.Span {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]*trace.Span(nil), e.spans...)
+}
+
+// SpansByName returns the recorded spans with the given name.
+func (e *InMemoryExporter) SpansByName(name string) []*trace.Span {
+	var spans []*trace.Span
+	for _, s := range e.Spans() {
+		if s.Name() == name {
+			spans = append(spans, s)
+		}
+	}
+	return spans
+}
+
+// Reset forgets the recorded spans.
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
@@ -0,0 +1,39 @@
+package export
+
+import (
+	"context"
+	"testing"
+
+	"github.com/NamhaeSusan/my-go-kit/trace"
+)
+
+func TestInMemoryExporter(t *testing.T) {
+	spans := NewInMemoryExporter()
+	unregister := trace.RegisterProcessor(spans)
+	defer unregister()
+
+	ctx, parent := trace.Start(context.Background(), "parent")
+	_, child := trace.Start(ctx, "child")
+	child.End()
+	parent.End()
+
+	got := spans.Spans()
+	if len(got) != 2 || got[0] != child || got[1] != parent {
+		t.Fatalf("spans should be recorded as they end: %v", got)
+	}
+	if byName := spans.SpansByName("child"); len(byName) != 1 || byName[0].ParentSpanID() != parent.SpanID() {
+		t.Fatalf("unexpected spans by name: %v", byName)
+	}
+
+	if err := spans.Export(context.Background(), []*trace.Span{parent}); err != nil {
+		t.Fatalf("Export returned error: %v", err)
+	}
+	if len(spans.Spans()) != 3 {
+		t.Fatal("exported spans should be recorded too")
+	}
+
+	spans.Reset()
+	if len(spans.Spans()) != 0 {
+		t.Fatal("Reset should forget the spans")
+	}
+}
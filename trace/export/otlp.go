@@ -0,0 +1,173 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+
+	"github.com/NamhaeSusan/my-go-kit/httpclient"
+	"github.com/NamhaeSusan/my-go-kit/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultOTLPEndpoint    = "http://localhost:4318/v1/traces"
+	defaultOTLPServiceName = "unknown_service"
+	defaultOTLPAttempts    = 3
+
+	// maxOTLPErrorBody is the length of a collector error kept in the
+	// returned error.
+	maxOTLPErrorBody = 1024
+)
+
+// OTLPEncoding is the body format of the OTLP/HTTP exporter.
+type OTLPEncoding int
+
+const (
+	// OTLPProtobuf sends binary protobuf (application/x-protobuf).
+	OTLPProtobuf OTLPEncoding = iota
+	// OTLPJSON sends the JSON mapping of OTLP (application/json).
+	OTLPJSON
+)
+
+// OTLPConfig configures an OTLPExporter.
+type OTLPConfig struct {
+	// Endpoint is the URL of the traces endpoint of the collector.
+	// Defaults to http://localhost:4318/v1/traces.
+	Endpoint string
+	// Encoding defaults to OTLPProtobuf.
+	Encoding OTLPEncoding
+	// Headers are added to every request, for instance for authentication.
+	Headers map[string]string
+	// HTTPClient sends the requests. Defaults to a new http.Client.
+	HTTPClient *http.Client
+	// Retry configures the retries of httpclient.Client. By default a batch
+	// is sent up to 3 times when the collector answers 429, 502, 503 or 504
+	// or cannot be reached.
+	Retry httpclient.RetryConfig
+	// ServiceName is the service.name resource attribute. Defaults to
+	// "unknown_service", as in OpenTelemetry.
+	ServiceName string
+	// ResourceAttributes are added to the resource of every span.
+	ResourceAttributes []zap.Field
+}
+
+func checkOTLPConfig(cfg *OTLPConfig) error {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = defaultOTLPEndpoint
+	}
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return fmt.Errorf("export: invalid endpoint: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("export: endpoint must be an http or https URL: %q", cfg.Endpoint)
+	}
+	if cfg.Encoding != OTLPProtobuf && cfg.Encoding != OTLPJSON {
+		return fmt.Errorf("export: unknown encoding %d", cfg.Encoding)
+	}
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = defaultOTLPServiceName
+	}
+	if cfg.Retry.MaxAttempts <= 0 {
+		cfg.Retry.MaxAttempts = defaultOTLPAttempts
+	}
+	if len(cfg.Retry.RetryableStatuses) == 0 {
+		cfg.Retry.RetryableStatuses = []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		}
+	}
+	if len(cfg.Retry.RetryableHTTPMethods) == 0 {
+		cfg.Retry.RetryableHTTPMethods = []string{http.MethodPost}
+	}
+	return nil
+}
+
+// OTLPExporter sends spans to an OpenTelemetry collector over OTLP/HTTP.
+// Its requests go through httpclient.Client with tracing turned off, so the
+// export itself records no spans.
+type OTLPExporter struct {
+	cfg      OTLPConfig
+	client   *httpclient.Client
+	resource otlpResource
+	shutdown atomic.Bool
+}
+
+// NewOTLPExporter returns an OTLPExporter for cfg.
+func NewOTLPExporter(cfg OTLPConfig) (*OTLPExporter, error) {
+	if err := checkOTLPConfig(&cfg); err != nil {
+		return nil, err
+	}
+
+	attrs := append([]zap.Field{zap.String("service.name", cfg.ServiceName)}, cfg.ResourceAttributes...)
+	return &OTLPExporter{
+		cfg: cfg,
+		client: httpclient.New(httpclient.Config{
+			HTTPClient:     cfg.HTTPClient,
+			Retry:          cfg.Retry,
+			DisableTracing: true,
+		}),
+		resource: otlpResource{Attributes: otlpAttributes(attrs)},
+	}, nil
+}
+
+func (e *OTLPExporter) Export(ctx context.Context, spans []*trace.Span) error {
+	if e.shutdown.Load() {
+		return errors.New("export: exporter is shut down")
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+
+	r := newOTLPRequest(e.resource, spans)
+	var body []byte
+	var contentType string
+	switch e.cfg.Encoding {
+	case OTLPJSON:
+		var err error
+		if body, err = r.marshalJSON(); err != nil {
+			return fmt.Errorf("export: encode spans: %w", err)
+		}
+		contentType = "application/json"
+	default:
+		body = r.marshalProto()
+		contentType = "application/x-protobuf"
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.cfg.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("export: build request: %w", err)
+	}
+	for k, v := range e.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := e.client.Do(ctx, req)
+	if err != nil {
+		return fmt.Errorf("export: send spans: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxOTLPErrorBody))
+		return fmt.Errorf("export: collector returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// Shutdown makes later calls to Export fail.
+func (e *OTLPExporter) Shutdown(context.Context) error {
+	e.shutdown.Store(true)
+	return nil
+}
@@ -0,0 +1,429 @@
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/NamhaeSusan/my-go-kit/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// otlpScopeName is the instrumentation scope of the exported spans.
+const otlpScopeName = "github.com/NamhaeSusan/my-go-kit/trace"
+
+// The types below mirror ExportTraceServiceRequest of OTLP. Their json tags
+// follow the OTLP/JSON mapping and marshalProto writes the same fields as
+// protobuf.
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           hexBytes       `json:"traceId"`
+	SpanID            hexBytes       `json:"spanId"`
+	ParentSpanID      hexBytes       `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano uint64         `json:"startTimeUnixNano,string"`
+	EndTimeUnixNano   uint64         `json:"endTimeUnixNano,string"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano uint64         `json:"timeUnixNano,string"`
+	Name         string         `json:"name"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpStatus struct {
+	Message string `json:"message,omitempty"`
+	Code    int    `json:"code,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+// otlpValue is an AnyValue; exactly one field is set.
+type otlpValue struct {
+	StringValue *string     `json:"stringValue,omitempty"`
+	BoolValue   *bool       `json:"boolValue,omitempty"`
+	IntValue    *int64      `json:"intValue,omitempty,string"`
+	DoubleValue *float64    `json:"doubleValue,omitempty"`
+	ArrayValue  *otlpValues `json:"arrayValue,omitempty"`
+	KvlistValue *otlpKvlist `json:"kvlistValue,omitempty"`
+	BytesValue  []byte      `json:"bytesValue,omitempty"`
+}
+
+type otlpValues struct {
+	Values []otlpValue `json:"values"`
+}
+
+type otlpKvlist struct {
+	Values []otlpKeyValue `json:"values"`
+}
+
+// hexBytes is an ID, written as hex in OTLP/JSON.
+type hexBytes []byte
+
+func (b hexBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(b))
+}
+
+func newOTLPRequest(resource otlpResource, spans []*trace.Span) otlpRequest {
+	out := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		out = append(out, newOTLPSpan(s))
+	}
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: resource,
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: otlpScopeName},
+			Spans: out,
+		}},
+	}}}
+}
+
+func newOTLPSpan(s *trace.Span) otlpSpan {
+	span := otlpSpan{
+		TraceID:           otlpID(s.TraceID(), 16),
+		SpanID:            otlpID(s.SpanID(), 8),
+		Name:              s.Name(),
+		Kind:              otlpKind(s.Kind()),
+		StartTimeUnixNano: unixNano(s.StartTime()),
+		EndTimeUnixNano:   unixNano(s.EndTime()),
+		Attributes:        otlpAttributes(s.Attributes()),
+	}
+	if parent := s.ParentSpanID(); parent != "" {
+		span.ParentSpanID = otlpID(parent, 8)
+	}
+	for _, ev := range s.Events() {
+		span.Events = append(span.Events, otlpEvent{
+			TimeUnixNano: unixNano(ev.Time),
+			Name:         ev.Name,
+			Attributes:   otlpAttributes(ev.Attributes),
+		})
+	}
+	status := s.Status()
+	// trace.StatusCode는 OTLP의 STATUS_CODE_UNSET/OK/ERROR와 같은 값이다.
+	span.Status = otlpStatus{Code: int(status.Code), Message: status.Description}
+	return span
+}
+
+// otlpID returns id as an OTLP ID of size bytes. IDs that are not hex of
+// that size, such as legacy trace IDs, are hashed so that every span of the
+// same trace still gets the same ID.
+func otlpID(id string, size int) []byte {
+	if len(id) == size*2 {
+		if b, err := hex.DecodeString(id); err == nil && strings.Trim(id, "0") != "" {
+			return b
+		}
+	}
+	sum := sha256.Sum256([]byte(id))
+	return sum[:size]
+}
+
+func otlpKind(kind trace.SpanKind) int {
+	// SPAN_KIND_INTERNAL = 1, SERVER = 2, CLIENT = 3
+	switch kind {
+	case trace.SpanKindServer:
+		return 2
+	case trace.SpanKindClient:
+		return 3
+	default:
+		return 1
+	}
+}
+
+func unixNano(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	return uint64(t.UnixNano())
+}
+
+// otlpAttributes converts fields with the zap encoder, so every field type
+// is written the way it is logged.
+func otlpAttributes(fields []zap.Field) []otlpKeyValue {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	kvs := make([]otlpKeyValue, 0, len(fields))
+	for _, f := range fields {
+		clear(enc.Fields)
+		f.AddTo(enc)
+		for _, k := range sortedKeys(enc.Fields) {
+			kvs = append(kvs, otlpKeyValue{Key: k, Value: otlpValueOf(enc.Fields[k])})
+		}
+	}
+	return kvs
+}
+
+func otlpValueOf(v any) otlpValue {
+	switch v := v.(type) {
+	case string:
+		return stringValue(v)
+	case bool:
+		return otlpValue{BoolValue: &v}
+	case int:
+		return intValue(int64(v))
+	case int8:
+		return intValue(int64(v))
+	case int16:
+		return intValue(int64(v))
+	case int32:
+		return intValue(int64(v))
+	case int64:
+		return intValue(v)
+	case uint:
+		return uintValue(uint64(v))
+	case uint8:
+		return intValue(int64(v))
+	case uint16:
+		return intValue(int64(v))
+	case uint32:
+		return intValue(int64(v))
+	case uint64:
+		return uintValue(v)
+	case uintptr:
+		return uintValue(uint64(v))
+	case float32:
+		return doubleValue(float64(v))
+	case float64:
+		return doubleValue(v)
+	case []byte:
+		return otlpValue{BytesValue: v}
+	case time.Time:
+		return stringValue(v.Format(time.RFC3339Nano))
+	case time.Duration:
+		return stringValue(v.String())
+	case []any:
+		values := make([]otlpValue, 0, len(v))
+		for _, item := range v {
+			values = append(values, otlpValueOf(item))
+		}
+		return otlpValue{ArrayValue: &otlpValues{Values: values}}
+	case map[string]any:
+		kvs := make([]otlpKeyValue, 0, len(v))
+		for _, k := range sortedKeys(v) {
+			kvs = append(kvs, otlpKeyValue{Key: k, Value: otlpValueOf(v[k])})
+		}
+		return otlpValue{KvlistValue: &otlpKvlist{Values: kvs}}
+	case fmt.Stringer:
+		return stringValue(v.String())
+	default:
+		return stringValue(fmt.Sprint(v))
+	}
+}
+
+func stringValue(s string) otlpValue {
+	return otlpValue{StringValue: &s}
+}
+
+func intValue(i int64) otlpValue {
+	return otlpValue{IntValue: &i}
+}
+
+func uintValue(u uint64) otlpValue {
+	if u > math.MaxInt64 {
+		return stringValue(fmt.Sprint(u))
+	}
+	return intValue(int64(u))
+}
+
+func doubleValue(f float64) otlpValue {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		// JSON에는 NaN/Inf가 없으므로 문자열로 보낸다.
+		return stringValue(fmt.Sprint(f))
+	}
+	return otlpValue{DoubleValue: &f}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func (r otlpRequest) marshalJSON() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+// marshalProto encodes r as an ExportTraceServiceRequest. The field numbers
+// are those of opentelemetry/proto/trace/v1/trace.proto and
+// opentelemetry/proto/common/v1/common.proto.
+func (r otlpRequest) marshalProto() []byte {
+	var b []byte
+	for _, rs := range r.ResourceSpans {
+		b = appendMessage(b, 1, rs.appendProto)
+	}
+	return b
+}
+
+func (rs otlpResourceSpans) appendProto(b []byte) []byte {
+	b = appendMessage(b, 1, func(b []byte) []byte {
+		return appendKeyValues(b, 1, rs.Resource.Attributes)
+	})
+	for _, ss := range rs.ScopeSpans {
+		b = appendMessage(b, 2, ss.appendProto)
+	}
+	return b
+}
+
+func (ss otlpScopeSpans) appendProto(b []byte) []byte {
+	b = appendMessage(b, 1, func(b []byte) []byte {
+		return appendString(b, 1, ss.Scope.Name)
+	})
+	for _, s := range ss.Spans {
+		b = appendMessage(b, 2, s.appendProto)
+	}
+	return b
+}
+
+func (s otlpSpan) appendProto(b []byte) []byte {
+	b = appendBytes(b, 1, s.TraceID)
+	b = appendBytes(b, 2, s.SpanID)
+	b = appendBytes(b, 4, s.ParentSpanID)
+	b = appendString(b, 5, s.Name)
+	b = appendVarint(b, 6, uint64(s.Kind))
+	b = appendFixed64(b, 7, s.StartTimeUnixNano)
+	b = appendFixed64(b, 8, s.EndTimeUnixNano)
+	b = appendKeyValues(b, 9, s.Attributes)
+	for _, ev := range s.Events {
+		b = appendMessage(b, 11, ev.appendProto)
+	}
+	return appendMessage(b, 15, s.Status.appendProto)
+}
+
+func (ev otlpEvent) appendProto(b []byte) []byte {
+	b = appendFixed64(b, 1, ev.TimeUnixNano)
+	b = appendString(b, 2, ev.Name)
+	return appendKeyValues(b, 3, ev.Attributes)
+}
+
+func (st otlpStatus) appendProto(b []byte) []byte {
+	b = appendString(b, 2, st.Message)
+	return appendVarint(b, 3, uint64(st.Code))
+}
+
+func (kv otlpKeyValue) appendProto(b []byte) []byte {
+	b = appendString(b, 1, kv.Key)
+	return appendMessage(b, 2, kv.Value.appendProto)
+}
+
+func (v otlpValue) appendProto(b []byte) []byte {
+	switch {
+	case v.StringValue != nil:
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		return protowire.AppendString(b, *v.StringValue)
+	case v.BoolValue != nil:
+		b = protowire.AppendTag(b, 2, protowire.VarintType)
+		return protowire.AppendVarint(b, protowire.EncodeBool(*v.BoolValue))
+	case v.IntValue != nil:
+		b = protowire.AppendTag(b, 3, protowire.VarintType)
+		return protowire.AppendVarint(b, uint64(*v.IntValue))
+	case v.DoubleValue != nil:
+		b = protowire.AppendTag(b, 4, protowire.Fixed64Type)
+		return protowire.AppendFixed64(b, math.Float64bits(*v.DoubleValue))
+	case v.ArrayValue != nil:
+		return appendMessage(b, 5, func(b []byte) []byte {
+			for _, item := range v.ArrayValue.Values {
+				b = appendMessage(b, 1, item.appendProto)
+			}
+			return b
+		})
+	case v.KvlistValue != nil:
+		return appendMessage(b, 6, func(b []byte) []byte {
+			return appendKeyValues(b, 1, v.KvlistValue.Values)
+		})
+	case v.BytesValue != nil:
+		b = protowire.AppendTag(b, 7, protowire.BytesType)
+		return protowire.AppendBytes(b, v.BytesValue)
+	default:
+		return b
+	}
+}
+
+func appendKeyValues(b []byte, num protowire.Number, kvs []otlpKeyValue) []byte {
+	for _, kv := range kvs {
+		b = appendMessage(b, num, kv.appendProto)
+	}
+	return b
+}
+
+// appendMessage appends the embedded message written by encode. Unlike the
+// scalar helpers it always writes the field, as an empty message may still
+// be meaningful.
+func appendMessage(b []byte, num protowire.Number, encode func([]byte) []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, encode(nil))
+}
+
+// The scalar helpers skip default values, as proto3 does.
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendFixed64(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, v)
+}
@@ -0,0 +1,285 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NamhaeSusan/my-go-kit/httpclient"
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+	"github.com/NamhaeSusan/my-go-kit/trace"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	testTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID  = "00f067aa0ba902b7"
+)
+
+func newTestSpans() []*trace.Span {
+	ctx := kitlog.WithTraceID(context.Background(), testTraceID)
+	ctx = kitlog.WithSpanID(ctx, testSpanID)
+	_, span := trace.Start(ctx, "GET /orders",
+		trace.WithKind(trace.SpanKindClient),
+		trace.WithAttributes(zap.Int("http.response.status_code", 503), zap.Strings("tags", []string{"a", "b"})),
+	)
+	span.RecordError(errors.New("unavailable"))
+	span.SetStatus(trace.StatusError, "unavailable")
+	span.End()
+	return []*trace.Span{span}
+}
+
+func TestOTLPExporterJSON(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" || r.Header.Get("Authorization") != "Bearer t" {
+			t.Errorf("unexpected headers: %v", r.Header)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("invalid body: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	exp, err := NewOTLPExporter(OTLPConfig{
+		Endpoint:           server.URL,
+		Encoding:           OTLPJSON,
+		Headers:            map[string]string{"Authorization": "Bearer t"},
+		ServiceName:        "orders",
+		ResourceAttributes: []zap.Field{zap.String("deployment.environment", "test")},
+	})
+	if err != nil {
+		t.Fatalf("NewOTLPExporter returned error: %v", err)
+	}
+	spans := newTestSpans()
+	if err := exp.Export(context.Background(), spans); err != nil {
+		t.Fatalf("Export returned error: %v", err)
+	}
+
+	rs := body["resourceSpans"].([]any)[0].(map[string]any)
+	resource := rs["resource"].(map[string]any)["attributes"].([]any)
+	if len(resource) != 2 || resource[0].(map[string]any)["value"].(map[string]any)["stringValue"] != "orders" {
+		t.Fatalf("unexpected resource: %v", resource)
+	}
+	span := rs["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)[0].(map[string]any)
+	if span["traceId"] != testTraceID || span["parentSpanId"] != testSpanID || span["spanId"] != spans[0].SpanID() {
+		t.Fatalf("unexpected IDs: %v", span)
+	}
+	if span["name"] != "GET /orders" || span["kind"] != float64(3) {
+		t.Fatalf("unexpected span: %v", span)
+	}
+	if span["startTimeUnixNano"] == "" || span["endTimeUnixNano"] == span["startTimeUnixNano"] && spans[0].Duration() > 0 {
+		t.Fatalf("unexpected timing: %v", span)
+	}
+	attrs := span["attributes"].([]any)
+	if v := attrs[0].(map[string]any)["value"].(map[string]any); v["intValue"] != "503" {
+		t.Fatalf("int attributes should be strings in OTLP/JSON: %v", attrs)
+	}
+	if v := attrs[1].(map[string]any)["value"].(map[string]any); len(v["arrayValue"].(map[string]any)["values"].([]any)) != 2 {
+		t.Fatalf("unexpected array attribute: %v", attrs)
+	}
+	if status := span["status"].(map[string]any); status["code"] != float64(2) || status["message"] != "unavailable" {
+		t.Fatalf("unexpected status: %v", status)
+	}
+	if events := span["events"].([]any); events[0].(map[string]any)["name"] != "exception" {
+		t.Fatalf("unexpected events: %v", events)
+	}
+}
+
+func TestOTLPExporterProtobuf(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/x-protobuf" {
+			t.Errorf("unexpected content type: %q", r.Header.Get("Content-Type"))
+		}
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	exp, err := NewOTLPExporter(OTLPConfig{Endpoint: server.URL})
+	if err != nil {
+		t.Fatalf("NewOTLPExporter returned error: %v", err)
+	}
+	spans := newTestSpans()
+	if err := exp.Export(context.Background(), spans); err != nil {
+		t.Fatalf("Export returned error: %v", err)
+	}
+
+	// resource_spans(1) > scope_spans(2) > spans(2)
+	rs := protoField(t, body, 1)
+	resource := protoField(t, rs, 1)
+	kv := protoField(t, resource, 1)
+	if key := string(protoField(t, kv, 1)); key != "service.name" {
+		t.Fatalf("unexpected resource attribute: %q", key)
+	}
+	if value := string(protoField(t, protoField(t, kv, 2), 1)); value != defaultOTLPServiceName {
+		t.Fatalf("unexpected service name: %q", value)
+	}
+	span := protoField(t, protoField(t, rs, 2), 2)
+	if got := protoField(t, span, 1); len(got) != 16 || string(got) != string(otlpID(testTraceID, 16)) {
+		t.Fatalf("unexpected trace id: %x", got)
+	}
+	if got := protoField(t, span, 4); string(got) != string(otlpID(testSpanID, 8)) {
+		t.Fatalf("unexpected parent span id: %x", got)
+	}
+	if name := string(protoField(t, span, 5)); name != "GET /orders" {
+		t.Fatalf("unexpected name: %q", name)
+	}
+	status := protoField(t, span, 15)
+	if msg := string(protoField(t, status, 2)); msg != "unavailable" {
+		t.Fatalf("unexpected status message: %q", msg)
+	}
+}
+
+// protoField returns the first length-delimited field num of b.
+func protoField(t *testing.T, b []byte, num protowire.Number) []byte {
+	t.Helper()
+	for len(b) > 0 {
+		n, typ, l := protowire.ConsumeTag(b)
+		if l < 0 {
+			t.Fatalf("invalid tag: %v", protowire.ParseError(l))
+		}
+		b = b[l:]
+		if n == num && typ == protowire.BytesType {
+			v, l := protowire.ConsumeBytes(b)
+			if l < 0 {
+				t.Fatalf("invalid field: %v", protowire.ParseError(l))
+			}
+			return v
+		}
+		l = protowire.ConsumeFieldValue(n, typ, b)
+		if l < 0 {
+			t.Fatalf("invalid field: %v", protowire.ParseError(l))
+		}
+		b = b[l:]
+	}
+	t.Fatalf("field %d not found", num)
+	return nil
+}
+
+func TestOTLPExporterRetriesWithoutTracingItself(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(kitlog.TraceHeader) != "" || r.Header.Get("traceparent") != "" {
+			t.Errorf("the exporter should not send trace headers: %v", r.Header)
+		}
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+	}))
+	defer server.Close()
+
+	recorded := NewInMemoryExporter()
+	unregister := trace.RegisterProcessor(recorded)
+	defer unregister()
+
+	exp, err := NewOTLPExporter(OTLPConfig{
+		Endpoint: server.URL,
+		Retry:    httpclientRetry(),
+	})
+	if err != nil {
+		t.Fatalf("NewOTLPExporter returned error: %v", err)
+	}
+	if err := exp.Export(context.Background(), newTestSpans()); err != nil {
+		t.Fatalf("Export returned error: %v", err)
+	}
+	if attempts.Load() != 2 {
+		t.Fatalf("a 503 should be retried, got %d attempts", attempts.Load())
+	}
+	// newTestSpans가 만든 span 외에 export가 만든 span은 없어야 한다.
+	if got := recorded.Spans(); len(got) != 1 || got[0].Name() != "GET /orders" {
+		t.Fatalf("the export should not record spans: %v", got)
+	}
+}
+
+func TestOTLPExporterErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad payload", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	exp, err := NewOTLPExporter(OTLPConfig{Endpoint: server.URL, Retry: httpclientRetry()})
+	if err != nil {
+		t.Fatalf("NewOTLPExporter returned error: %v", err)
+	}
+	if err := exp.Export(context.Background(), newTestSpans()); err == nil || !strings.Contains(err.Error(), "bad payload") {
+		t.Fatalf("a 400 should fail with the collector message: %v", err)
+	}
+
+	if err := exp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown returned error: %v", err)
+	}
+	if err := exp.Export(context.Background(), newTestSpans()); err == nil {
+		t.Fatal("Export after Shutdown should fail")
+	}
+
+	for _, cfg := range []OTLPConfig{
+		{Endpoint: "localhost:4318"},
+		{Endpoint: "ftp://collector/v1/traces"},
+		{Encoding: OTLPEncoding(9)},
+	} {
+		if _, err := NewOTLPExporter(cfg); err == nil {
+			t.Errorf("expected an error for %+v", cfg)
+		}
+	}
+}
+
+func TestOTLPID(t *testing.T) {
+	if got := otlpID(testTraceID, 16); len(got) != 16 || got[0] != 0x4b {
+		t.Fatalf("a hex ID should be decoded: %x", got)
+	}
+	legacy := otlpID("legacy-trace", 16)
+	if len(legacy) != 16 || string(legacy) != string(otlpID("legacy-trace", 16)) {
+		t.Fatalf("a legacy ID should be hashed consistently: %x", legacy)
+	}
+	if got := otlpID("00000000000000000000000000000000", 16); string(got) == string(make([]byte, 16)) {
+		t.Fatal("an all-zero ID is invalid in OTLP")
+	}
+}
+
+func TestOTLPAttributes(t *testing.T) {
+	attrs := otlpAttributes([]zap.Field{
+		zap.Bool("ok", true),
+		zap.Float64("ratio", 0.5),
+		zap.Duration("elapsed", 1500*time.Millisecond),
+		zap.Uint64("big", 1<<63),
+		zap.Error(errors.New("boom")),
+		zap.Any("obj", map[string]any{"b": 1, "a": "x"}),
+	})
+
+	got := map[string]otlpValue{}
+	for _, kv := range attrs {
+		got[kv.Key] = kv.Value
+	}
+	if v := got["ok"]; v.BoolValue == nil || !*v.BoolValue {
+		t.Fatalf("unexpected bool: %+v", v)
+	}
+	if v := got["ratio"]; v.DoubleValue == nil || *v.DoubleValue != 0.5 {
+		t.Fatalf("unexpected double: %+v", v)
+	}
+	if v := got["elapsed"]; v.StringValue == nil || *v.StringValue != "1.5s" {
+		t.Fatalf("unexpected duration: %+v", v)
+	}
+	if v := got["big"]; v.StringValue == nil || *v.StringValue != "9223372036854775808" {
+		t.Fatalf("a uint64 above int64 should be a string: %+v", v)
+	}
+	if v := got["error"]; v.StringValue == nil || *v.StringValue != "boom" {
+		t.Fatalf("unexpected error: %+v", v)
+	}
+	if v := got["obj"]; v.KvlistValue == nil || v.KvlistValue.Values[0].Key != "a" {
+		t.Fatalf("unexpected object: %+v", v)
+	}
+}
+
+func httpclientRetry() httpclient.RetryConfig {
+	return httpclient.RetryConfig{BaseDelay: time.Millisecond}
+}
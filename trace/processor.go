@@ -0,0 +1,70 @@
+package trace
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Processor receives every span when it ends, for instance to export it.
+// OnEnd is called on the goroutine that calls End and must not block.
+type Processor interface {
+	OnEnd(s *Span)
+}
+
+// registration is the entry of one RegisterProcessor call. Processors are
+// removed by their registration, since a Processor need not be comparable.
+type registration struct {
+	p Processor
+}
+
+var (
+	processorsMu sync.Mutex
+	processors   atomic.Pointer[[]*registration]
+)
+
+// RegisterProcessor adds p to the processors that receive ended spans and
+// returns a function that removes it again.
+func RegisterProcessor(p Processor) (unregister func()) {
+	processorsMu.Lock()
+	defer processorsMu.Unlock()
+
+	reg := &registration{p: p}
+	var next []*registration
+	if cur := processors.Load(); cur != nil {
+		next = slices.Clone(*cur)
+	}
+	next = append(next, reg)
+	processors.Store(&next)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { unregisterProcessor(reg) })
+	}
+}
+
+func unregisterProcessor(reg *registration) {
+	processorsMu.Lock()
+	defer processorsMu.Unlock()
+
+	cur := processors.Load()
+	if cur == nil {
+		return
+	}
+	i := slices.Index(*cur, reg)
+	if i < 0 {
+		return
+	}
+	next := slices.Delete(slices.Clone(*cur), i, i+1)
+	processors.Store(&next)
+}
+
+func onEnd(s *Span) {
+	regs := processors.Load()
+	if regs == nil {
+		return
+	}
+	for _, reg := range *regs {
+		reg.p.OnEnd(s)
+	}
+}
@@ -0,0 +1,72 @@
+package trace
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+type recordingProcessor struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+func (p *recordingProcessor) OnEnd(s *Span) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.spans = append(p.spans, s)
+}
+
+func (p *recordingProcessor) ended() []*Span {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*Span(nil), p.spans...)
+}
+
+func TestRegisterProcessor(t *testing.T) {
+	first, second := &recordingProcessor{}, &recordingProcessor{}
+	unregisterFirst := RegisterProcessor(first)
+	unregisterSecond := RegisterProcessor(second)
+	defer unregisterSecond()
+
+	_, span := Start(context.Background(), "work")
+	if len(first.ended()) != 0 {
+		t.Fatal("a running span should not be processed")
+	}
+	span.End()
+	span.End()
+	if got := first.ended(); len(got) != 1 || got[0] != span || !got[0].Ended() {
+		t.Fatalf("the ended span should be processed once: %v", got)
+	}
+	if len(second.ended()) != 1 {
+		t.Fatal("every processor should receive the span")
+	}
+
+	unregisterFirst()
+	unregisterFirst()
+	_, span = Start(context.Background(), "other")
+	span.End()
+	if len(first.ended()) != 1 || len(second.ended()) != 2 {
+		t.Fatalf("unexpected spans after unregister: %d/%d", len(first.ended()), len(second.ended()))
+	}
+}
+
+// funcProcessor is not comparable, so it can only be removed by its
+// registration.
+type funcProcessor func(s *Span)
+
+func (f funcProcessor) OnEnd(s *Span) { f(s) }
+
+func TestUnregisterNonComparableProcessor(t *testing.T) {
+	var first, second int
+	unregisterFirst := RegisterProcessor(funcProcessor(func(*Span) { first++ }))
+	unregisterSecond := RegisterProcessor(funcProcessor(func(*Span) { second++ }))
+	defer unregisterSecond()
+
+	unregisterFirst()
+	_, span := Start(context.Background(), "work")
+	span.End()
+	if first != 0 || second != 1 {
+		t.Fatalf("only the unregistered processor should stop: %d/%d", first, second)
+	}
+}
@@ -0,0 +1,135 @@
+package trace
+
+import (
+	"fmt"
+	"path"
+	"sync/atomic"
+
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+	"go.uber.org/zap"
+)
+
+// SamplingParameters is what a Sampler decides on when a span starts.
+type SamplingParameters struct {
+	TraceID    string
+	Name       string
+	Kind       SpanKind
+	Attributes []zap.Field
+	// ParentSampled is the decision of the parent span or of the caller, and
+	// ParentDecided reports whether there is one.
+	ParentSampled bool
+	ParentDecided bool
+}
+
+// Sampler decides whether a trace is recorded. The decision is made when a
+// span starts and is stored in its context with kitlog.WithSampled, so the
+// propagators send it downstream and log sampling follows it.
+type Sampler interface {
+	ShouldSample(p SamplingParameters) bool
+}
+
+// SamplerFunc adapts a function to Sampler.
+type SamplerFunc func(p SamplingParameters) bool
+
+func (f SamplerFunc) ShouldSample(p SamplingParameters) bool {
+	return f(p)
+}
+
+// AlwaysSample records every trace.
+func AlwaysSample() Sampler {
+	return SamplerFunc(func(SamplingParameters) bool { return true })
+}
+
+// NeverSample records no trace.
+func NeverSample() Sampler {
+	return SamplerFunc(func(SamplingParameters) bool { return false })
+}
+
+// TraceIDRatioBased records the given ratio of traces. The decision is a hash
+// of the traceId, the same as kitlog.SamplingConfig.TraceRatio, so services
+// that share the ratio agree even without a propagated decision.
+func TraceIDRatioBased(ratio float64) Sampler {
+	return SamplerFunc(func(p SamplingParameters) bool {
+		return kitlog.TraceRatioSampled(p.TraceID, ratio)
+	})
+}
+
+// ParentBased follows the decision of the parent and asks root for the first
+// span of a trace. A nil root records every trace.
+func ParentBased(root Sampler) Sampler {
+	if root == nil {
+		root = AlwaysSample()
+	}
+	return SamplerFunc(func(p SamplingParameters) bool {
+		if p.ParentDecided {
+			return p.ParentSampled
+		}
+		return root.ShouldSample(p)
+	})
+}
+
+// SamplingRule picks the Sampler of the spans whose name matches Name, a
+// path.Match pattern such as "GET /healthz", "* /orders/*" or
+// "grpc.health.v1.Health/*".
+type SamplingRule struct {
+	Name    string
+	Sampler Sampler
+}
+
+// RuleBased asks the Sampler of the first matching rule and fallback when
+// none matches. A nil fallback records every trace. Wrap it in ParentBased
+// so that the rules only pick the decision of new traces, as in
+//
+//	rules, err := trace.RuleBased([]trace.SamplingRule{
+//		{Name: "GET /healthz", Sampler: trace.NeverSample()},
+//	}, trace.TraceIDRatioBased(0.1))
+//	trace.SetSampler(trace.ParentBased(rules))
+func RuleBased(rules []SamplingRule, fallback Sampler) (Sampler, error) {
+	for _, rule := range rules {
+		if _, err := path.Match(rule.Name, ""); err != nil {
+			return nil, fmt.Errorf("trace: invalid sampling rule %q: %w", rule.Name, err)
+		}
+		if rule.Sampler == nil {
+			return nil, fmt.Errorf("trace: sampling rule %q has no sampler", rule.Name)
+		}
+	}
+	if fallback == nil {
+		fallback = AlwaysSample()
+	}
+
+	rules = append([]SamplingRule(nil), rules...)
+	return SamplerFunc(func(p SamplingParameters) bool {
+		for _, rule := range rules {
+			if ok, _ := path.Match(rule.Name, p.Name); ok {
+				return rule.Sampler.ShouldSample(p)
+			}
+		}
+		return fallback.ShouldSample(p)
+	}), nil
+}
+
+var globalSampler atomic.Pointer[Sampler]
+
+// SetSampler sets the Sampler of every span started afterwards. A nil s
+// restores the default: spans follow the decision of the caller and are
+// recorded without one, leaving the decision to downstream services.
+func SetSampler(s Sampler) {
+	if s == nil {
+		globalSampler.Store(nil)
+		return
+	}
+	globalSampler.Store(&s)
+}
+
+// sample returns the decision for the span of p. decided is false when no
+// one made one, so that none is stored.
+func sample(p SamplingParameters) (sampled, decided bool) {
+	s := globalSampler.Load()
+	if s == nil {
+		if p.ParentDecided {
+			return p.ParentSampled, true
+		}
+		return true, false
+	}
+	return (*s).ShouldSample(p), true
+}
@@ -0,0 +1,147 @@
+package trace
+
+import (
+	"context"
+	"testing"
+
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+	"go.uber.org/zap"
+)
+
+// useSampler sets s for the duration of the test.
+func useSampler(t *testing.T, s Sampler) {
+	t.Helper()
+	SetSampler(s)
+	t.Cleanup(func() { SetSampler(nil) })
+}
+
+func TestStartWithoutSamplerLeavesDecisionOpen(t *testing.T) {
+	ctx, span := Start(context.Background(), "work")
+	if !span.IsRecording() {
+		t.Fatal("spans should record by default")
+	}
+	if _, ok := kitlog.GetSampled(ctx); ok {
+		t.Fatal("no decision should be stored without a sampler")
+	}
+
+	ctx = kitlog.WithSampled(ctx, false)
+	_, child := Start(ctx, "child")
+	if child.IsRecording() {
+		t.Fatal("the decision of the caller should be followed by default")
+	}
+}
+
+func TestStartWithNeverSampleDoesNotRecord(t *testing.T) {
+	useSampler(t, NeverSample())
+	p := &recordingProcessor{}
+	defer RegisterProcessor(p)()
+
+	ctx, span := Start(context.Background(), "work")
+	span.SetAttributes(zap.String("k", "v"))
+	span.AddEvent("ignored")
+	span.SetStatus(StatusError, "ignored")
+	span.End()
+
+	if span.IsRecording() || len(span.Attributes()) != 0 || len(span.Events()) != 0 || span.Status().Code != StatusUnset {
+		t.Fatalf("a span that is not sampled should not record: %v %v %+v", span.Attributes(), span.Events(), span.Status())
+	}
+	if !span.Ended() || span.SpanID() == "" || kitlog.GetSpanID(ctx) != span.SpanID() {
+		t.Fatal("a span that is not sampled should keep its IDs")
+	}
+	if sampled, ok := kitlog.GetSampled(ctx); !ok || sampled {
+		t.Fatalf("the decision should be stored: %v/%v", sampled, ok)
+	}
+	if len(p.ended()) != 0 {
+		t.Fatal("a span that is not sampled should not be processed")
+	}
+}
+
+func TestStartNewTraceIgnoresStaleDecision(t *testing.T) {
+	useSampler(t, ParentBased(AlwaysSample()))
+
+	ctx := kitlog.WithSampled(context.Background(), false)
+	ctx, span := Start(ctx, "work")
+	if !span.IsRecording() {
+		t.Fatal("a new trace should not inherit a decision")
+	}
+	if sampled, _ := kitlog.GetSampled(ctx); !sampled {
+		t.Fatal("the decision of the new trace should be stored")
+	}
+}
+
+func TestParentBased(t *testing.T) {
+	useSampler(t, ParentBased(NeverSample()))
+
+	ctx, root := Start(context.Background(), "root")
+	if root.IsRecording() {
+		t.Fatal("the root sampler should decide a new trace")
+	}
+	if _, child := Start(ctx, "child"); child.IsRecording() {
+		t.Fatal("a child should follow its parent")
+	}
+
+	ctx = kitlog.WithTraceID(context.Background(), "incoming-trace")
+	ctx = kitlog.WithSpanID(ctx, "incoming-span")
+	ctx = kitlog.WithSampled(ctx, true)
+	if _, span := Continue(ctx, "GET /"); !span.IsRecording() {
+		t.Fatal("the decision of the caller should be followed")
+	}
+}
+
+func TestTraceIDRatioBased(t *testing.T) {
+	if TraceIDRatioBased(0).ShouldSample(SamplingParameters{TraceID: "t"}) ||
+		!TraceIDRatioBased(1).ShouldSample(SamplingParameters{TraceID: "t"}) {
+		t.Fatal("ratios 0 and 1 should never and always sample")
+	}
+
+	s := TraceIDRatioBased(0.5)
+	var kept int
+	for range 1000 {
+		id := kitlog.NewTraceID()
+		sampled := s.ShouldSample(SamplingParameters{TraceID: id})
+		if sampled != kitlog.TraceRatioSampled(id, 0.5) {
+			t.Fatalf("the decision should match log trace sampling for %s", id)
+		}
+		if sampled != s.ShouldSample(SamplingParameters{TraceID: id}) {
+			t.Fatalf("the decision should be deterministic for %s", id)
+		}
+		if sampled {
+			kept++
+		}
+	}
+	if kept < 400 || kept > 600 {
+		t.Fatalf("expected about half of the traces, got: %d", kept)
+	}
+}
+
+func TestRuleBased(t *testing.T) {
+	s, err := RuleBased([]SamplingRule{
+		{Name: "GET /healthz", Sampler: NeverSample()},
+		{Name: "* /internal/*", Sampler: NeverSample()},
+		{Name: "grpc.health.v1.Health/*", Sampler: NeverSample()},
+	}, nil)
+	if err != nil {
+		t.Fatalf("RuleBased returned error: %v", err)
+	}
+
+	for name, want := range map[string]bool{
+		"GET /healthz":                false,
+		"POST /internal/:id":          false,
+		"grpc.health.v1.Health/Check": false,
+		"GET /orders/:id":             true,
+		"POST /healthz":               true,
+		"GET /internal/a/b":           true,
+		"orders.v1.OrderService/Get":  true,
+	} {
+		if got := s.ShouldSample(SamplingParameters{Name: name}); got != want {
+			t.Errorf("%s: got %v, want %v", name, got, want)
+		}
+	}
+
+	if _, err := RuleBased([]SamplingRule{{Name: "[", Sampler: NeverSample()}}, nil); err == nil {
+		t.Fatal("an invalid pattern should be rejected")
+	}
+	if _, err := RuleBased([]SamplingRule{{Name: "GET /"}}, nil); err == nil {
+		t.Fatal("a rule without a sampler should be rejected")
+	}
+}
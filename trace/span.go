@@ -0,0 +1,306 @@
+package trace
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// StatusCode is the outcome of a span.
+type StatusCode int
+
+const (
+	// StatusUnset is the status of a span nobody judged.
+	StatusUnset StatusCode = iota
+	StatusOK
+	StatusError
+)
+
+func (c StatusCode) String() string {
+	switch c {
+	case StatusOK:
+		return "ok"
+	case StatusError:
+		return "error"
+	default:
+		return "unset"
+	}
+}
+
+// Status is the outcome of a span and, for StatusError, why it failed.
+type Status struct {
+	Code        StatusCode
+	Description string
+}
+
+// Event is something that happened at a point in time during a span.
+type Event struct {
+	Name       string
+	Time       time.Time
+	Attributes []zap.Field
+}
+
+// exceptionEvent is the name of the event added by RecordError, as in
+// OpenTelemetry.
+const exceptionEvent = "exception"
+
+// Span is a named, timed unit of work of a trace. It is safe for concurrent
+// use. Changes after End are ignored, and every method of a nil *Span does
+// nothing.
+//
+// A span of a trace that is not sampled does not record: it keeps its IDs
+// for the logs and downstream calls, ignores changes and is not handed to
+// the processors.
+type Span struct {
+	traceID   string
+	spanID    string
+	parentID  string
+	kind      SpanKind
+	start     time.Time
+	recording bool
+
+	mu         sync.Mutex
+	name       string
+	end        time.Time
+	attributes []zap.Field
+	events     []Event
+	status     Status
+}
+
+// End records the end time of s and hands it to the registered processors.
+// Only the first call has an effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if !s.end.IsZero() {
+		s.mu.Unlock()
+		return
+	}
+	s.end = time.Now()
+	if !s.end.After(s.start) {
+		// WithStartTime이 미래 시각이어도 end가 start보다 앞서지 않게 한다.
+		s.end = s.start
+	}
+	s.mu.Unlock()
+
+	if s.recording {
+		onEnd(s)
+	}
+}
+
+// IsRecording reports whether s records its changes, that is whether its
+// trace is sampled.
+func (s *Span) IsRecording() bool {
+	return s != nil && s.recording
+}
+
+// SetName replaces the name given to Start, for instance once the route of
+// a request is known.
+func (s *Span) SetName(name string) {
+	if !s.IsRecording() {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.end.IsZero() {
+		s.name = name
+	}
+}
+
+// SetAttributes adds attributes to s. An attribute replaces an earlier one
+// with the same key.
+func (s *Span) SetAttributes(fields ...zap.Field) {
+	if !s.IsRecording() || len(fields) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.end.IsZero() {
+		return
+	}
+	for _, f := range fields {
+		s.attributes = setField(s.attributes, f)
+	}
+}
+
+// AddEvent records an event that happened now.
+func (s *Span) AddEvent(name string, fields ...zap.Field) {
+	if !s.IsRecording() {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.end.IsZero() {
+		return
+	}
+	s.events = append(s.events, Event{
+		Name:       name,
+		Time:       time.Now(),
+		Attributes: append([]zap.Field(nil), fields...),
+	})
+}
+
+// RecordError records err as an "exception" event with its type and
+// message. It does not change the status; call SetStatus for an error that
+// fails the span. A nil err is ignored.
+func (s *Span) RecordError(err error, fields ...zap.Field) {
+	if !s.IsRecording() || err == nil {
+		return
+	}
+
+	attrs := make([]zap.Field, 0, len(fields)+2)
+	attrs = append(attrs,
+		zap.String("exception.type", fmt.Sprintf("%T", err)),
+		zap.String("exception.message", err.Error()),
+	)
+	s.AddEvent(exceptionEvent, append(attrs, fields...)...)
+}
+
+// SetStatus sets the outcome of s. The description is kept only for
+// StatusError. StatusUnset is ignored and StatusOK is final, as in
+// OpenTelemetry.
+func (s *Span) SetStatus(code StatusCode, description string) {
+	if !s.IsRecording() || code == StatusUnset {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.end.IsZero() || s.status.Code == StatusOK {
+		return
+	}
+	if code != StatusError {
+		description = ""
+	}
+	s.status = Status{Code: code, Description: description}
+}
+
+// TraceID returns the trace of s.
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return s.traceID
+}
+
+// SpanID returns the ID of s.
+func (s *Span) SpanID() string {
+	if s == nil {
+		return ""
+	}
+	return s.spanID
+}
+
+// ParentSpanID returns the ID of the parent of s, or "" for a root span.
+func (s *Span) ParentSpanID() string {
+	if s == nil {
+		return ""
+	}
+	return s.parentID
+}
+
+// Kind returns the kind given to Start.
+func (s *Span) Kind() SpanKind {
+	if s == nil {
+		return SpanKindInternal
+	}
+	return s.kind
+}
+
+// Name returns the name of s.
+func (s *Span) Name() string {
+	if s == nil {
+		return ""
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.name
+}
+
+// StartTime returns when s started.
+func (s *Span) StartTime() time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+	return s.start
+}
+
+// EndTime returns when s ended, or the zero time before End.
+func (s *Span) EndTime() time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.end
+}
+
+// Ended reports whether End was called.
+func (s *Span) Ended() bool {
+	return !s.EndTime().IsZero()
+}
+
+// Duration returns how long s took, or how long it has run so far before
+// End.
+func (s *Span) Duration() time.Duration {
+	if s == nil {
+		return 0
+	}
+	if end := s.EndTime(); !end.IsZero() {
+		return end.Sub(s.start)
+	}
+	return time.Since(s.start)
+}
+
+// Attributes returns a copy of the attributes of s.
+func (s *Span) Attributes() []zap.Field {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]zap.Field(nil), s.attributes...)
+}
+
+// Events returns a copy of the events of s, oldest first.
+func (s *Span) Events() []Event {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Event(nil), s.events...)
+}
+
+// Status returns the outcome of s.
+func (s *Span) Status() Status {
+	if s == nil {
+		return Status{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+func setField(fields []zap.Field, f zap.Field) []zap.Field {
+	for i := range fields {
+		if fields[i].Key == f.Key {
+			fields[i] = f
+			return fields
+		}
+	}
+	return append(fields, f)
+}
@@ -0,0 +1,77 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestSpanAttributesAndEvents(t *testing.T) {
+	_, span := Start(context.Background(), "work", WithAttributes(zap.String("a", "1")))
+	span.SetAttributes(zap.String("a", "2"), zap.Int("b", 3))
+	span.AddEvent("cache miss", zap.String("key", "k"))
+	span.RecordError(errors.New("boom"), zap.Bool("retry", true))
+	span.RecordError(nil)
+	span.SetName("renamed")
+
+	attrs := span.Attributes()
+	if len(attrs) != 2 || attrs[0].Key != "a" || attrs[0].String != "2" || attrs[1].Key != "b" {
+		t.Fatalf("unexpected attributes: %v", attrs)
+	}
+	events := span.Events()
+	if len(events) != 2 || events[0].Name != "cache miss" || events[0].Time.IsZero() {
+		t.Fatalf("unexpected events: %v", events)
+	}
+	exc := events[1]
+	if exc.Name != "exception" || len(exc.Attributes) != 3 ||
+		exc.Attributes[0].String != "*errors.errorString" || exc.Attributes[1].String != "boom" {
+		t.Fatalf("unexpected exception event: %+v", exc)
+	}
+	if span.Name() != "renamed" {
+		t.Fatalf("unexpected name: %q", span.Name())
+	}
+	if span.Status().Code != StatusUnset {
+		t.Fatal("RecordError should not change the status")
+	}
+}
+
+func TestSpanStatus(t *testing.T) {
+	_, span := Start(context.Background(), "work")
+	span.SetStatus(StatusError, "failed")
+	if got := span.Status(); got.Code != StatusError || got.Description != "failed" {
+		t.Fatalf("unexpected status: %+v", got)
+	}
+	span.SetStatus(StatusUnset, "")
+	if span.Status().Code != StatusError {
+		t.Fatal("StatusUnset should be ignored")
+	}
+	span.SetStatus(StatusOK, "ignored")
+	span.SetStatus(StatusError, "again")
+	if got := span.Status(); got.Code != StatusOK || got.Description != "" {
+		t.Fatalf("StatusOK should be final: %+v", got)
+	}
+}
+
+func TestSpanEnd(t *testing.T) {
+	_, span := Start(context.Background(), "work")
+	if span.Ended() || span.Duration() < 0 {
+		t.Fatal("the span should be running")
+	}
+	span.End()
+	end := span.EndTime()
+	if !span.Ended() || end.Before(span.StartTime()) || span.Duration() != end.Sub(span.StartTime()) {
+		t.Fatalf("unexpected end: %v", end)
+	}
+
+	span.End()
+	span.SetAttributes(zap.String("late", "x"))
+	span.AddEvent("late")
+	span.SetStatus(StatusError, "late")
+	span.SetName("late")
+	if !span.EndTime().Equal(end) || len(span.Attributes()) != 0 || len(span.Events()) != 0 ||
+		span.Status().Code != StatusUnset || span.Name() != "work" {
+		t.Fatal("changes after End should be ignored")
+	}
+}
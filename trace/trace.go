@@ -0,0 +1,159 @@
+// Package trace records spans: named, timed units of work with attributes,
+// events and a status. A span owns the traceId, spanId and pSpanId values of
+// its context, so the logs written with that context carry its IDs.
+package trace
+
+import (
+	"context"
+	"time"
+
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+	"go.uber.org/zap"
+)
+
+type spanKeyType struct{}
+
+// SpanKey is the context key of the Span added by Start and Continue.
+var SpanKey spanKeyType
+
+// SpanKind tells whether a span serves a request, calls another service or
+// does work inside one.
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = iota
+	SpanKindServer
+	SpanKindClient
+)
+
+func (k SpanKind) String() string {
+	switch k {
+	case SpanKindServer:
+		return "server"
+	case SpanKindClient:
+		return "client"
+	default:
+		return "internal"
+	}
+}
+
+// StartOption configures a span created by Start or Continue.
+type StartOption func(*startConfig)
+
+type startConfig struct {
+	kind       SpanKind
+	attributes []zap.Field
+	start      time.Time
+}
+
+// WithKind sets the kind of the span. Defaults to SpanKindInternal.
+func WithKind(kind SpanKind) StartOption {
+	return func(c *startConfig) {
+		c.kind = kind
+	}
+}
+
+// WithAttributes sets attributes when the span starts.
+func WithAttributes(fields ...zap.Field) StartOption {
+	return func(c *startConfig) {
+		c.attributes = append(c.attributes, fields...)
+	}
+}
+
+// WithStartTime overrides the start time of the span, for work that began
+// before the span could be created.
+func WithStartTime(t time.Time) StartOption {
+	return func(c *startConfig) {
+		c.start = t
+	}
+}
+
+// Start starts a child of the span of ctx and returns a context carrying it.
+// The span gets a new spanId, the spanId of ctx becomes its pSpanId, and a
+// new trace is started when ctx has none. The Sampler set by SetSampler
+// decides whether the span records. The caller must call End.
+func Start(ctx context.Context, name string, opts ...StartOption) (context.Context, *Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	traceID := kitlog.GetTraceID(ctx)
+	if traceID == kitlog.Unknown {
+		// 새 trace에는 이전 trace의 샘플링 결정을 물려주지 않는다.
+		traceID = kitlog.NewTraceID()
+		ctx = context.WithValue(ctx, kitlog.SampledKey, nil)
+	}
+	return start(ctx, name, traceID, kitlog.NewSpanID(), kitlog.GetSpanID(ctx), opts)
+}
+
+// Continue starts the span whose IDs an incoming request stored in ctx, as
+// propagation.ExtractContext does: the spanId of ctx becomes the ID of the
+// span and the pSpanId its parent. Without a spanId it is the same as Start.
+// The caller must call End.
+func Continue(ctx context.Context, name string, opts ...StartOption) (context.Context, *Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	traceID, spanID := kitlog.GetTraceID(ctx), kitlog.GetSpanID(ctx)
+	if traceID == kitlog.Unknown || spanID == kitlog.Unknown {
+		return Start(ctx, name, opts...)
+	}
+	return start(ctx, name, traceID, spanID, kitlog.GetPSpanID(ctx), opts)
+}
+
+func start(ctx context.Context, name, traceID, spanID, parentID string, opts []StartOption) (context.Context, *Span) {
+	var cfg startConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.start.IsZero() {
+		cfg.start = time.Now()
+	}
+	if parentID == kitlog.Unknown {
+		parentID = ""
+	}
+
+	parentSampled, parentDecided := kitlog.GetSampled(ctx)
+	sampled, decided := sample(SamplingParameters{
+		TraceID:       traceID,
+		Name:          name,
+		Kind:          cfg.kind,
+		Attributes:    cfg.attributes,
+		ParentSampled: parentSampled,
+		ParentDecided: parentDecided,
+	})
+	if decided {
+		ctx = kitlog.WithSampled(ctx, sampled)
+	}
+
+	s := &Span{
+		traceID:   traceID,
+		spanID:    spanID,
+		parentID:  parentID,
+		kind:      cfg.kind,
+		start:     cfg.start,
+		recording: sampled,
+		name:      name,
+	}
+	s.SetAttributes(cfg.attributes...)
+
+	ctx = kitlog.WithTraceID(ctx, traceID)
+	ctx = kitlog.WithSpanID(ctx, spanID)
+	if parentID == "" {
+		ctx = kitlog.WithPSpanID(ctx, kitlog.Unknown)
+	} else {
+		ctx = kitlog.WithPSpanID(ctx, parentID)
+	}
+	return context.WithValue(ctx, SpanKey, s), s
+}
+
+// SpanFromContext returns the span of ctx, or nil. The methods of a nil
+// *Span do nothing, so the result can be used without a check.
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(SpanKey).(*Span)
+	return s
+}
@@ -0,0 +1,91 @@
+package trace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kitlog "github.com/NamhaeSusan/my-go-kit/log"
+	"go.uber.org/zap"
+)
+
+func TestStartNewTrace(t *testing.T) {
+	ctx, span := Start(context.Background(), "work")
+	defer span.End()
+
+	if SpanFromContext(ctx) != span {
+		t.Fatal("the span should be stored in the context")
+	}
+	if kitlog.GetTraceID(ctx) != span.TraceID() || kitlog.GetSpanID(ctx) != span.SpanID() {
+		t.Fatalf("the context should carry the span IDs: %s/%s", kitlog.GetTraceID(ctx), kitlog.GetSpanID(ctx))
+	}
+	if len(span.TraceID()) != 32 || len(span.SpanID()) != 16 {
+		t.Fatalf("unexpected IDs: %s/%s", span.TraceID(), span.SpanID())
+	}
+	if span.ParentSpanID() != "" || kitlog.GetPSpanID(ctx) != kitlog.Unknown {
+		t.Fatalf("a root span has no parent: %q/%q", span.ParentSpanID(), kitlog.GetPSpanID(ctx))
+	}
+	if span.Kind() != SpanKindInternal || span.Name() != "work" {
+		t.Fatalf("unexpected span: %s %s", span.Kind(), span.Name())
+	}
+}
+
+func TestStartChild(t *testing.T) {
+	ctx, parent := Start(context.Background(), "parent")
+	childCtx, child := Start(ctx, "child", WithKind(SpanKindClient), WithAttributes(zap.String("k", "v")))
+
+	if child.TraceID() != parent.TraceID() || child.ParentSpanID() != parent.SpanID() || child.SpanID() == parent.SpanID() {
+		t.Fatalf("unexpected child: %s/%s/%s", child.TraceID(), child.SpanID(), child.ParentSpanID())
+	}
+	if kitlog.GetPSpanID(childCtx) != parent.SpanID() || kitlog.GetSpanID(ctx) != parent.SpanID() {
+		t.Fatal("the child context should point to the parent without changing it")
+	}
+	if child.Kind() != SpanKindClient || len(child.Attributes()) != 1 {
+		t.Fatalf("options were not applied: %s %v", child.Kind(), child.Attributes())
+	}
+}
+
+func TestContinueAdoptsIncomingSpan(t *testing.T) {
+	ctx := kitlog.WithTraceID(context.Background(), "incoming-trace")
+	ctx = kitlog.WithSpanID(ctx, "incoming-span")
+	ctx = kitlog.WithPSpanID(ctx, "caller-span")
+
+	ctx, span := Continue(ctx, "GET /", WithKind(SpanKindServer))
+	if span.TraceID() != "incoming-trace" || span.SpanID() != "incoming-span" || span.ParentSpanID() != "caller-span" {
+		t.Fatalf("unexpected span: %s/%s/%s", span.TraceID(), span.SpanID(), span.ParentSpanID())
+	}
+	if SpanFromContext(ctx) != span || kitlog.GetSpanID(ctx) != "incoming-span" {
+		t.Fatal("the context should carry the adopted span")
+	}
+
+	// spanId가 없으면 Start와 같다.
+	_, span = Continue(kitlog.WithTraceID(context.Background(), "incoming-trace"), "GET /")
+	if span.TraceID() != "incoming-trace" || len(span.SpanID()) != 16 || span.ParentSpanID() != "" {
+		t.Fatalf("unexpected span without a span ID: %s/%s/%s", span.TraceID(), span.SpanID(), span.ParentSpanID())
+	}
+}
+
+func TestWithStartTime(t *testing.T) {
+	start := time.Now().Add(-time.Second)
+	_, span := Start(context.Background(), "work", WithStartTime(start))
+	span.End()
+
+	if !span.StartTime().Equal(start) || span.Duration() < time.Second {
+		t.Fatalf("unexpected timing: %v %v", span.StartTime(), span.Duration())
+	}
+}
+
+func TestSpanFromContextWithoutSpan(t *testing.T) {
+	span := SpanFromContext(context.Background())
+	if span != nil {
+		t.Fatal("no span expected")
+	}
+	// nil span의 메서드는 아무 일도 하지 않는다.
+	span.SetAttributes(zap.String("k", "v"))
+	span.RecordError(context.Canceled)
+	span.SetStatus(StatusError, "failed")
+	span.End()
+	if span.Ended() || span.TraceID() != "" || span.Duration() != 0 {
+		t.Fatal("a nil span should report nothing")
+	}
+}